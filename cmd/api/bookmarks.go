@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+)
+
+func (app *application) bookmarkPostHandler(w http.ResponseWriter, r *http.Request) {
+	app.changeBookmark(w, r, true)
+}
+
+func (app *application) unbookmarkPostHandler(w http.ResponseWriter, r *http.Request) {
+	app.changeBookmark(w, r, false)
+}
+
+func (app *application) changeBookmark(w http.ResponseWriter, r *http.Request, bookmark bool) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	post, err := app.models.Posts.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	if !post.VisibleTo(user) {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if bookmark {
+		err = app.models.Bookmarks.Insert(user.ID, post.ID)
+	} else {
+		err = app.models.Bookmarks.Delete(user.ID, post.ID)
+	}
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"post": post.ID, "bookmarked": bookmark}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// List the current user's bookmarked posts, most recently bookmarked first.
+func (app *application) showBookmarksHandler(w http.ResponseWriter, r *http.Request) {
+	var filters data.Filters
+
+	v := validator.New()
+
+	queryString := r.URL.Query()
+
+	filters.Page = app.readInt(queryString, "page", 1, v)
+	filters.Limit = app.readInt(queryString, "limit", 20, v)
+
+	filters.Sort = "-id"
+	filters.SortSafeList = []string{"-id"}
+
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	posts, metadata, err := app.models.Bookmarks.GetAllForUser(user.ID, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.attachPostReactions(posts, user)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"posts": posts, "metadata": metadata}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
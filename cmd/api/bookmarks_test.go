@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+)
+
+func TestBookmarkHandlers(t *testing.T) {
+	// User 2 has bookmarked mocked post 1. Post 3 is a draft of user 1.
+	tests := []struct {
+		name     string
+		userID   int64
+		method   string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Bookmark", userID: 3, method: http.MethodPut, urlPath: "/api/v1/post/1/bookmark", wantCode: http.StatusOK, wantBody: `"bookmarked": true`},
+		{name: "Remove bookmark", userID: 3, method: http.MethodDelete, urlPath: "/api/v1/post/1/bookmark", wantCode: http.StatusOK, wantBody: `"bookmarked": false`},
+		{name: "Draft of another user", userID: 3, method: http.MethodPut, urlPath: "/api/v1/post/3/bookmark", wantCode: http.StatusNotFound},
+		{name: "Non-existent post", userID: 3, method: http.MethodPut, urlPath: "/api/v1/post/2/bookmark", wantCode: http.StatusNotFound},
+		{name: "List bookmarks", userID: 2, method: http.MethodGet, urlPath: "/api/v1/bookmarks", wantCode: http.StatusOK, wantBody: `"bookmarkedByMe": true`},
+		{name: "Invalid page", userID: 2, method: http.MethodGet, urlPath: "/api/v1/bookmarks?page=0", wantCode: http.StatusUnprocessableEntity},
+		{name: "Shown on post", userID: 2, method: http.MethodGet, urlPath: "/api/v1/post/1", wantCode: http.StatusOK, wantBody: `"bookmarkedByMe": true`},
+		{name: "Not shown to other users", userID: 3, method: http.MethodGet, urlPath: "/api/v1/post/1", wantCode: http.StatusOK, wantBody: `"bookmarkedByMe": false`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, tt.userID, data.RoleReader)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, tt.method, tt.urlPath, data.GenerateTestToken(), "")
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+
+	t.Run("Anonymous user", func(t *testing.T) {
+		app := newTestApplication(t)
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		code, _, _ := ts.get(t, "/api/v1/bookmarks")
+
+		assert.Equal(t, code, http.StatusUnauthorized)
+	})
+}
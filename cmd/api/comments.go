@@ -17,13 +17,130 @@ func (app *application) showCommentsForPostHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
-	comments, err := app.models.Comments.GetAllForPost(id)
+	var filters data.Filters
+
+	v := validator.New()
+
+	queryString := r.URL.Query()
+
+	filters.Page = app.readInt(queryString, "page", 1, v)
+	filters.Limit = app.readInt(queryString, "limit", 50, v)
+	filters.After = app.readString(queryString, "after", "")
+	filters.Before = app.readString(queryString, "before", "")
+	view := app.readString(queryString, "view", "flat")
+
+	filters.Sort = app.readString(queryString, "sort", "-id")
+
+	// Newest, oldest or most liked threads first. Replies are always listed oldest first.
+	filters.SortSafeList = []string{"-id", "id", "-likescount"}
+
+	v.Check(validator.In(view, "flat", "tree"), "view", "must be flat or tree")
+
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	post, err := app.models.Posts.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	// Comments are only listed on posts the user can see.
+	if !post.VisibleTo(user) {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	comments, metadata, err := app.models.Comments.GetAllForPost(id, user.ReaderID(data.PermissionCommentsRead), user.HasPermission(data.PermissionContentModerate), filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.attachCommentReactions(comments, user)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if view == "flat" {
+		comments = flattenComments(comments, []*dto.CommentResponseBody{})
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"comments": comments, "metadata": metadata}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Page through the replies of a comment which has more replies than were loaded with it. after is the id of the last
+// reply already shown. Replies are nested like in the tree view.
+func (app *application) showCommentRepliesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	v := validator.New()
+
+	after := app.readInt(r.URL.Query(), "after", 0, v)
+	v.Check(after >= 0, "after", "must not be negative")
+
+	if !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	comment, err := app.models.Comments.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	// Deleted comments stay in the tree as placeholders, so their replies can still be paged.
+	comment.Deleted = false
+
+	visible, err := app.commentVisibleTo(comment, user)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusOK, envelope{"comments": comments}, nil)
+	if !visible {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	replies, more, err := app.models.Comments.GetReplies(id, user.ReaderID(data.PermissionCommentsRead), user.HasPermission(data.PermissionContentModerate), int64(after))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.attachCommentReactions(replies, user)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"replies": replies, "moreReplies": more}, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -44,6 +161,7 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 	comment := &data.Comment{
 		Text:      strings.TrimSpace(input.Text),
 		PostID:    input.PostID,
+		ParentID:  input.ParentID,
 		CreatedBy: user.ID,
 	}
 
@@ -54,6 +172,55 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	post, err := app.models.Posts.Get(comment.PostID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("post", "Post does not exist")
+			app.validationFailedResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !post.VisibleTo(user) {
+		v.AddError("post", "Post does not exist")
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	comment.Status, err = app.newCommentStatus(post, user)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if comment.Status == "" {
+		v.AddError("post", "Comments are closed on this post")
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	if comment.ParentID != nil {
+		parent, err := app.models.Comments.Get(*comment.ParentID)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				v.AddError("parentId", "Parent comment does not exist")
+				app.validationFailedResponse(w, r, v.Errors)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		if data.ValidateReply(v, comment, parent, app.config.comments.maxDepth); !v.Valid() {
+			app.validationFailedResponse(w, r, v.Errors)
+			return
+		}
+	}
+
 	err = app.models.Comments.Insert(comment)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -65,6 +232,9 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 		Text:      comment.Text,
 		CreatedBy: comment.CreatedBy,
 		PostID:    comment.PostID,
+		ParentID:  comment.ParentID,
+		Depth:     comment.Depth,
+		Status:    comment.Status,
 		UserName:  user.Name,
 	}
 
@@ -75,13 +245,248 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 	}
 }
 
+// Authors can edit their own comments and moderators can edit any comment. The previous text goes to the edit history.
+func (app *application) updateCommentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	comment, userName, err := app.models.Comments.GetWithUserName(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// Only the replies of a deleted comment are left, so there is nothing to edit.
+	if comment.Deleted {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	if !user.CanModify(comment.CreatedBy) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	var input dto.UpdateCommentRequestBody
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if input.Text == nil {
+		v.AddError("text", "must be provided")
+	} else {
+		comment.Text = strings.TrimSpace(*input.Text)
+	}
+
+	if data.ValidateComment(v, comment); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	// Moderators' edits keep the comment's status, like their comments are always approved.
+	if !user.HasPermission(data.PermissionContentModerate) {
+		post, err := app.models.Posts.Get(comment.PostID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		comment.Status = data.EditedCommentStatus(post.CommentModerationMode(app.config.comments.moderation), comment.Status)
+	}
+
+	err = app.models.Comments.Update(comment, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	CommentResponseBody := dto.CommentResponseBody{
+		ID:         comment.ID,
+		Text:       comment.Text,
+		CreatedBy:  comment.CreatedBy,
+		PostID:     comment.PostID,
+		ParentID:   comment.ParentID,
+		Depth:      comment.Depth,
+		EditedAt:   comment.EditedAt,
+		LikesCount: comment.LikesCount,
+		Status:     comment.Status,
+		UserName:   *userName,
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"comment": CommentResponseBody}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) likeCommentHandler(w http.ResponseWriter, r *http.Request) {
+	app.changeCommentLike(w, r, true)
+}
+
+func (app *application) dislikeCommentHandler(w http.ResponseWriter, r *http.Request) {
+	app.changeCommentLike(w, r, false)
+}
+
+func (app *application) changeCommentLike(w http.ResponseWriter, r *http.Request, like bool) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	comment, userName, err := app.models.Comments.GetWithUserName(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	visible, err := app.commentVisibleTo(comment, user)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !visible {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if like {
+		err = app.models.Comments.AddLike(comment, user.ID)
+	} else {
+		err = app.models.Comments.RemoveLike(comment, user.ID)
+	}
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	CommentResponseBody := dto.CommentResponseBody{
+		ID:         comment.ID,
+		Text:       comment.Text,
+		CreatedBy:  comment.CreatedBy,
+		PostID:     comment.PostID,
+		ParentID:   comment.ParentID,
+		Depth:      comment.Depth,
+		EditedAt:   comment.EditedAt,
+		LikesCount: comment.LikesCount,
+		Status:     comment.Status,
+		UserName:   *userName,
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"comment": CommentResponseBody}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Only users with the content:moderate permission reach this handler.
+func (app *application) showCommentEditHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	comment, err := app.models.Comments.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	edits, err := app.models.Comments.GetEditHistory(comment.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"edits": edits}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Work out the status of a new comment from the post's moderation mode. Moderators' comments are always approved.
+// It returns an empty status when the post doesn't take comments.
+func (app *application) newCommentStatus(post *data.Post, user *data.User) (string, error) {
+	if user.HasPermission(data.PermissionContentModerate) {
+		return data.CommentStatusApproved, nil
+	}
+
+	mode := post.CommentModerationMode(app.config.comments.moderation)
+
+	switch mode {
+	case data.ModerationModeClosed:
+		return "", nil
+	case data.ModerationModeFirstTime:
+		approved, err := app.models.Comments.HasApproved(user.ID)
+		if err != nil {
+			return "", err
+		}
+		return data.NewCommentStatus(mode, !approved), nil
+	default:
+		return data.NewCommentStatus(mode, false), nil
+	}
+}
+
 func (app *application) deleteCommentHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := app.readIDParam(r)
 	if err != nil {
 		app.notFoundResponse(w, r)
+		return
 	}
 
-	err = app.models.Comments.Delete(id)
+	comment, err := app.models.Comments.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	if !user.CanModify(comment.CreatedBy) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	err = app.models.Comments.Delete(comment.ID)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -97,3 +502,35 @@ func (app *application) deleteCommentHandler(w http.ResponseWriter, r *http.Requ
 		app.serverErrorResponse(w, r, err)
 	}
 }
+
+// List comment threads depth first, each reply right after its parent, and drop the nesting.
+func flattenComments(comments, flat []*dto.CommentResponseBody) []*dto.CommentResponseBody {
+	for _, comment := range comments {
+		replies := comment.Replies
+		comment.Replies = nil
+
+		flat = append(flat, comment)
+		flat = flattenComments(replies, flat)
+	}
+
+	return flat
+}
+
+// Comments are only visible along with their post. Deleted comments aren't visible to anyone.
+func (app *application) commentVisibleTo(comment *data.Comment, user *data.User) (bool, error) {
+	if comment.Deleted || !comment.VisibleTo(user) {
+		return false, nil
+	}
+
+	post, err := app.models.Posts.Get(comment.PostID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+
+	return post.VisibleTo(user), nil
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
 )
 
 func TestShowCommentsForPostHandler(t *testing.T) {
@@ -45,6 +46,50 @@ func TestShowCommentsForPostHandler(t *testing.T) {
 			urlPath:  "/api/v1/posts/comments/one",
 			wantCode: http.StatusNotFound,
 		},
+		{
+			name:     "Tree View",
+			urlPath:  "/api/v1/posts/comments/1?view=tree",
+			wantCode: http.StatusOK,
+			wantBody: `"replies": [`,
+		},
+		{
+			name:     "Flat View Lists Replies With Depth",
+			urlPath:  "/api/v1/posts/comments/1",
+			wantCode: http.StatusOK,
+			wantBody: `"depth": 2`,
+		},
+		{
+			name:     "Most Liked First",
+			urlPath:  "/api/v1/posts/comments/1?sort=-likescount&page=1&limit=10",
+			wantCode: http.StatusOK,
+			wantBody: `"likesCount"`,
+		},
+		{
+			name:     "Invalid Sort",
+			urlPath:  "/api/v1/posts/comments/1?sort=text",
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "Invalid View",
+			urlPath:  "/api/v1/posts/comments/1?view=nested",
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "Before Cursor",
+			urlPath:  "/api/v1/posts/comments/1?before=eyJzIjoiLWlkIiwiayI6IjEiLCJpIjoxfQ",
+			wantCode: http.StatusOK,
+			wantBody: "Mocked Comment",
+		},
+		{
+			name:     "Malformed Cursor",
+			urlPath:  "/api/v1/posts/comments/1?after=abc",
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "Limit Too Large",
+			urlPath:  "/api/v1/posts/comments/1?limit=101",
+			wantCode: http.StatusUnprocessableEntity,
+		},
 	}
 
 	for _, tt := range tests {
@@ -59,3 +104,321 @@ func TestShowCommentsForPostHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestShowCommentsForDraftPost(t *testing.T) {
+	// Mocked post 3 is a draft created by user 1.
+	tests := []struct {
+		name     string
+		userID   int64
+		role     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Author", userID: 1, role: data.RoleAuthor, urlPath: "/api/v1/posts/comments/3", wantCode: http.StatusOK, wantBody: "Mocked Draft Post Comment"},
+		{name: "Non-author", userID: 2, role: data.RoleReader, urlPath: "/api/v1/posts/comments/3", wantCode: http.StatusNotFound},
+		{name: "Non-existent post", userID: 2, role: data.RoleReader, urlPath: "/api/v1/posts/comments/2", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, tt.userID, tt.role)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodGet, tt.urlPath, data.GenerateTestToken(), "")
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+
+	t.Run("Anonymous", func(t *testing.T) {
+		app := newTestApplication(t)
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		code, _, _ := ts.get(t, "/api/v1/posts/comments/3")
+
+		assert.Equal(t, code, http.StatusNotFound)
+	})
+}
+
+func TestShowCommentRepliesHandler(t *testing.T) {
+	// Mocked comment 3 is the only reply of comment 1, comment 7 is on a draft of another user.
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Replies", urlPath: "/api/v1/posts/comment/1/replies", wantCode: http.StatusOK, wantBody: "Mocked Reply"},
+		{name: "After last reply", urlPath: "/api/v1/posts/comment/1/replies?after=3", wantCode: http.StatusOK, wantBody: `"replies": []`},
+		{name: "Negative after", urlPath: "/api/v1/posts/comment/1/replies?after=-1", wantCode: http.StatusUnprocessableEntity},
+		{name: "Comment on draft of another user", urlPath: "/api/v1/posts/comment/7/replies", wantCode: http.StatusNotFound},
+		{name: "Non-existent ID", urlPath: "/api/v1/posts/comment/2/replies", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, 2, data.RoleReader)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodGet, tt.urlPath, data.GenerateTestToken(), "")
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
+
+func TestCreateCommentHandler(t *testing.T) {
+	// Mocked comments 1 and 3 are on post 1, comment 3 is a reply at the maximum depth and comment 4 is deleted.
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Top level comment",
+			body:     `{"text": "Hello", "post": 1}`,
+			wantCode: http.StatusCreated,
+			wantBody: `"parentId": null`,
+		},
+		{
+			name:     "Reply",
+			body:     `{"text": "Hello", "post": 1, "parentId": 1}`,
+			wantCode: http.StatusCreated,
+			wantBody: `"depth": 1`,
+		},
+		{
+			name:     "Non-existent parent",
+			body:     `{"text": "Hello", "post": 1, "parentId": 2}`,
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "Parent comment does not exist",
+		},
+		{
+			name:     "Parent on another post",
+			body:     `{"text": "Hello", "post": 3, "parentId": 1}`,
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "Parent comment must belong to the same post",
+		},
+		{
+			name:     "Parent at maximum depth",
+			body:     `{"text": "Hello", "post": 1, "parentId": 3}`,
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "Replies can only be nested 2 levels deep",
+		},
+		{
+			name:     "Deleted parent",
+			body:     `{"text": "Hello", "post": 1, "parentId": 4}`,
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "Parent comment has been deleted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, 1, data.RoleReader)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodPost, "/api/v1/posts/comment", data.GenerateTestToken(), tt.body)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
+
+func TestUpdateCommentHandler(t *testing.T) {
+	// Mocked comment 1 is created by user 1 and approved, comment 4 is deleted.
+	tests := []struct {
+		name     string
+		userID   int64
+		role     string
+		mode     string
+		urlPath  string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Reader owner under all moderation", userID: 1, role: data.RoleReader, mode: data.ModerationModeAll, urlPath: "/api/v1/posts/comment/1", body: `{"text": "Edited"}`, wantCode: http.StatusOK, wantBody: `"status": "pending"`},
+		{name: "Reader owner under first time moderation", userID: 1, role: data.RoleReader, mode: data.ModerationModeFirstTime, urlPath: "/api/v1/posts/comment/1", body: `{"text": "Edited"}`, wantCode: http.StatusOK, wantBody: `"status": "approved"`},
+		{name: "Editor under all moderation", userID: 2, role: data.RoleEditor, mode: data.ModerationModeAll, urlPath: "/api/v1/posts/comment/1", body: `{"text": "Edited"}`, wantCode: http.StatusOK, wantBody: `"status": "approved"`},
+		{name: "Reader owner", userID: 1, role: data.RoleReader, urlPath: "/api/v1/posts/comment/1", body: `{"text": "Edited"}`, wantCode: http.StatusOK, wantBody: `"editedAt"`},
+		{name: "Reader non-owner", userID: 2, role: data.RoleReader, urlPath: "/api/v1/posts/comment/1", body: `{"text": "Edited"}`, wantCode: http.StatusForbidden},
+		{name: "Editor non-owner", userID: 2, role: data.RoleEditor, urlPath: "/api/v1/posts/comment/1", body: `{"text": "Edited"}`, wantCode: http.StatusOK, wantBody: `"Edited"`},
+		{name: "Missing text", userID: 1, role: data.RoleReader, urlPath: "/api/v1/posts/comment/1", body: `{}`, wantCode: http.StatusUnprocessableEntity, wantBody: "must be provided"},
+		{name: "Empty text", userID: 1, role: data.RoleReader, urlPath: "/api/v1/posts/comment/1", body: `{"text": "  "}`, wantCode: http.StatusUnprocessableEntity, wantBody: "Comment cannot be empty"},
+		{name: "Deleted comment", userID: 2, role: data.RoleReader, urlPath: "/api/v1/posts/comment/4", body: `{"text": "Edited"}`, wantCode: http.StatusNotFound},
+		{name: "Non-existent ID", userID: 1, role: data.RoleReader, urlPath: "/api/v1/posts/comment/2", body: `{"text": "Edited"}`, wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, tt.userID, tt.role)
+
+			if tt.mode != "" {
+				app.config.comments.moderation = tt.mode
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodPatch, tt.urlPath, data.GenerateTestToken(), tt.body)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
+
+// Writing comments needs the comments:write permission, which like every permission is only granted to activated users.
+func TestWriteCommentInactiveUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		urlPath string
+		body    string
+	}{
+		{name: "Create", method: http.MethodPost, urlPath: "/api/v1/posts/comment", body: `{"text": "Comment", "post": 1}`},
+		{name: "Update", method: http.MethodPatch, urlPath: "/api/v1/posts/comment/1", body: `{"text": "Edited"}`},
+		{name: "Delete", method: http.MethodDelete, urlPath: "/api/v1/posts/comment/1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			app.models.Users = data.MockUserModel{
+				MockGetForToken: func(tokenScope, tokenPlainText string) (*data.User, error) {
+					return &data.User{ID: 1, Name: "Mocked Name", Activated: false, Role: data.RoleReader}, nil
+				},
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, tt.method, tt.urlPath, data.GenerateTestToken(), tt.body)
+
+			assert.Equal(t, code, http.StatusForbidden)
+			assert.StringContains(t, body, "your user account must be activated")
+		})
+	}
+}
+
+func TestLikeCommentHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Like", urlPath: "/api/v1/posts/comment/1/like", wantCode: http.StatusOK, wantBody: `"likesCount": 1`},
+		{name: "Dislike", urlPath: "/api/v1/posts/comment/1/dislike", wantCode: http.StatusOK, wantBody: `"likesCount": 0`},
+		{name: "Deleted comment", urlPath: "/api/v1/posts/comment/4/like", wantCode: http.StatusNotFound},
+		{name: "Own pending comment", urlPath: "/api/v1/posts/comment/6/like", wantCode: http.StatusOK},
+		{name: "Comment on draft of another user", urlPath: "/api/v1/posts/comment/7/like", wantCode: http.StatusNotFound},
+		{name: "Non-existent ID", urlPath: "/api/v1/posts/comment/2/like", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, 2, data.RoleReader)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodPatch, tt.urlPath, data.GenerateTestToken(), "")
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
+
+func TestShowCommentEditHistoryHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   int64
+		role     string
+		urlPath  string
+		wantCode int
+	}{
+		{name: "Reader owner", userID: 1, role: data.RoleReader, urlPath: "/api/v1/posts/comment/1/history", wantCode: http.StatusForbidden},
+		{name: "Editor", userID: 2, role: data.RoleEditor, urlPath: "/api/v1/posts/comment/1/history", wantCode: http.StatusOK},
+		{name: "Non-existent ID", userID: 2, role: data.RoleEditor, urlPath: "/api/v1/posts/comment/2/history", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, tt.userID, tt.role)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodGet, tt.urlPath, data.GenerateTestToken(), "")
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if code == http.StatusOK {
+				assert.StringContains(t, body, "Mocked Comment Before Edit")
+			}
+		})
+	}
+}
+
+func TestDeleteCommentHandler(t *testing.T) {
+	// Mocked comment 1 is created by user 1. Readers are allowed to delete their own comments.
+	tests := []struct {
+		name     string
+		userID   int64
+		role     string
+		urlPath  string
+		wantCode int
+	}{
+		{name: "Reader owner", userID: 1, role: data.RoleReader, urlPath: "/api/v1/posts/comment/1", wantCode: http.StatusOK},
+		{name: "Reader non-owner", userID: 2, role: data.RoleReader, urlPath: "/api/v1/posts/comment/1", wantCode: http.StatusForbidden},
+		{name: "Author non-owner", userID: 2, role: data.RoleAuthor, urlPath: "/api/v1/posts/comment/1", wantCode: http.StatusForbidden},
+		{name: "Editor non-owner", userID: 2, role: data.RoleEditor, urlPath: "/api/v1/posts/comment/1", wantCode: http.StatusOK},
+		{name: "Admin non-owner", userID: 2, role: data.RoleAdmin, urlPath: "/api/v1/posts/comment/1", wantCode: http.StatusOK},
+		{name: "Non-existent ID", userID: 1, role: data.RoleReader, urlPath: "/api/v1/posts/comment/2", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, tt.userID, tt.role)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, _ := ts.do(t, http.MethodDelete, tt.urlPath, data.GenerateTestToken(), "")
+
+			assert.Equal(t, code, tt.wantCode)
+		})
+	}
+
+	t.Run("Anonymous user", func(t *testing.T) {
+		app := newTestApplication(t)
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		code, _, _ := ts.do(t, http.MethodDelete, "/api/v1/posts/comment/1", "", "")
+
+		assert.Equal(t, code, http.StatusUnauthorized)
+	})
+}
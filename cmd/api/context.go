@@ -10,7 +10,10 @@ import (
 // Prevent naming collisions in request context by defining a custom type
 type contextKey string
 
-const userContextKey = contextKey("user")
+const (
+	userContextKey    = contextKey("user")
+	sessionContextKey = contextKey("session")
+)
 
 func (app *application) contextSetUser(r *http.Request, user *data.User) *http.Request {
 	ctx := context.WithValue(r.Context(), userContextKey, user)
@@ -25,3 +28,16 @@ func (app *application) contextGetUser(r *http.Request) *data.User {
 
 	return user
 }
+
+// The session the request was authenticated with, so handlers can act on it. Opaque tokens are referred to by their
+// plaintext and signed tokens by their session (token family).
+func (app *application) contextSetSession(r *http.Request, session data.SessionRef) *http.Request {
+	ctx := context.WithValue(r.Context(), sessionContextKey, session)
+	return r.WithContext(ctx)
+}
+
+// Returns a zero SessionRef for anonymous requests.
+func (app *application) contextGetSession(r *http.Request) data.SessionRef {
+	session, _ := r.Context().Value(sessionContextKey).(data.SessionRef)
+	return session
+}
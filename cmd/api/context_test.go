@@ -21,3 +21,15 @@ func TestContextUser(t *testing.T) {
 
 	assert.Equal(t, userFromReq.ID, user.ID)
 }
+
+func TestContextSession(t *testing.T) {
+	app := newTestApplication(t)
+
+	req := &http.Request{}
+
+	assert.Equal(t, app.contextGetSession(req), data.SessionRef{})
+
+	reqWithSession := app.contextSetSession(req, data.SessionRef{TokenPlainText: "token"})
+
+	assert.Equal(t, app.contextGetSession(reqWithSession), data.SessionRef{TokenPlainText: "token"})
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+)
+
+func TestRequestEmailChangeHandler(t *testing.T) {
+	user := &data.User{ID: 1, Email: "current@email.com", Activated: true, Role: data.RoleAuthor}
+
+	err := user.Password.Set("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// mocked@email.com already belongs to an account.
+	tests := []struct {
+		name        string
+		requestBody string
+		wantCode    int
+		wantBody    string
+		wantEmail   string
+	}{
+		{name: "Valid request", requestBody: `{"email": " new@email.com ", "password": "password"}`, wantCode: http.StatusAccepted, wantBody: "a confirmation email was sent", wantEmail: "new@email.com"},
+		{name: "Wrong password", requestBody: `{"email": "new@email.com", "password": "wrong password"}`, wantCode: http.StatusUnauthorized},
+		{name: "Current email", requestBody: `{"email": "Current@email.com", "password": "password"}`, wantCode: http.StatusUnprocessableEntity, wantBody: "must be different from your current email address"},
+		{name: "Taken email", requestBody: `{"email": "mocked@email.com", "password": "password"}`, wantCode: http.StatusUnprocessableEntity, wantBody: "email address already exists"},
+		{name: "Invalid email", requestBody: `{"email": "invalid", "password": "password"}`, wantCode: http.StatusUnprocessableEntity, wantBody: "must be a valid email address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+
+			app.models.Users = data.MockUserModel{
+				MockGetForToken: func(tokenScope, tokenPlainText string) (*data.User, error) {
+					return user, nil
+				},
+				MockGet: func(id int64) (*data.User, error) {
+					return user, nil
+				},
+			}
+
+			email := ""
+			app.models.Tokens = data.MockTokenModel{
+				MockNewForEmail: func(userID int64, ttl time.Duration, scope string, newEmail string) (*data.Token, error) {
+					assert.Equal(t, scope, data.ScopeEmailChange)
+					email = newEmail
+					return &data.Token{UserID: userID, Expiry: time.Now().Add(ttl), Scope: scope, Email: newEmail}, nil
+				},
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodPost, "/api/v1/users/me/email", data.GenerateTestToken(), tt.requestBody)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+			assert.Equal(t, email, tt.wantEmail)
+		})
+	}
+}
+
+func TestConfirmEmailChangeHandler(t *testing.T) {
+	token := data.GenerateTestToken()
+
+	tests := []struct {
+		name       string
+		validToken bool
+		updateErr  error
+		wantCode   int
+		wantBody   string
+	}{
+		{name: "Valid token", validToken: true, wantCode: http.StatusOK, wantBody: "email address changed successfully"},
+		{name: "Invalid token", wantCode: http.StatusUnprocessableEntity, wantBody: "invalid or expired token"},
+		{name: "Email taken since the request", validToken: true, updateErr: data.ErrDuplicateEmail, wantCode: http.StatusUnprocessableEntity, wantBody: "email address already exists"},
+		{name: "Edit conflict", validToken: true, updateErr: data.ErrEditConflict, wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+
+			savedEmail := ""
+			app.models.Users = data.MockUserModel{
+				MockGetForToken: func(tokenScope, tokenPlainText string) (*data.User, error) {
+					return &data.User{ID: 1, Email: "old@email.com", Activated: true, Role: data.RoleAuthor}, nil
+				},
+				MockUpdate: func(user *data.User) error {
+					savedEmail = user.Email
+					return tt.updateErr
+				},
+			}
+
+			revertEmail := ""
+			app.models.Tokens = data.MockTokenModel{
+				MockGet: func(scope, tokenPlainText string) (*data.Token, error) {
+					if !tt.validToken || scope != data.ScopeEmailChange {
+						return nil, data.ErrRecordNotFound
+					}
+					return &data.Token{UserID: 1, Scope: scope, Email: "new@email.com"}, nil
+				},
+				MockNewForEmail: func(userID int64, ttl time.Duration, scope string, email string) (*data.Token, error) {
+					assert.Equal(t, scope, data.ScopeEmailRevert)
+					revertEmail = email
+					return &data.Token{UserID: userID, Expiry: time.Now().Add(ttl), Scope: scope, Email: email}, nil
+				},
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodPut, "/api/v1/auth/email", "", `{"token": "`+token+`"}`)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, savedEmail, "new@email.com")
+				// The old address can revert the change.
+				assert.Equal(t, revertEmail, "old@email.com")
+			}
+		})
+	}
+}
+
+func TestRevertEmailChangeHandler(t *testing.T) {
+	app := newTestApplication(t)
+
+	savedEmail := ""
+	app.models.Users = data.MockUserModel{
+		MockGetForToken: func(tokenScope, tokenPlainText string) (*data.User, error) {
+			return &data.User{ID: 1, Email: "new@email.com", Activated: true, Role: data.RoleAuthor}, nil
+		},
+		MockUpdate: func(user *data.User) error {
+			savedEmail = user.Email
+			return nil
+		},
+	}
+
+	deleted := []string{}
+	app.models.Tokens = data.MockTokenModel{
+		MockDeleteAllForUser: func(scope string, userID int64) error {
+			deleted = append(deleted, scope)
+			return nil
+		},
+	}
+
+	personalTokensDeleted := false
+	app.models.PersonalTokens = data.MockPersonalTokenModel{
+		MockDeleteAllForUser: func(userID int64) error {
+			personalTokensDeleted = true
+			return nil
+		},
+	}
+
+	twoFactorDisabled := false
+	app.models.TwoFactor = data.MockTwoFactorModel{
+		MockDisable: func(userID int64) error {
+			twoFactorDisabled = true
+			return nil
+		},
+	}
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	t.Run("Malformed token", func(t *testing.T) {
+		code, _, _ := ts.do(t, http.MethodPut, "/api/v1/auth/email/revert", "", `{"token": "abc"}`)
+
+		assert.Equal(t, code, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("Valid token", func(t *testing.T) {
+		code, _, body := ts.do(t, http.MethodPut, "/api/v1/auth/email/revert", "", `{"token": "`+data.GenerateTestToken()+`"}`)
+
+		assert.Equal(t, code, http.StatusOK)
+		assert.StringContains(t, body, "you have been logged out everywhere")
+		assert.Equal(t, savedEmail, "mocked@email.com")
+		assert.Equal(t, deleted[len(deleted)-3], data.ScopeAuthentication)
+		assert.Equal(t, deleted[len(deleted)-2], data.ScopeRefresh)
+		assert.Equal(t, deleted[len(deleted)-1], data.Scope2FAChallenge)
+		assert.Equal(t, personalTokensDeleted, true)
+		assert.Equal(t, twoFactorDisabled, true)
+	})
+}
@@ -71,6 +71,11 @@ func (app *application) invalidAuthenticationTokenResponse(w http.ResponseWriter
 	app.errorResponse(w, r, http.StatusUnauthorized, message)
 }
 
+func (app *application) invalidRefreshTokenResponse(w http.ResponseWriter, r *http.Request) {
+	message := "refresh token is invalid or expired"
+	app.errorResponse(w, r, http.StatusUnauthorized, message)
+}
+
 func (app *application) authenticationRequiredResponse(w http.ResponseWriter, r *http.Request) {
 	message := "you must be authenticated to access this resource"
 	app.errorResponse(w, r, http.StatusUnauthorized, message)
@@ -80,3 +85,33 @@ func (app *application) inactiveAccountResponse(w http.ResponseWriter, r *http.R
 	message := "your user account must be activated"
 	app.errorResponse(w, r, http.StatusForbidden, message)
 }
+
+func (app *application) notPermittedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "your user account doesn't have the necessary permissions to access this resource"
+	app.errorResponse(w, r, http.StatusForbidden, message)
+}
+
+func (app *application) personalTokenNotAllowedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "personal access tokens can't be used to access this resource"
+	app.errorResponse(w, r, http.StatusForbidden, message)
+}
+
+func (app *application) twoFactorEnabledResponse(w http.ResponseWriter, r *http.Request) {
+	message := "two-factor authentication is already enabled"
+	app.errorResponse(w, r, http.StatusConflict, message)
+}
+
+func (app *application) twoFactorNotEnabledResponse(w http.ResponseWriter, r *http.Request) {
+	message := "two-factor authentication is not enabled"
+	app.errorResponse(w, r, http.StatusConflict, message)
+}
+
+func (app *application) twoFactorLockedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "too many invalid two-factor codes, please try again later"
+	app.errorResponse(w, r, http.StatusTooManyRequests, message)
+}
+
+func (app *application) reportResolvedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the report has already been resolved"
+	app.errorResponse(w, r, http.StatusConflict, message)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+)
+
+func (app *application) followUserHandler(w http.ResponseWriter, r *http.Request) {
+	app.changeFollow(w, r, true)
+}
+
+func (app *application) unfollowUserHandler(w http.ResponseWriter, r *http.Request) {
+	app.changeFollow(w, r, false)
+}
+
+func (app *application) changeFollow(w http.ResponseWriter, r *http.Request, follow bool) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	if user.ID == id {
+		v := validator.New()
+		v.AddError("user", "You cannot follow yourself")
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	if follow {
+		err = app.models.Follows.Insert(user.ID, id)
+	} else {
+		err = app.models.Follows.Delete(user.ID, id)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"user": id, "following": follow}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// List the users following the user in the id param, along with the user's follow counts.
+func (app *application) showFollowersHandler(w http.ResponseWriter, r *http.Request) {
+	app.showFollows(w, r, app.models.Follows.GetFollowers)
+}
+
+// List the users the user in the id param follows, along with the user's follow counts.
+func (app *application) showFollowingHandler(w http.ResponseWriter, r *http.Request) {
+	app.showFollows(w, r, app.models.Follows.GetFollowing)
+}
+
+func (app *application) showFollows(w http.ResponseWriter, r *http.Request, list func(int64, data.Filters) ([]*dto.FollowResponseBody, data.Metadata, error)) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var filters data.Filters
+
+	v := validator.New()
+
+	queryString := r.URL.Query()
+
+	filters.Page = app.readInt(queryString, "page", 1, v)
+	filters.Limit = app.readInt(queryString, "limit", 20, v)
+
+	filters.Sort = "-followed"
+	filters.SortSafeList = []string{"-followed"}
+
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	counts, err := app.models.Follows.GetCounts(id, app.contextGetUser(r).ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	users, metadata, err := list(id, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"users": users, "counts": counts, "metadata": metadata}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// The home feed lists the latest posts from the authors the current user follows.
+// Users who follow nobody get the most liked posts instead, and the source field tells which of the two was returned.
+func (app *application) showFeedHandler(w http.ResponseWriter, r *http.Request) {
+	var filters data.Filters
+
+	v := validator.New()
+
+	queryString := r.URL.Query()
+
+	filters.Page = app.readInt(queryString, "page", 1, v)
+	filters.Limit = app.readInt(queryString, "limit", 6, v)
+	filters.After = app.readString(queryString, "after", "")
+	filters.Before = app.readString(queryString, "before", "")
+
+	user := app.contextGetUser(r)
+
+	counts, err := app.models.Follows.GetCounts(user.ID, user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	followedOnly := counts.Following > 0
+
+	source := "popular"
+	filters.Sort = "-likescount"
+	if followedOnly {
+		source = "following"
+		filters.Sort = "-published"
+	}
+	filters.SortSafeList = []string{filters.Sort}
+
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	posts, metadata, err := app.models.Posts.GetFeed(user.ID, followedOnly, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.attachPostReactions(posts, user)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"posts": posts, "metadata": metadata, "source": source}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
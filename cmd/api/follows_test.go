@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+)
+
+func TestFollowHandlers(t *testing.T) {
+	// User 2 follows user 1. Users above 4 don't exist.
+	tests := []struct {
+		name     string
+		userID   int64
+		method   string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Follow", userID: 3, method: http.MethodPut, urlPath: "/api/v1/users/1/follow", wantCode: http.StatusOK, wantBody: `"following": true`},
+		{name: "Unfollow", userID: 2, method: http.MethodDelete, urlPath: "/api/v1/users/1/follow", wantCode: http.StatusOK, wantBody: `"following": false`},
+		{name: "Follow yourself", userID: 1, method: http.MethodPut, urlPath: "/api/v1/users/1/follow", wantCode: http.StatusUnprocessableEntity, wantBody: "You cannot follow yourself"},
+		{name: "Non-existent user", userID: 3, method: http.MethodPut, urlPath: "/api/v1/users/99/follow", wantCode: http.StatusNotFound},
+		{name: "Followers", userID: 2, method: http.MethodGet, urlPath: "/api/v1/users/1/followers", wantCode: http.StatusOK, wantBody: `"userName": "Mocked User 2"`},
+		{name: "Followed by me", userID: 2, method: http.MethodGet, urlPath: "/api/v1/users/1/followers", wantCode: http.StatusOK, wantBody: `"followedByMe": true`},
+		{name: "Following", userID: 3, method: http.MethodGet, urlPath: "/api/v1/users/2/following", wantCode: http.StatusOK, wantBody: `"following": 1`},
+		{name: "Followers of non-existent user", userID: 3, method: http.MethodGet, urlPath: "/api/v1/users/99/followers", wantCode: http.StatusNotFound},
+		{name: "Invalid page", userID: 3, method: http.MethodGet, urlPath: "/api/v1/users/1/followers?page=0", wantCode: http.StatusUnprocessableEntity},
+		{name: "Feed of followed authors", userID: 2, method: http.MethodGet, urlPath: "/api/v1/feed", wantCode: http.StatusOK, wantBody: `"source": "following"`},
+		{name: "Popular feed when following nobody", userID: 3, method: http.MethodGet, urlPath: "/api/v1/feed", wantCode: http.StatusOK, wantBody: `"source": "popular"`},
+		{name: "Invalid feed limit", userID: 2, method: http.MethodGet, urlPath: "/api/v1/feed?limit=0", wantCode: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, tt.userID, data.RoleReader)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, tt.method, tt.urlPath, data.GenerateTestToken(), "")
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+
+	t.Run("Anonymous feed", func(t *testing.T) {
+		app := newTestApplication(t)
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		code, _, _ := ts.get(t, "/api/v1/feed")
+
+		assert.Equal(t, code, http.StatusUnauthorized)
+	})
+}
@@ -113,6 +113,30 @@ func (app *application) readIDParam(r *http.Request) (int64, error) {
 	return id, nil
 }
 
+// Read revision param from request url
+func (app *application) readRevisionParam(r *http.Request) (int32, error) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	revision, err := strconv.ParseInt(params.ByName("rev"), 10, 32)
+	if err != nil || revision < 1 {
+		return 0, errors.New("invalid revision parameter")
+	}
+
+	return int32(revision), nil
+}
+
+// Read post param from request url
+func (app *application) readPostParam(r *http.Request) (int64, error) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	postID, err := strconv.ParseInt(params.ByName("post"), 10, 64)
+	if err != nil || postID < 1 {
+		return 0, errors.New("invalid post parameter")
+	}
+
+	return postID, nil
+}
+
 // Return a string value from query string map or a default value
 func (app *application) readString(queryString url.Values, key string, defaultValue string) string {
 	stringValue := queryString.Get(key)
@@ -125,6 +149,17 @@ func (app *application) readString(queryString url.Values, key string, defaultVa
 	return stringValue
 }
 
+// Return a slice of comma-separated values from query string map or a default value
+func (app *application) readCSV(queryString url.Values, key string, defaultValue []string) []string {
+	csv := queryString.Get(key)
+
+	if csv == "" {
+		return defaultValue
+	}
+
+	return strings.Split(csv, ",")
+}
+
 // Return an int value from query string map or a default value
 func (app *application) readInt(queryString url.Values, key string, defaultValue int, v *validator.Validator) int {
 	stringValue := queryString.Get(key)
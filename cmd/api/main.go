@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"expvar"
 	"flag"
 	"fmt"
@@ -15,6 +16,8 @@ import (
 	"github.com/AthfanFasee/blog-post-backend/internal/data"
 	"github.com/AthfanFasee/blog-post-backend/internal/jsonlog"
 	"github.com/AthfanFasee/blog-post-backend/internal/mailer"
+	"github.com/AthfanFasee/blog-post-backend/internal/signedtoken"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
 	"github.com/AthfanFasee/blog-post-backend/util"
 	_ "github.com/lib/pq"
 )
@@ -50,15 +53,44 @@ type config struct {
 	cors struct {
 		trustedOrigins []string
 	}
+	scheduler struct {
+		enabled   bool
+		interval  time.Duration
+		batchSize int
+	}
+	comments struct {
+		maxDepth   int
+		moderation string
+	}
+	reports struct {
+		hideThreshold int
+	}
+	reactions struct {
+		allowed []string
+	}
+	tokens struct {
+		accessTTL  time.Duration
+		refreshTTL time.Duration
+		// Whether access tokens are issued as opaque tokens or as signed tokens. Both are accepted when keys are set.
+		format             string
+		keys               *signedtoken.KeySet
+		revocationInterval time.Duration
+	}
+	twoFactor struct {
+		issuer string
+	}
 }
 
 // Application dependencies
 type application struct {
-	config config
-	logger *jsonlog.Logger
-	models data.Models
-	mailer mailer.Mailer
-	wg     sync.WaitGroup
+	config    config
+	logger    *jsonlog.Logger
+	models    data.Models
+	mailer    mailer.Mailer
+	scheduler *scheduler
+	// Only set when signed access tokens are enabled.
+	revocations *revocationList
+	wg          sync.WaitGroup
 }
 
 func main() {
@@ -97,6 +129,49 @@ func main() {
 		return nil
 	})
 
+	// Scheduled publishing related
+	flag.BoolVar(&cfg.scheduler.enabled, "scheduler-enabled", true, "Enable the scheduler publishing posts and deleting expired tokens")
+	flag.DurationVar(&cfg.scheduler.interval, "scheduler-interval", time.Minute, "Interval between scheduled post publishing runs")
+	flag.IntVar(&cfg.scheduler.batchSize, "scheduler-batch-size", 50, "Maximum number of scheduled posts published per run")
+
+	// Comment threads related
+	flag.IntVar(&cfg.comments.maxDepth, "comments-max-depth", 5, "Maximum nesting level of comment replies")
+	cfg.comments.moderation = data.ModerationModeOpen
+	flag.Func("comments-moderation", "Comment moderation mode of posts without their own (open|first_time|all|closed)", func(val string) error {
+		v := validator.New()
+		if data.ValidateModerationMode(v, val); !v.Valid() {
+			return errors.New(v.Errors["mode"])
+		}
+		cfg.comments.moderation = val
+		return nil
+	})
+
+	// Content reports related
+	flag.IntVar(&cfg.reports.hideThreshold, "reports-hide-threshold", 5, "Open reports after which content is hidden until reviewed (0 disables)")
+
+	// Reactions related
+	cfg.reactions.allowed = data.DefaultReactions
+	flag.Func("reactions", "Emojis users can react with (separated by space)", func(val string) error {
+		emojis := strings.Fields(val)
+		if len(emojis) == 0 {
+			return errors.New("must contain at least one emoji")
+		}
+		cfg.reactions.allowed = emojis
+		return nil
+	})
+
+	// Authentication tokens related
+	flag.DurationVar(&cfg.tokens.accessTTL, "access-token-ttl", 15*time.Minute, "Lifetime of access tokens")
+	flag.DurationVar(&cfg.tokens.refreshTTL, "refresh-token-ttl", 30*24*time.Hour, "Lifetime of refresh tokens")
+	// Signed access tokens are checked without the database. Their sessions' last use, IP and user agent are only
+	// updated when refreshing, and a changed role or deactivation only applies once the access token expires, after
+	// at most access-token-ttl. Logging out revokes them within token-revocation-interval.
+	flag.StringVar(&cfg.tokens.format, "token-format", tokenFormatOpaque, "Format of issued access tokens (opaque|signed)")
+	flag.DurationVar(&cfg.tokens.revocationInterval, "token-revocation-interval", 30*time.Second, "Interval between reloads of the revoked sessions of signed access tokens")
+
+	// Two-factor authentication related
+	flag.StringVar(&cfg.twoFactor.issuer, "2fa-issuer", "Blog Post", "Name authenticator apps show for the accounts")
+
 	// Version control
 	displayVersion := flag.Bool("version", false, "Display version and exit")
 
@@ -108,6 +183,21 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Signing keys are secrets, so they come from the environment rather than flags.
+	if env.TokenSigningKeys != "" {
+		cfg.tokens.keys, err = signedtoken.ParseKeySet(env.TokenSigningKeys)
+		if err != nil {
+			logger.PrintFatal(err, nil)
+		}
+	}
+
+	switch {
+	case cfg.tokens.format != tokenFormatOpaque && cfg.tokens.format != tokenFormatSigned:
+		logger.PrintFatal(fmt.Errorf("invalid token format %q", cfg.tokens.format), nil)
+	case cfg.tokens.format == tokenFormatSigned && cfg.tokens.keys == nil:
+		logger.PrintFatal(errors.New("signed tokens need TOKEN_SIGNING_KEYS to be set"), nil)
+	}
+
 	// Connect with DB
 	db, err := openDB(cfg)
 	if err != nil {
@@ -136,6 +226,17 @@ func main() {
 		mailer: mailer.New(cfg.smtp.host, cfg.smtp.port, cfg.smtp.username, cfg.smtp.password, cfg.smtp.sender),
 	}
 
+	if cfg.scheduler.enabled {
+		app.startScheduler()
+	}
+
+	if cfg.tokens.keys != nil {
+		err = app.startRevocationList()
+		if err != nil {
+			logger.PrintFatal(err, nil)
+		}
+	}
+
 	err = app.serve()
 	if err != nil {
 		logger.PrintFatal(err, nil)
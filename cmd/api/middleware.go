@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/AthfanFasee/blog-post-backend/internal/data"
+	"github.com/AthfanFasee/blog-post-backend/internal/signedtoken"
 	"github.com/AthfanFasee/blog-post-backend/internal/validator"
 	"github.com/felixge/httpsnoop"
 	"github.com/tomasen/realip"
@@ -121,6 +122,53 @@ func (app *application) authenticate(next http.Handler) http.Handler {
 
 		token := headerParts[1]
 
+		// Personal access tokens don't belong to a session, the user they resolve to carries the token's scopes instead.
+		if data.IsPersonalToken(token) {
+			v := validator.New()
+
+			if data.ValidatePersonalTokenPlainText(v, token); !v.Valid() {
+				app.invalidAuthenticationTokenResponse(w, r)
+				return
+			}
+
+			user, err := app.models.PersonalTokens.GetUserForToken(token)
+			if err != nil {
+				switch {
+				case errors.Is(err, data.ErrRecordNotFound):
+					app.invalidAuthenticationTokenResponse(w, r)
+				default:
+					app.serverErrorResponse(w, r, err)
+				}
+				return
+			}
+
+			err = app.models.PersonalTokens.Touch(token, realip.FromRequest(r))
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+
+			r = app.contextSetUser(r, user)
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Signed tokens carry the user, so only the revocation list has to be checked.
+		if app.config.tokens.keys != nil && signedtoken.IsSigned(token) {
+			claims, err := app.config.tokens.keys.Verify(token, time.Now())
+			if err != nil || app.revocations.revoked(claims.Session) {
+				app.invalidAuthenticationTokenResponse(w, r)
+				return
+			}
+
+			r = app.contextSetUser(r, &data.User{ID: claims.UserID, Name: claims.Name, Role: claims.Role, Activated: claims.Activated})
+			r = app.contextSetSession(r, data.SessionRef{Family: claims.Session})
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		v := validator.New()
 
 		if data.ValidateTokenPlainText(v, token); !v.Valid() {
@@ -139,15 +187,39 @@ func (app *application) authenticate(next http.Handler) http.Handler {
 			return
 		}
 
+		err = app.models.Tokens.Touch(token, realip.FromRequest(r), r.UserAgent())
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
 		r = app.contextSetUser(r, user)
+		r = app.contextSetSession(r, data.SessionRef{TokenPlainText: token})
 
 		next.ServeHTTP(w, r)
 
 	})
 }
 
-// Checks that a user authenticated.
+// Checks that a user authenticated, with a login session. Personal access tokens are only accepted by requirePermission,
+// which checks their scopes, so they can't be used for anything else such as managing the account.
 func (app *application) requireAuthenticatedUser(next http.HandlerFunc) http.HandlerFunc {
+	fn := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := app.contextGetUser(r)
+
+		if user.Scopes != nil {
+			app.personalTokenNotAllowedResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+
+	return app.requireUser(fn)
+}
+
+// Checks that a user authenticated, with a login session or a personal access token.
+func (app *application) requireUser(next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user := app.contextGetUser(r)
 
@@ -162,7 +234,12 @@ func (app *application) requireAuthenticatedUser(next http.HandlerFunc) http.Han
 
 // Checks that a user is both authenticated and activated.
 func (app *application) requireActivatedUser(next http.HandlerFunc) http.HandlerFunc {
-	fn := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return app.requireAuthenticatedUser(app.requireActivation(next))
+}
+
+// Checks that the authenticated user is activated. It must be wrapped by one of the middlewares checking authentication.
+func (app *application) requireActivation(next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user := app.contextGetUser(r)
 
 		if !user.Activated {
@@ -172,8 +249,22 @@ func (app *application) requireActivatedUser(next http.HandlerFunc) http.Handler
 
 		next.ServeHTTP(w, r)
 	})
+}
+
+// Checks that an activated user's role grants the given permission, and that the scopes of personal access tokens do.
+func (app *application) requirePermission(code string, next http.HandlerFunc) http.HandlerFunc {
+	fn := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := app.contextGetUser(r)
+
+		if !user.HasPermission(code) {
+			app.notPermittedResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
 
-	return app.requireAuthenticatedUser(fn)
+	return app.requireUser(app.requireActivation(fn))
 }
 
 func (app *application) enableCORS(next http.Handler) http.Handler {
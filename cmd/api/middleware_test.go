@@ -10,6 +10,7 @@ import (
 
 	"github.com/AthfanFasee/blog-post-backend/internal/assert"
 	"github.com/AthfanFasee/blog-post-backend/internal/data"
+	"github.com/AthfanFasee/blog-post-backend/internal/signedtoken"
 )
 
 func TestApplication_RecoverPanic(t *testing.T) {
@@ -108,6 +109,75 @@ func TestApplication_Authenticate(t *testing.T) {
 	}
 }
 
+func TestApplication_AuthenticateSigned(t *testing.T) {
+	app := newTestApplication(t)
+	useSignedTokens(t, app)
+
+	sign := func(session int64, expiry time.Time) string {
+		token, err := app.config.tokens.keys.Sign(signedtoken.Claims{UserID: 1, Name: "Mocked Name", Role: data.RoleAuthor, Activated: true, Session: session, ExpiresAt: expiry.Unix()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	valid := sign(1, time.Now().Add(time.Minute))
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{"Valid token", valid, http.StatusOK},
+		{"Expired token", sign(1, time.Now().Add(-time.Minute)), http.StatusUnauthorized},
+		{"Revoked session", sign(99, time.Now().Add(time.Minute)), http.StatusUnauthorized},
+		{"Bad signature", valid[:len(valid)-2] + "AA", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The user and session come from the token alone.
+			app.models.Users = data.MockUserModel{
+				MockGetForToken: func(tokenScope, tokenPlainText string) (*data.User, error) {
+					t.Fatal("signed tokens must not be looked up")
+					return nil, nil
+				},
+			}
+
+			request, err := http.NewRequest(http.MethodGet, "/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			request.Header.Set("Authorization", "Bearer "+tt.token)
+
+			responseRecorder := httptest.NewRecorder()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, app.contextGetUser(r).ID, int64(1))
+				assert.Equal(t, app.contextGetSession(r), data.SessionRef{Family: 1})
+			})
+
+			app.authenticate(next).ServeHTTP(responseRecorder, request)
+
+			assert.Equal(t, responseRecorder.Code, tt.wantStatus)
+		})
+	}
+
+	t.Run("Logout revokes the session", func(t *testing.T) {
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		token := sign(5, time.Now().Add(time.Minute))
+
+		code, _, _ := ts.do(t, http.MethodDelete, "/api/v1/auth/token", token, "")
+		assert.Equal(t, code, http.StatusOK)
+
+		code, _, _ = ts.do(t, http.MethodGet, "/api/v1/auth/sessions", token, "")
+		assert.Equal(t, code, http.StatusUnauthorized)
+	})
+}
+
 // The tests for requireAuthenticatedUser should only focus on the logic within itself.
 func TestApplication_RequireAuthenticatedUser(t *testing.T) {
 	tests := []struct {
@@ -206,6 +276,52 @@ func TestApplication_RequireActivatedUser(t *testing.T) {
 	}
 }
 
+// The tests for requirePermission should only focus on the logic within itself.
+func TestApplication_RequirePermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       string
+		permission string
+		wantStatus int
+	}{
+		{"Reader writing comments", data.RoleReader, data.PermissionCommentsWrite, http.StatusOK},
+		{"Reader writing posts", data.RoleReader, data.PermissionPostsWrite, http.StatusForbidden},
+		{"Author writing posts", data.RoleAuthor, data.PermissionPostsWrite, http.StatusOK},
+		{"Author moderating content", data.RoleAuthor, data.PermissionContentModerate, http.StatusForbidden},
+		{"Editor moderating content", data.RoleEditor, data.PermissionContentModerate, http.StatusOK},
+		{"Admin moderating content", data.RoleAdmin, data.PermissionContentModerate, http.StatusOK},
+		{"Unknown role", "unknown", data.PermissionCommentsWrite, http.StatusForbidden},
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+
+			request, err := http.NewRequest(http.MethodGet, "/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			responseRecorder := httptest.NewRecorder()
+
+			user := &data.User{Activated: true, Role: tt.role}
+
+			ctx := context.WithValue(request.Context(), userContextKey, user)
+			request = request.WithContext(ctx)
+
+			app.requirePermission(tt.permission, next).ServeHTTP(responseRecorder, request)
+
+			if responseRecorder.Code != tt.wantStatus {
+				t.Errorf("got status %d; want %d", responseRecorder.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
 func TestEnableCORS(t *testing.T) {
 	// Prepare the application with CORS configuration.
 	app := newTestApplication(t)
@@ -297,12 +413,10 @@ func TestMetrics(t *testing.T) {
 		}
 
 		totalResponsesSentByStatus := expvar.Get("total_responses_sent_by_status").(*expvar.Map)
-		var responseCount expvar.Int
-		totalResponsesSentByStatus.Do(func(kv expvar.KeyValue) {
-			if kv.Key == "200" {
-				responseCount = *(kv.Value.(*expvar.Int))
-			}
-		})
+		responseCount, ok := totalResponsesSentByStatus.Get("200").(*expvar.Int)
+		if !ok {
+			t.Fatal("Expected responses with status 200 to be counted")
+		}
 		if responseCount.Value() != 1 {
 			t.Errorf("Expected %d but got %d", 1, responseCount.Value())
 		}
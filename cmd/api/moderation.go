@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+)
+
+// List comments waiting for moderation, or those already given another status. Only users with the content:moderate
+// permission reach this handler.
+func (app *application) showModerationQueueHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Status string
+		data.Filters
+	}
+
+	v := validator.New()
+
+	queryString := r.URL.Query()
+
+	input.Status = app.readString(queryString, "status", data.CommentStatusPending)
+	input.Filters.Page = app.readInt(queryString, "page", 1, v)
+	input.Filters.Limit = app.readInt(queryString, "limit", 20, v)
+
+	// The queue is always worked through oldest first.
+	input.Filters.Sort = "id"
+	input.Filters.SortSafeList = []string{"id"}
+
+	data.ValidateCommentStatus(v, input.Status)
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	comments, metadata, err := app.models.Comments.GetModerationQueue(input.Status, input.Filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"comments": comments, "metadata": metadata}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Approve, reject or mark as spam several comments at once.
+// Only users with the content:moderate permission reach this handler.
+func (app *application) moderateCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	var input dto.ModerateCommentsRequestBody
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	data.ValidateModerationBatch(v, input.IDs)
+
+	if data.ValidateCommentStatus(v, input.Status); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	updated, err := app.models.Comments.Moderate(input.IDs, input.Status, user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"updated": updated}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Authors choose how comments on their own posts are moderated, moderators can change it on any post.
+func (app *application) updatePostModerationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	post, err := app.models.Posts.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	if !user.CanModify(post.CreatedBy) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	var input dto.PostModerationRequestBody
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if input.Mode != nil {
+		if data.ValidateModerationMode(v, *input.Mode); !v.Valid() {
+			app.validationFailedResponse(w, r, v.Errors)
+			return
+		}
+	}
+
+	err = app.models.Posts.SetCommentModeration(post.ID, input.Mode)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	post.CommentModeration = input.Mode
+
+	PostModerationResponseBody := dto.PostModerationResponseBody{
+		PostID:        post.ID,
+		Mode:          post.CommentModeration,
+		EffectiveMode: post.CommentModerationMode(app.config.comments.moderation),
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"moderation": PostModerationResponseBody}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+)
+
+func TestCreateCommentModeration(t *testing.T) {
+	// Only user 1 has had comments approved before.
+	tests := []struct {
+		name       string
+		mode       string
+		userID     int64
+		role       string
+		wantCode   int
+		wantStatus string
+	}{
+		{name: "Open", mode: data.ModerationModeOpen, userID: 2, role: data.RoleReader, wantCode: http.StatusCreated, wantStatus: data.CommentStatusApproved},
+		{name: "All need approval", mode: data.ModerationModeAll, userID: 1, role: data.RoleReader, wantCode: http.StatusCreated, wantStatus: data.CommentStatusPending},
+		{name: "First time commenter", mode: data.ModerationModeFirstTime, userID: 2, role: data.RoleReader, wantCode: http.StatusCreated, wantStatus: data.CommentStatusPending},
+		{name: "Returning commenter", mode: data.ModerationModeFirstTime, userID: 1, role: data.RoleReader, wantCode: http.StatusCreated, wantStatus: data.CommentStatusApproved},
+		{name: "Moderator", mode: data.ModerationModeAll, userID: 2, role: data.RoleEditor, wantCode: http.StatusCreated, wantStatus: data.CommentStatusApproved},
+		{name: "Closed", mode: data.ModerationModeClosed, userID: 1, role: data.RoleReader, wantCode: http.StatusUnprocessableEntity},
+		{name: "Moderator on closed", mode: data.ModerationModeClosed, userID: 2, role: data.RoleEditor, wantCode: http.StatusCreated, wantStatus: data.CommentStatusApproved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			app.config.comments.moderation = tt.mode
+			authenticateAs(app, tt.userID, tt.role)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodPost, "/api/v1/posts/comment", data.GenerateTestToken(), `{"text": "Hello", "post": 1}`)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantStatus != "" {
+				assert.StringContains(t, body, `"status": "`+tt.wantStatus+`"`)
+			} else {
+				assert.StringContains(t, body, "Comments are closed on this post")
+			}
+		})
+	}
+
+	t.Run("Non-existent post", func(t *testing.T) {
+		app := newTestApplication(t)
+		authenticateAs(app, 1, data.RoleReader)
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		code, _, body := ts.do(t, http.MethodPost, "/api/v1/posts/comment", data.GenerateTestToken(), `{"text": "Hello", "post": 2}`)
+
+		assert.Equal(t, code, http.StatusUnprocessableEntity)
+		assert.StringContains(t, body, "Post does not exist")
+	})
+}
+
+func TestShowCommentsForPostModeration(t *testing.T) {
+	// Mocked pending comment is written by user 2.
+	tests := []struct {
+		name        string
+		userID      int64
+		role        string
+		wantPending bool
+	}{
+		{name: "Other reader", userID: 3, role: data.RoleReader, wantPending: false},
+		{name: "Author of pending comment", userID: 2, role: data.RoleReader, wantPending: true},
+		{name: "Moderator", userID: 3, role: data.RoleEditor, wantPending: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, tt.userID, tt.role)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodGet, "/api/v1/posts/comments/1", data.GenerateTestToken(), "")
+
+			assert.Equal(t, code, http.StatusOK)
+			assert.Equal(t, strings.Contains(body, "Mocked Pending Comment"), tt.wantPending)
+		})
+	}
+}
+
+func TestShowModerationQueueHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Reader", role: data.RoleReader, urlPath: "/api/v1/moderation/comments", wantCode: http.StatusForbidden},
+		{name: "Editor", role: data.RoleEditor, urlPath: "/api/v1/moderation/comments", wantCode: http.StatusOK, wantBody: "Mocked Pending Comment"},
+		{name: "Spam", role: data.RoleEditor, urlPath: "/api/v1/moderation/comments?status=spam", wantCode: http.StatusOK, wantBody: `"comments": []`},
+		{name: "Invalid status", role: data.RoleEditor, urlPath: "/api/v1/moderation/comments?status=hidden", wantCode: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, 2, tt.role)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodGet, tt.urlPath, data.GenerateTestToken(), "")
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
+
+func TestModerateCommentsHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Reader", role: data.RoleReader, body: `{"ids": [6], "status": "approved"}`, wantCode: http.StatusForbidden},
+		{name: "Approve", role: data.RoleEditor, body: `{"ids": [6, 7], "status": "approved"}`, wantCode: http.StatusOK, wantBody: `"updated": 1`},
+		{name: "Spam", role: data.RoleAdmin, body: `{"ids": [6], "status": "spam"}`, wantCode: http.StatusOK, wantBody: `"updated": 1`},
+		{name: "No ids", role: data.RoleEditor, body: `{"ids": [], "status": "approved"}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "Invalid id", role: data.RoleEditor, body: `{"ids": [0], "status": "approved"}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "Invalid status", role: data.RoleEditor, body: `{"ids": [6], "status": "hidden"}`, wantCode: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, 2, tt.role)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodPatch, "/api/v1/moderation/comments", data.GenerateTestToken(), tt.body)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
+
+func TestUpdatePostModerationHandler(t *testing.T) {
+	// Mocked post 1 is created by user 1.
+	tests := []struct {
+		name     string
+		userID   int64
+		role     string
+		urlPath  string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Owner", userID: 1, role: data.RoleAuthor, urlPath: "/api/v1/post/1/moderation", body: `{"mode": "all"}`, wantCode: http.StatusOK, wantBody: `"effectiveMode": "all"`},
+		{name: "Back to global mode", userID: 1, role: data.RoleAuthor, urlPath: "/api/v1/post/1/moderation", body: `{"mode": null}`, wantCode: http.StatusOK, wantBody: `"effectiveMode": "open"`},
+		{name: "Non-owner", userID: 2, role: data.RoleAuthor, urlPath: "/api/v1/post/1/moderation", body: `{"mode": "all"}`, wantCode: http.StatusForbidden},
+		{name: "Editor non-owner", userID: 2, role: data.RoleEditor, urlPath: "/api/v1/post/1/moderation", body: `{"mode": "closed"}`, wantCode: http.StatusOK, wantBody: `"effectiveMode": "closed"`},
+		{name: "Invalid mode", userID: 1, role: data.RoleAuthor, urlPath: "/api/v1/post/1/moderation", body: `{"mode": "strict"}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "Non-existent post", userID: 1, role: data.RoleAuthor, urlPath: "/api/v1/post/2/moderation", body: `{"mode": "all"}`, wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, tt.userID, tt.role)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodPut, tt.urlPath, data.GenerateTestToken(), tt.body)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
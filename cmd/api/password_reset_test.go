@@ -0,0 +1,130 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+)
+
+func TestCreatePasswordResetTokenHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		requestBody string
+		wantCode    int
+		wantBody    string
+		wantToken   bool
+	}{
+		{name: "Existing account", requestBody: `{"email": "mocked@email.com"}`, wantCode: http.StatusAccepted, wantBody: "if an account with that email exists", wantToken: true},
+		{name: "Unknown account", requestBody: `{"email": "unknown@email.com"}`, wantCode: http.StatusAccepted, wantBody: "if an account with that email exists"},
+		{name: "Invalid email", requestBody: `{"email": "invalid"}`, wantCode: http.StatusUnprocessableEntity, wantBody: "must be a valid email address"},
+		{name: "Invalid body", requestBody: `{"email": 1}`, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+
+			created := false
+			app.models.Tokens = data.MockTokenModel{
+				MockNew: func(userID int64, ttl time.Duration, scope string) (*data.Token, error) {
+					assert.Equal(t, scope, data.ScopePasswordReset)
+					assert.Equal(t, ttl, 45*time.Minute)
+					created = true
+					return &data.Token{UserID: userID, Expiry: time.Now().Add(ttl), Scope: scope}, nil
+				},
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodPost, "/api/v1/auth/password-reset", "", tt.requestBody)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+			assert.Equal(t, created, tt.wantToken)
+		})
+	}
+}
+
+func TestUpdateUserPasswordHandler(t *testing.T) {
+	token := data.GenerateTestToken()
+
+	tests := []struct {
+		name        string
+		requestBody string
+		validToken  bool
+		twoFactor   bool
+		wantCode    int
+		wantBody    string
+	}{
+		{name: "Valid token", requestBody: `{"password": "new password", "token": "` + token + `"}`, validToken: true, wantCode: http.StatusOK, wantBody: "your password was successfully reset"},
+		{name: "Expired token", requestBody: `{"password": "new password", "token": "` + token + `"}`, wantCode: http.StatusUnprocessableEntity, wantBody: "invalid or expired password reset token"},
+		{name: "Short password", requestBody: `{"password": "new", "token": "` + token + `"}`, validToken: true, wantCode: http.StatusUnprocessableEntity, wantBody: "password must be at least 6 bytes long"},
+		{name: "Malformed token", requestBody: `{"password": "new password", "token": "abc"}`, validToken: true, wantCode: http.StatusUnprocessableEntity, wantBody: "must be 26 bytes long"},
+		{name: "Two-factor code", requestBody: `{"password": "new password", "token": "` + token + `", "code": "abcde-fghij"}`, validToken: true, twoFactor: true, wantCode: http.StatusOK, wantBody: "your password was successfully reset"},
+		{name: "Missing two-factor code", requestBody: `{"password": "new password", "token": "` + token + `"}`, validToken: true, twoFactor: true, wantCode: http.StatusUnprocessableEntity, wantBody: "must be provided"},
+		{name: "Invalid two-factor code", requestBody: `{"password": "new password", "token": "` + token + `", "code": "wrong-code"}`, validToken: true, twoFactor: true, wantCode: http.StatusUnprocessableEntity, wantBody: "invalid or already used code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+
+			app.models.Users = data.MockUserModel{
+				MockGetForToken: func(tokenScope, tokenPlainText string) (*data.User, error) {
+					if tokenScope != data.ScopePasswordReset || !tt.validToken {
+						return nil, data.ErrRecordNotFound
+					}
+					return &data.User{ID: 1, Email: "mocked@email.com", Activated: true, Role: data.RoleAuthor}, nil
+				},
+			}
+
+			app.models.TwoFactor = data.MockTwoFactorModel{
+				MockGet: func(userID int64) (*data.TwoFactor, error) {
+					return &data.TwoFactor{UserID: userID, Secret: testTOTPSecret, Enabled: tt.twoFactor}, nil
+				},
+				MockUseRecoveryCode: func(userID int64, code string) (bool, error) {
+					return code == "abcde-fghij", nil
+				},
+			}
+
+			deleted := []string{}
+			app.models.Tokens = data.MockTokenModel{
+				MockDeleteAllForUser: func(scope string, userID int64) error {
+					deleted = append(deleted, scope)
+					return nil
+				},
+			}
+
+			personalTokensDeleted := false
+			app.models.PersonalTokens = data.MockPersonalTokenModel{
+				MockDeleteAllForUser: func(userID int64) error {
+					personalTokensDeleted = true
+					return nil
+				},
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodPut, "/api/v1/auth/password", "", tt.requestBody)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+
+			if tt.wantCode == http.StatusOK {
+				// Resetting the password logs the user out everywhere.
+				assert.Equal(t, len(deleted), 6)
+				assert.Equal(t, deleted[1], data.ScopeEmailChange)
+				assert.Equal(t, deleted[2], data.ScopeEmailRevert)
+				assert.Equal(t, deleted[3], data.ScopeAuthentication)
+				assert.Equal(t, deleted[4], data.ScopeRefresh)
+				assert.Equal(t, deleted[5], data.Scope2FAChallenge)
+				assert.Equal(t, personalTokensDeleted, true)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+)
+
+// Create a personal access token for the current user. Its plaintext is only ever shown in this response.
+func (app *application) createPersonalTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var input dto.CreatePersonalTokenRequestBody
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	token := &data.PersonalToken{
+		UserID: user.ID,
+		Name:   strings.TrimSpace(input.Name),
+		Scopes: input.Scopes,
+		Expiry: input.Expiry,
+	}
+
+	v := validator.New()
+
+	if data.ValidatePersonalToken(v, token, user.Role); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.PersonalTokens.Insert(token)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateTokenName):
+			v.AddError("name", "You already have a personal access token with this name")
+			app.validationFailedResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	body := &dto.PersonalTokenResponseBody{
+		ID:        token.ID,
+		Name:      token.Name,
+		Scopes:    token.Scopes,
+		CreatedAt: token.CreatedAt,
+		Expiry:    token.Expiry,
+		Token:     token.Plaintext,
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"personal_token": body}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) showPersonalTokensHandler(w http.ResponseWriter, r *http.Request) {
+	tokens, err := app.models.PersonalTokens.GetAllForUser(app.contextGetUser(r).ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"personal_tokens": tokens}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deletePersonalTokenHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.models.PersonalTokens.Delete(app.contextGetUser(r).ID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "personal access token revoked successfully"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+)
+
+func TestCreatePersonalTokenHandler(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour).Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name        string
+		requestBody string
+		insertErr   error
+		wantCode    int
+		wantBody    string
+	}{
+		{name: "Valid request", requestBody: `{"name": "CI", "scopes": ["posts:write"], "expiry": "` + future + `"}`, wantCode: http.StatusCreated, wantBody: `"token": "pat_`},
+		{name: "No expiry", requestBody: `{"name": "CI", "scopes": ["posts:write", "comments:write"]}`, wantCode: http.StatusCreated, wantBody: `"expiry": null`},
+		{name: "Unknown scope", requestBody: `{"name": "CI", "scopes": ["posts:delete"]}`, wantCode: http.StatusUnprocessableEntity, wantBody: "must only contain valid scopes"},
+		{name: "Scope not granted by role", requestBody: `{"name": "CI", "scopes": ["posts:publish"]}`, wantCode: http.StatusUnprocessableEntity, wantBody: "your role doesn't grant the posts:publish permission"},
+		{name: "No scopes", requestBody: `{"name": "CI", "scopes": []}`, wantCode: http.StatusUnprocessableEntity, wantBody: "must contain at least one scope"},
+		{name: "Past expiry", requestBody: `{"name": "CI", "scopes": ["posts:write"], "expiry": "` + past + `"}`, wantCode: http.StatusUnprocessableEntity, wantBody: "must be in the future"},
+		{name: "Missing name", requestBody: `{"name": " ", "scopes": ["posts:write"]}`, wantCode: http.StatusUnprocessableEntity, wantBody: "must be provided"},
+		{name: "Duplicate name", requestBody: `{"name": "CI", "scopes": ["posts:write"]}`, insertErr: data.ErrDuplicateTokenName, wantCode: http.StatusUnprocessableEntity, wantBody: "You already have a personal access token with this name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, 1, data.RoleAuthor)
+
+			if tt.insertErr != nil {
+				app.models.PersonalTokens = data.MockPersonalTokenModel{
+					MockInsert: func(token *data.PersonalToken) error {
+						return tt.insertErr
+					},
+				}
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodPost, "/api/v1/auth/personal-tokens", data.GenerateTestToken(), tt.requestBody)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
+
+func TestPersonalTokensHandlers(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{"List", http.MethodGet, "/api/v1/auth/personal-tokens", http.StatusOK, `"name": "Mocked CI"`},
+		{"Delete", http.MethodDelete, "/api/v1/auth/personal-tokens/1", http.StatusOK, "personal access token revoked successfully"},
+		{"Delete someone else's token", http.MethodDelete, "/api/v1/auth/personal-tokens/2", http.StatusNotFound, ""},
+		{"Delete with invalid id", http.MethodDelete, "/api/v1/auth/personal-tokens/abc", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, 1, data.RoleReader)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, tt.method, tt.urlPath, data.GenerateTestToken(), "")
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
+
+// Requests made with a personal access token are limited by both the user's role and the token's scopes.
+func TestPersonalTokenAuthentication(t *testing.T) {
+	token := data.PersonalTokenPrefix + data.GenerateTestToken()
+
+	tests := []struct {
+		name     string
+		token    string
+		method   string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{"Scope granted", token, http.MethodGet, "/api/v1/post/1/history", http.StatusOK, ""},
+		{"Scope not granted", token, http.MethodPost, "/api/v1/post/1/publish", http.StatusForbidden, "doesn't have the necessary permissions"},
+		{"Read scope not granted", token, http.MethodGet, "/api/v1/feed", http.StatusForbidden, "doesn't have the necessary permissions"},
+		{"Session route", token, http.MethodGet, "/api/v1/auth/sessions", http.StatusForbidden, "personal access tokens can't be used to access this resource"},
+		{"Token management", token, http.MethodPost, "/api/v1/auth/personal-tokens", http.StatusForbidden, "personal access tokens can't be used to access this resource"},
+		{"Malformed token", data.PersonalTokenPrefix + "abc", http.MethodGet, "/api/v1/post/1/history", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+
+			// An editor's role grants publishing, but the token is only scoped to writing posts.
+			app.models.PersonalTokens = data.MockPersonalTokenModel{
+				MockGetUserForToken: func(tokenPlainText string) (*data.User, error) {
+					assert.Equal(t, tokenPlainText, token)
+					return &data.User{ID: 1, Name: "Mocked Name", Activated: true, Role: data.RoleEditor, Scopes: data.Permissions{data.PermissionPostsWrite}}, nil
+				},
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, tt.method, tt.urlPath, tt.token, "")
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
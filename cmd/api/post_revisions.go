@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+	"github.com/AthfanFasee/blog-post-backend/internal/diff"
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+)
+
+func (app *application) showPostRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	post, _, ok := app.getModifiablePost(w, r)
+	if !ok {
+		return
+	}
+
+	revisions, err := app.models.PostRevisions.GetAllForPost(post.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"revisions": revisions}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Show a single revision along with a line-based diff from the revision's text to the current text. diffTooLarge is
+// set instead when the texts differ in too many lines.
+func (app *application) showPostRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	post, _, ok := app.getModifiablePost(w, r)
+	if !ok {
+		return
+	}
+
+	revision, ok := app.getPostRevision(w, r, post.ID)
+	if !ok {
+		return
+	}
+
+	PostRevisionResponseBody := dto.PostRevisionResponseBody{
+		Revision:  revision.Revision,
+		Title:     revision.Title,
+		PostText:  revision.PostText,
+		CreatedBy: revision.CreatedBy,
+		CreatedAt: revision.CreatedAt,
+	}
+
+	// Texts too large to diff are still shown, the diff is just left out.
+	lines, err := diff.Lines(revision.PostText, post.PostText)
+	if err != nil && !errors.Is(err, diff.ErrTooLarge) {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	env := envelope{
+		"revision":     PostRevisionResponseBody,
+		"titleChanged": revision.Title != post.Title,
+		"diff":         lines,
+		"diffTooLarge": errors.Is(err, diff.ErrTooLarge),
+	}
+
+	err = app.writeJSON(w, http.StatusOK, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Restoring a revision saves its title and text as a new revision, so the history is never rewritten.
+func (app *application) restorePostRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	post, userName, ok := app.getModifiablePost(w, r)
+	if !ok {
+		return
+	}
+
+	revision, ok := app.getPostRevision(w, r, post.ID)
+	if !ok {
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	post.Title = revision.Title
+	post.PostText = revision.PostText
+
+	err := app.models.Posts.Update(post, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.readViewerState(post, user)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	PostResponseBody := dto.PostResponseBody{
+		ID:             post.ID,
+		Title:          post.Title,
+		Slug:           post.Slug,
+		PostText:       post.PostText,
+		Img:            post.Img,
+		ReadTime:       post.ReadTime,
+		LikesCount:     post.LikesCount,
+		LikedByMe:      post.LikedByMe,
+		BookmarkedByMe: post.BookmarkedByMe,
+		Tags:           post.Tags,
+		Status:         post.Status,
+		PublishAt:      post.PublishAt,
+		PublishedAt:    post.PublishedAt,
+		CommentsCount:  post.CommentsCount,
+		Hidden:         post.Hidden,
+		CreatedAt:      post.CreatedAt,
+		CreatedBy:      post.CreatedBy,
+		UserName:       userName,
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"post": PostResponseBody}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Get the post from the id param along with its author's name, making sure the current user can modify it.
+// It writes the error response itself and returns false when the handler should stop.
+func (app *application) getModifiablePost(w http.ResponseWriter, r *http.Request) (*data.Post, string, bool) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, "", false
+	}
+
+	post, userName, err := app.models.Posts.GetWithUserName(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, "", false
+	}
+
+	if !app.contextGetUser(r).CanModify(post.CreatedBy) {
+		app.notPermittedResponse(w, r)
+		return nil, "", false
+	}
+
+	return post, *userName, true
+}
+
+func (app *application) getPostRevision(w http.ResponseWriter, r *http.Request, postID int64) (*data.PostRevision, bool) {
+	rev, err := app.readRevisionParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	revision, err := app.models.PostRevisions.Get(postID, rev)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, false
+	}
+
+	return revision, true
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+)
+
+func TestPostRevisionHandlers(t *testing.T) {
+	// Mocked post 1 created by user 1 has a single mocked revision 1.
+	tests := []struct {
+		name     string
+		userID   int64
+		role     string
+		method   string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Owner lists revisions", userID: 1, role: data.RoleAuthor, method: http.MethodGet, urlPath: "/api/v1/post/1/revisions", wantCode: http.StatusOK, wantBody: "Mocked Revision Title"},
+		{name: "Non-owner lists revisions", userID: 2, role: data.RoleAuthor, method: http.MethodGet, urlPath: "/api/v1/post/1/revisions", wantCode: http.StatusForbidden},
+		{name: "Editor lists revisions", userID: 2, role: data.RoleEditor, method: http.MethodGet, urlPath: "/api/v1/post/1/revisions", wantCode: http.StatusOK},
+		{name: "Owner shows revision diff", userID: 1, role: data.RoleAuthor, method: http.MethodGet, urlPath: "/api/v1/post/1/revisions/1", wantCode: http.StatusOK, wantBody: `"op": "insert"`},
+		{name: "Non-existent revision", userID: 1, role: data.RoleAuthor, method: http.MethodGet, urlPath: "/api/v1/post/1/revisions/2", wantCode: http.StatusNotFound},
+		{name: "Invalid revision", userID: 1, role: data.RoleAuthor, method: http.MethodGet, urlPath: "/api/v1/post/1/revisions/one", wantCode: http.StatusNotFound},
+		{name: "Owner restores revision", userID: 1, role: data.RoleAuthor, method: http.MethodPost, urlPath: "/api/v1/post/1/revisions/1/restore", wantCode: http.StatusOK, wantBody: "Mocked Revision PostText"},
+		{name: "Non-owner restores revision", userID: 2, role: data.RoleAuthor, method: http.MethodPost, urlPath: "/api/v1/post/1/revisions/1/restore", wantCode: http.StatusForbidden},
+		{name: "Non-existent post", userID: 1, role: data.RoleAuthor, method: http.MethodGet, urlPath: "/api/v1/post/2/revisions", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, tt.userID, tt.role)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, tt.method, tt.urlPath, data.GenerateTestToken(), "")
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantBody != "" {
+				assert.StringContains(t, body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+)
+
+// Authors send their drafts to editors for review.
+func (app *application) submitPostHandler(w http.ResponseWriter, r *http.Request) {
+	app.changePostStatus(w, r, data.PostStatusInReview, nil)
+}
+
+// Only users with the posts:publish permission reach this handler.
+func (app *application) publishPostHandler(w http.ResponseWriter, r *http.Request) {
+	app.changePostStatus(w, r, data.PostStatusPublished, nil)
+}
+
+// Queue a post under review to be published by the scheduler at a future time.
+// Only users with the posts:publish permission reach this handler.
+func (app *application) schedulePostHandler(w http.ResponseWriter, r *http.Request) {
+	var input dto.SchedulePostRequestBody
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if data.ValidatePublishAt(v, input.PublishAt); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	app.changePostStatus(w, r, data.PostStatusScheduled, input.PublishAt)
+}
+
+func (app *application) archivePostHandler(w http.ResponseWriter, r *http.Request) {
+	app.changePostStatus(w, r, data.PostStatusArchived, nil)
+}
+
+// Moves a post under review (withdrawn by its author or rejected by an editor), a scheduled post or an archived post back to draft.
+func (app *application) draftPostHandler(w http.ResponseWriter, r *http.Request) {
+	app.changePostStatus(w, r, data.PostStatusDraft, nil)
+}
+
+func (app *application) showPostStatusHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	post, err := app.models.Posts.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	if !user.CanModify(post.CreatedBy) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	history, err := app.models.Posts.GetStatusHistory(post.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"history": history}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// publishAt is only used when scheduling a post, every other transition clears it.
+func (app *application) changePostStatus(w http.ResponseWriter, r *http.Request, status string, publishAt *time.Time) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	post, userName, err := app.models.Posts.GetWithUserName(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	if !user.CanModify(post.CreatedBy) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	v := validator.New()
+
+	if data.ValidatePostTransition(v, post.Status, status); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	post.PublishAt = publishAt
+
+	err = app.models.Posts.UpdateStatus(post, status, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.readViewerState(post, user)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	PostResponseBody := dto.PostResponseBody{
+		ID:             post.ID,
+		Title:          post.Title,
+		Slug:           post.Slug,
+		PostText:       post.PostText,
+		Img:            post.Img,
+		ReadTime:       post.ReadTime,
+		LikesCount:     post.LikesCount,
+		LikedByMe:      post.LikedByMe,
+		BookmarkedByMe: post.BookmarkedByMe,
+		Tags:           post.Tags,
+		Status:         post.Status,
+		PublishAt:      post.PublishAt,
+		PublishedAt:    post.PublishedAt,
+		CommentsCount:  post.CommentsCount,
+		Hidden:         post.Hidden,
+		CreatedAt:      post.CreatedAt,
+		CreatedBy:      post.CreatedBy,
+		UserName:       *userName,
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"post": PostResponseBody}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+)
+
+func TestChangePostStatusHandlers(t *testing.T) {
+	// Mocked post 1 is published and mocked post 3 is a draft, both created by user 1.
+	tests := []struct {
+		name     string
+		userID   int64
+		role     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Owner submits draft", userID: 1, role: data.RoleAuthor, urlPath: "/api/v1/post/3/submit", wantCode: http.StatusOK, wantBody: data.PostStatusInReview},
+		{name: "Non-owner submits draft", userID: 2, role: data.RoleAuthor, urlPath: "/api/v1/post/3/submit", wantCode: http.StatusForbidden},
+		{name: "Owner submits published post", userID: 1, role: data.RoleAuthor, urlPath: "/api/v1/post/1/submit", wantCode: http.StatusUnprocessableEntity},
+		{name: "Author publishes", userID: 1, role: data.RoleAuthor, urlPath: "/api/v1/post/3/publish", wantCode: http.StatusForbidden},
+		{name: "Editor publishes draft", userID: 2, role: data.RoleEditor, urlPath: "/api/v1/post/3/publish", wantCode: http.StatusUnprocessableEntity},
+		{name: "Owner archives published post", userID: 1, role: data.RoleAuthor, urlPath: "/api/v1/post/1/archive", wantCode: http.StatusOK, wantBody: data.PostStatusArchived},
+		{name: "Admin archives published post", userID: 2, role: data.RoleAdmin, urlPath: "/api/v1/post/1/archive", wantCode: http.StatusOK, wantBody: data.PostStatusArchived},
+		{name: "Owner moves draft to draft", userID: 1, role: data.RoleAuthor, urlPath: "/api/v1/post/3/draft", wantCode: http.StatusUnprocessableEntity},
+		{name: "Reader submits draft", userID: 1, role: data.RoleReader, urlPath: "/api/v1/post/3/submit", wantCode: http.StatusForbidden},
+		{name: "Non-existent ID", userID: 1, role: data.RoleAuthor, urlPath: "/api/v1/post/2/submit", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, tt.userID, tt.role)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodPost, tt.urlPath, data.GenerateTestToken(), "")
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantBody != "" {
+				assert.StringContains(t, body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestShowPostStatusHistoryHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   int64
+		role     string
+		wantCode int
+	}{
+		{name: "Owner", userID: 1, role: data.RoleAuthor, wantCode: http.StatusOK},
+		{name: "Non-owner", userID: 2, role: data.RoleAuthor, wantCode: http.StatusForbidden},
+		{name: "Editor", userID: 2, role: data.RoleEditor, wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, tt.userID, tt.role)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, _ := ts.do(t, http.MethodGet, "/api/v1/post/1/history", data.GenerateTestToken(), "")
+
+			assert.Equal(t, code, tt.wantCode)
+		})
+	}
+}
+
+func TestSchedulePostHandler(t *testing.T) {
+	publishAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	future := publishAt.Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+
+	// Mocked post 3 is a draft, so scheduling it is rejected even for editors. Mocked post 4 is in review.
+	tests := []struct {
+		name        string
+		role        string
+		urlPath     string
+		requestBody string
+		wantCode    int
+	}{
+		{name: "Editor schedules post in review", role: data.RoleEditor, urlPath: "/api/v1/post/4/schedule", requestBody: `{"publishAt":"` + future + `"}`, wantCode: http.StatusOK},
+		{name: "Author schedules", role: data.RoleAuthor, urlPath: "/api/v1/post/3/schedule", requestBody: `{"publishAt":"` + future + `"}`, wantCode: http.StatusForbidden},
+		{name: "Editor schedules draft", role: data.RoleEditor, urlPath: "/api/v1/post/3/schedule", requestBody: `{"publishAt":"` + future + `"}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "Publish time in the past", role: data.RoleEditor, urlPath: "/api/v1/post/3/schedule", requestBody: `{"publishAt":"` + past + `"}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "Missing publish time", role: data.RoleEditor, urlPath: "/api/v1/post/3/schedule", requestBody: `{}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "Malformed publish time", role: data.RoleEditor, urlPath: "/api/v1/post/3/schedule", requestBody: `{"publishAt":"tomorrow"}`, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, 2, tt.role)
+
+			var stored *data.Post
+
+			app.models.Posts = data.MockPostModel{
+				MockUpdateStatus: func(post *data.Post, status string, changedBy int64) error {
+					post.Status = status
+					stored = post
+					return nil
+				},
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodPost, tt.urlPath, data.GenerateTestToken(), tt.requestBody)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, stored.Status, data.PostStatusScheduled)
+				assert.Equal(t, stored.PublishAt != nil && stored.PublishAt.Equal(publishAt), true)
+				assert.StringContains(t, body, `"publishAt": "`+future+`"`)
+			}
+		})
+	}
+}
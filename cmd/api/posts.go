@@ -8,11 +8,13 @@ import (
 	"github.com/AthfanFasee/blog-post-backend/internal/data"
 	"github.com/AthfanFasee/blog-post-backend/internal/dto"
 	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+	"github.com/julienschmidt/httprouter"
 )
 
 func (app *application) showPostsHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		Title string
+		Title  string
+		Status string
 		data.Filters
 	}
 
@@ -23,19 +25,35 @@ func (app *application) showPostsHandler(w http.ResponseWriter, r *http.Request)
 
 	input.Filters.Sort = app.readString(queryString, "sort", "-id")
 	input.Title = app.readString(queryString, "title", "")
+	input.Status = app.readString(queryString, "status", "")
 	input.Filters.Page = app.readInt(queryString, "page", 1, v)
 	input.Filters.ID = app.readInt(queryString, "id", 0, v)
 	input.Filters.Limit = app.readInt(queryString, "limit", 6, v)
+	input.Filters.Tags = data.NormalizeTags(app.readCSV(queryString, "tags", []string{}))
+	input.Filters.TagMode = app.readString(queryString, "tag_mode", data.TagModeAny)
+	input.Filters.After = app.readString(queryString, "after", "")
+	input.Filters.Before = app.readString(queryString, "before", "")
 
 	// Add the supported sort values for this endpoint to the sort safelist.
 	input.Filters.SortSafeList = []string{"id", "title", "readtime", "likescount", "-id", "-title", "-readtime", "-likescount"}
 
+	data.ValidateTagMode(v, input.Filters.TagMode)
+	data.ValidatePostStatusFilter(v, input.Status)
+
 	if data.ValidateFilters(v, input.Filters); !v.Valid() {
 		app.validationFailedResponse(w, r, v.Errors)
 		return
 	}
 
-	posts, metadata, err := app.models.Posts.GetAll(input.Title, input.Filters)
+	user := app.contextGetUser(r)
+
+	// Posts waiting for review are listed for the editors who publish them.
+	if input.Status != "" && !user.HasPermission(data.PermissionPostsPublish) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	posts, metadata, err := app.models.Posts.GetAll(input.Title, input.Status, user.ReaderID(data.PermissionPostsRead), input.Filters)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -46,6 +64,12 @@ func (app *application) showPostsHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	err = app.attachPostReactions(posts, user)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
 	err = app.writeJSON(w, http.StatusOK, envelope{"posts": posts, "metadata": metadata}, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -70,15 +94,43 @@ func (app *application) showSinglePostHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Hide unpublished posts from everyone except users who can modify them.
+	if !post.VisibleTo(app.contextGetUser(r)) {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.readViewerState(post, app.contextGetUser(r))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
 	PostResponseBody := dto.PostResponseBody{
-		ID:        post.ID,
-		Title:     post.Title,
-		PostText:  post.PostText,
-		Img:       post.Img,
-		ReadTime:  post.ReadTime,
-		CreatedAt: post.CreatedAt,
-		CreatedBy: post.CreatedBy,
-		UserName:  *userName,
+		ID:             post.ID,
+		Title:          post.Title,
+		Slug:           post.Slug,
+		PostText:       post.PostText,
+		Img:            post.Img,
+		ReadTime:       post.ReadTime,
+		LikesCount:     post.LikesCount,
+		LikedByMe:      post.LikedByMe,
+		BookmarkedByMe: post.BookmarkedByMe,
+		Tags:           post.Tags,
+		Status:         post.Status,
+		PublishAt:      post.PublishAt,
+		PublishedAt:    post.PublishedAt,
+		CommentsCount:  post.CommentsCount,
+		Hidden:         post.Hidden,
+		CreatedAt:      post.CreatedAt,
+		CreatedBy:      post.CreatedBy,
+		UserName:       *userName,
+	}
+
+	err = app.attachPostReactions([]*dto.PostResponseBody{&PostResponseBody}, app.contextGetUser(r))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
 	}
 
 	err = app.writeJSON(w, http.StatusOK, envelope{"post": PostResponseBody}, nil)
@@ -87,6 +139,75 @@ func (app *application) showSinglePostHandler(w http.ResponseWriter, r *http.Req
 	}
 }
 
+// Resolve a post by its current or any previous slug. When an old slug is used the response carries a
+// redirect hint pointing at the post's current permalink.
+func (app *application) showPostBySlugHandler(w http.ResponseWriter, r *http.Request) {
+	postSlug := httprouter.ParamsFromContext(r.Context()).ByName("slug")
+
+	post, userName, err := app.models.Posts.GetBySlug(postSlug)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !post.VisibleTo(app.contextGetUser(r)) {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.readViewerState(post, app.contextGetUser(r))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	PostResponseBody := dto.PostResponseBody{
+		ID:             post.ID,
+		Title:          post.Title,
+		Slug:           post.Slug,
+		PostText:       post.PostText,
+		Img:            post.Img,
+		ReadTime:       post.ReadTime,
+		LikesCount:     post.LikesCount,
+		LikedByMe:      post.LikedByMe,
+		BookmarkedByMe: post.BookmarkedByMe,
+		Tags:           post.Tags,
+		Status:         post.Status,
+		PublishAt:      post.PublishAt,
+		PublishedAt:    post.PublishedAt,
+		CommentsCount:  post.CommentsCount,
+		Hidden:         post.Hidden,
+		CreatedAt:      post.CreatedAt,
+		CreatedBy:      post.CreatedBy,
+		UserName:       *userName,
+	}
+
+	err = app.attachPostReactions([]*dto.PostResponseBody{&PostResponseBody}, app.contextGetUser(r))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	env := envelope{"post": PostResponseBody}
+
+	if post.Slug != postSlug {
+		env["redirect"] = map[string]string{
+			"slug":     post.Slug,
+			"location": "/api/v1/posts/by-slug/" + post.Slug,
+		}
+	}
+
+	err = app.writeJSON(w, http.StatusOK, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
 func (app *application) createPostHandler(w http.ResponseWriter, r *http.Request) {
 	var input dto.CreatePostRequestBody
 	// Decoding JSON values in to input struct
@@ -103,11 +224,14 @@ func (app *application) createPostHandler(w http.ResponseWriter, r *http.Request
 		PostText:  strings.TrimSpace(input.PostText),
 		Img:       input.Img,
 		ReadTime:  input.ReadTime,
+		Tags:      data.NormalizeTags(input.Tags),
 		CreatedBy: user.ID,
 	}
 
 	v := validator.New()
 
+	data.ValidateTags(v, post.Tags)
+
 	if data.ValidatePost(v, post); !v.Valid() {
 		app.validationFailedResponse(w, r, v.Errors)
 		return
@@ -122,9 +246,12 @@ func (app *application) createPostHandler(w http.ResponseWriter, r *http.Request
 	PostResponseBody := dto.PostResponseBody{
 		ID:        post.ID,
 		Title:     post.Title,
+		Slug:      post.Slug,
 		PostText:  post.PostText,
 		Img:       post.Img,
 		ReadTime:  post.ReadTime,
+		Tags:      post.Tags,
+		Status:    post.Status,
 		CreatedAt: post.CreatedAt,
 		CreatedBy: user.ID,
 		UserName:  user.Name,
@@ -140,10 +267,11 @@ func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request
 	id, err := app.readIDParam(r)
 	if err != nil {
 		app.badRequestResponse(w, r, err)
+		return
 	}
 
-	// Check if a post with provided id exists.
-	post, err := app.models.Posts.Get(id)
+	// Check if a post with provided id exists. Return username of the user who created the post as well.
+	post, userName, err := app.models.Posts.GetWithUserName(id)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -154,6 +282,13 @@ func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	user := app.contextGetUser(r)
+
+	if !user.CanModify(post.CreatedBy) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
 	var input dto.UpdatePostRequestBody
 
 	// Decoding JSON values in to input struct.
@@ -176,6 +311,9 @@ func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request
 	if input.ReadTime != nil {
 		post.ReadTime = *input.ReadTime
 	}
+	if input.Tags != nil {
+		post.Tags = data.NormalizeTags(*input.Tags)
+	}
 
 	v := validator.New()
 
@@ -187,12 +325,14 @@ func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request
 		v.AddError("postText", "must be provided")
 	}
 
+	data.ValidateTags(v, post.Tags)
+
 	if data.ValidatePost(v, post); !v.Valid() {
 		app.validationFailedResponse(w, r, v.Errors)
 		return
 	}
 
-	err = app.models.Posts.Update(post)
+	err = app.models.Posts.Update(post, user.ID)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrEditConflict):
@@ -203,18 +343,31 @@ func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	user := app.contextGetUser(r)
+	err = app.readViewerState(post, user)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
 
 	PostResponseBody := dto.PostResponseBody{
-		ID:        post.ID,
-		Title:     post.Title,
-		PostText:  post.PostText,
-		Img:       post.Img,
-		ReadTime:  post.ReadTime,
-		LikedBy:   post.LikedBy,
-		CreatedAt: post.CreatedAt,
-		CreatedBy: post.CreatedBy,
-		UserName:  user.Name,
+		ID:             post.ID,
+		Title:          post.Title,
+		Slug:           post.Slug,
+		PostText:       post.PostText,
+		Img:            post.Img,
+		ReadTime:       post.ReadTime,
+		LikesCount:     post.LikesCount,
+		LikedByMe:      post.LikedByMe,
+		BookmarkedByMe: post.BookmarkedByMe,
+		Tags:           post.Tags,
+		Status:         post.Status,
+		PublishAt:      post.PublishAt,
+		PublishedAt:    post.PublishedAt,
+		CommentsCount:  post.CommentsCount,
+		Hidden:         post.Hidden,
+		CreatedAt:      post.CreatedAt,
+		CreatedBy:      post.CreatedBy,
+		UserName:       *userName,
 	}
 
 	err = app.writeJSON(w, http.StatusOK, envelope{"post": PostResponseBody}, nil)
@@ -227,9 +380,28 @@ func (app *application) deletePostHandler(w http.ResponseWriter, r *http.Request
 	id, err := app.readIDParam(r)
 	if err != nil {
 		app.notFoundResponse(w, r)
+		return
 	}
 
-	err = app.models.Posts.Delete(id)
+	post, err := app.models.Posts.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	if !user.CanModify(post.CreatedBy) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	err = app.models.Posts.Delete(post.ID)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -250,6 +422,7 @@ func (app *application) likePostHandler(w http.ResponseWriter, r *http.Request)
 	id, err := app.readIDParam(r)
 	if err != nil {
 		app.notFoundResponse(w, r)
+		return
 	}
 
 	// Check if a post with provided id exists. Return username of the user who created the post as well.
@@ -266,6 +439,17 @@ func (app *application) likePostHandler(w http.ResponseWriter, r *http.Request)
 
 	user := app.contextGetUser(r)
 
+	if !post.VisibleTo(user) {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.readViewerState(post, user)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
 	err = app.models.Posts.AddLike(post, user.ID)
 	if err != nil {
 		app.badRequestResponse(w, r, err)
@@ -273,15 +457,24 @@ func (app *application) likePostHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	PostResponseBody := dto.PostResponseBody{
-		ID:        post.ID,
-		Title:     post.Title,
-		PostText:  post.PostText,
-		Img:       post.Img,
-		ReadTime:  post.ReadTime,
-		LikedBy:   post.LikedBy,
-		CreatedAt: post.CreatedAt,
-		CreatedBy: post.CreatedBy,
-		UserName:  *userName,
+		ID:             post.ID,
+		Title:          post.Title,
+		Slug:           post.Slug,
+		PostText:       post.PostText,
+		Img:            post.Img,
+		ReadTime:       post.ReadTime,
+		LikesCount:     post.LikesCount,
+		LikedByMe:      post.LikedByMe,
+		BookmarkedByMe: post.BookmarkedByMe,
+		Tags:           post.Tags,
+		Status:         post.Status,
+		PublishAt:      post.PublishAt,
+		PublishedAt:    post.PublishedAt,
+		CommentsCount:  post.CommentsCount,
+		Hidden:         post.Hidden,
+		CreatedAt:      post.CreatedAt,
+		CreatedBy:      post.CreatedBy,
+		UserName:       *userName,
 	}
 
 	err = app.writeJSON(w, http.StatusOK, envelope{"post": PostResponseBody}, nil)
@@ -294,6 +487,7 @@ func (app *application) dislikePostHandler(w http.ResponseWriter, r *http.Reques
 	id, err := app.readIDParam(r)
 	if err != nil {
 		app.notFoundResponse(w, r)
+		return
 	}
 
 	post, userName, err := app.models.Posts.GetWithUserName(id)
@@ -309,6 +503,17 @@ func (app *application) dislikePostHandler(w http.ResponseWriter, r *http.Reques
 
 	user := app.contextGetUser(r)
 
+	if !post.VisibleTo(user) {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.readViewerState(post, user)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
 	err = app.models.Posts.RemoveLike(post, user.ID)
 	if err != nil {
 		app.badRequestResponse(w, r, err)
@@ -316,15 +521,24 @@ func (app *application) dislikePostHandler(w http.ResponseWriter, r *http.Reques
 	}
 
 	PostResponseBody := dto.PostResponseBody{
-		ID:        post.ID,
-		Title:     post.Title,
-		PostText:  post.PostText,
-		Img:       post.Img,
-		ReadTime:  post.ReadTime,
-		LikedBy:   post.LikedBy,
-		CreatedAt: post.CreatedAt,
-		CreatedBy: post.CreatedBy,
-		UserName:  *userName,
+		ID:             post.ID,
+		Title:          post.Title,
+		Slug:           post.Slug,
+		PostText:       post.PostText,
+		Img:            post.Img,
+		ReadTime:       post.ReadTime,
+		LikesCount:     post.LikesCount,
+		LikedByMe:      post.LikedByMe,
+		BookmarkedByMe: post.BookmarkedByMe,
+		Tags:           post.Tags,
+		Status:         post.Status,
+		PublishAt:      post.PublishAt,
+		PublishedAt:    post.PublishedAt,
+		CommentsCount:  post.CommentsCount,
+		Hidden:         post.Hidden,
+		CreatedAt:      post.CreatedAt,
+		CreatedBy:      post.CreatedBy,
+		UserName:       *userName,
 	}
 
 	err = app.writeJSON(w, http.StatusOK, envelope{"post": PostResponseBody}, nil)
@@ -332,3 +546,12 @@ func (app *application) dislikePostHandler(w http.ResponseWriter, r *http.Reques
 		app.serverErrorResponse(w, r, err)
 	}
 }
+
+// Fill in whether the user reading the post likes and has bookmarked it. Anonymous users do neither.
+func (app *application) readViewerState(post *data.Post, user *data.User) error {
+	if user.IsAnonymous() {
+		return nil
+	}
+
+	return app.models.Posts.GetViewerState(post, user.ID)
+}
@@ -2,9 +2,11 @@ package main
 
 import (
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
 )
 
 func TestShowPostsHandler(t *testing.T) {
@@ -78,6 +80,47 @@ func TestShowPostsHandler(t *testing.T) {
 			urlPath:  "/api/v1/posts?id=one",
 			wantCode: http.StatusUnprocessableEntity,
 		},
+		{
+			name:     "Tags Param",
+			urlPath:  "/api/v1/posts?tags=Go,postgres&tag_mode=all",
+			wantCode: http.StatusOK,
+			wantBody: `"go"`,
+		},
+		{
+			name:     "Unknown Tag Param",
+			urlPath:  "/api/v1/posts?tags=unknown",
+			wantCode: http.StatusOK,
+			wantBody: `"posts": []`,
+		},
+		{
+			name:     "Invalid Tag Mode Param",
+			urlPath:  "/api/v1/posts?tags=go&tag_mode=some",
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "After Cursor Param",
+			urlPath:  "/api/v1/posts?after=eyJzIjoiLWlkIiwiayI6IjEiLCJpIjoxfQ",
+			wantCode: http.StatusOK,
+			wantBody: "Mocked Post Title",
+		},
+		{
+			name:     "Malformed Cursor Param",
+			urlPath:  "/api/v1/posts?before=not-a-cursor",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "must be a cursor from a previous page",
+		},
+		{
+			name:     "Cursor For Another Sort",
+			urlPath:  "/api/v1/posts?after=eyJzIjoidGl0bGUiLCJrIjoiYSIsImkiOjF9",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "was made for a different sort",
+		},
+		{
+			name:     "After And Before Params",
+			urlPath:  "/api/v1/posts?after=eyJzIjoiLWlkIiwiayI6IjEiLCJpIjoxfQ&before=eyJzIjoiLWlkIiwiayI6IjEiLCJpIjoxfQ",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "cannot be used together with after",
+		},
 	}
 
 	for _, tt := range tests {
@@ -93,6 +136,36 @@ func TestShowPostsHandler(t *testing.T) {
 	}
 }
 
+func TestShowPostsInReview(t *testing.T) {
+	// Mocked post 4 is in review.
+	tests := []struct {
+		name     string
+		role     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Editor", role: data.RoleEditor, urlPath: "/api/v1/posts?status=in_review", wantCode: http.StatusOK, wantBody: "Mocked In Review Title"},
+		{name: "Author", role: data.RoleAuthor, urlPath: "/api/v1/posts?status=in_review", wantCode: http.StatusForbidden},
+		{name: "Other status", role: data.RoleEditor, urlPath: "/api/v1/posts?status=draft", wantCode: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, 2, tt.role)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodGet, tt.urlPath, data.GenerateTestToken(), "")
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
+
 func TestShowSinglePostHandler(t *testing.T) {
 	app := newTestApplication(t)
 
@@ -111,6 +184,12 @@ func TestShowSinglePostHandler(t *testing.T) {
 			wantCode: http.StatusOK,
 			wantBody: "Mocked Post Title",
 		},
+		{
+			name:     "Comments Count",
+			urlPath:  "/api/v1/post/1",
+			wantCode: http.StatusOK,
+			wantBody: `"commentsCount": 1`,
+		},
 		{
 			name:     "Non-existent ID",
 			urlPath:  "/api/v1/post/2",
@@ -142,6 +221,183 @@ func TestShowSinglePostHandler(t *testing.T) {
 			wantCode: http.StatusOK,
 			wantBody: "Mocked User",
 		},
+		{
+			name:     "Draft is hidden from anonymous users",
+			urlPath:  "/api/v1/post/3",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.get(t, tt.urlPath)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantBody != "" {
+				assert.StringContains(t, body, tt.wantBody)
+			}
+		})
+	}
+}
+
+// Mocked post 1 is created by user 1.
+var postAuthorizationTests = []struct {
+	name     string
+	userID   int64
+	role     string
+	token    bool
+	wantCode int
+}{
+	{name: "Anonymous user", wantCode: http.StatusUnauthorized},
+	{name: "Reader owner", userID: 1, role: data.RoleReader, token: true, wantCode: http.StatusForbidden},
+	{name: "Reader non-owner", userID: 2, role: data.RoleReader, token: true, wantCode: http.StatusForbidden},
+	{name: "Author owner", userID: 1, role: data.RoleAuthor, token: true, wantCode: http.StatusOK},
+	{name: "Author non-owner", userID: 2, role: data.RoleAuthor, token: true, wantCode: http.StatusForbidden},
+	{name: "Editor owner", userID: 1, role: data.RoleEditor, token: true, wantCode: http.StatusOK},
+	{name: "Editor non-owner", userID: 2, role: data.RoleEditor, token: true, wantCode: http.StatusOK},
+	{name: "Admin owner", userID: 1, role: data.RoleAdmin, token: true, wantCode: http.StatusOK},
+	{name: "Admin non-owner", userID: 2, role: data.RoleAdmin, token: true, wantCode: http.StatusOK},
+}
+
+func TestUpdatePostHandler(t *testing.T) {
+	requestBody := `{"title":"Updated Title","postText":"Updated Text"}`
+
+	for _, tt := range postAuthorizationTests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, tt.userID, tt.role)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			token := ""
+			if tt.token {
+				token = data.GenerateTestToken()
+			}
+
+			code, _, body := ts.do(t, http.MethodPatch, "/api/v1/post/1", token, requestBody)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantCode == http.StatusOK {
+				assert.StringContains(t, body, "Updated Title")
+				// The response carries the name of the post's author, not the editor's.
+				assert.StringContains(t, body, "Mocked User")
+			}
+		})
+	}
+
+	t.Run("Non-existent ID", func(t *testing.T) {
+		app := newTestApplication(t)
+		authenticateAs(app, 1, data.RoleAuthor)
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		code, _, _ := ts.do(t, http.MethodPatch, "/api/v1/post/2", data.GenerateTestToken(), requestBody)
+
+		assert.Equal(t, code, http.StatusNotFound)
+	})
+}
+
+func TestDeletePostHandler(t *testing.T) {
+	for _, tt := range postAuthorizationTests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, tt.userID, tt.role)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			token := ""
+			if tt.token {
+				token = data.GenerateTestToken()
+			}
+
+			code, _, _ := ts.do(t, http.MethodDelete, "/api/v1/post/1", token, "")
+
+			assert.Equal(t, code, tt.wantCode)
+		})
+	}
+
+	t.Run("Non-existent ID", func(t *testing.T) {
+		app := newTestApplication(t)
+		authenticateAs(app, 1, data.RoleAuthor)
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		code, _, _ := ts.do(t, http.MethodDelete, "/api/v1/post/2", data.GenerateTestToken(), "")
+
+		assert.Equal(t, code, http.StatusNotFound)
+	})
+}
+
+func TestShowDraftPostHandler(t *testing.T) {
+	// Mocked post 3 is a draft created by user 1.
+	tests := []struct {
+		name     string
+		userID   int64
+		role     string
+		wantCode int
+	}{
+		{name: "Owner", userID: 1, role: data.RoleAuthor, wantCode: http.StatusOK},
+		{name: "Non-owner", userID: 2, role: data.RoleAuthor, wantCode: http.StatusNotFound},
+		{name: "Editor", userID: 2, role: data.RoleEditor, wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, tt.userID, tt.role)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, _ := ts.do(t, http.MethodGet, "/api/v1/post/3", data.GenerateTestToken(), "")
+
+			assert.Equal(t, code, tt.wantCode)
+		})
+	}
+}
+
+func TestShowPostBySlugHandler(t *testing.T) {
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	tests := []struct {
+		name         string
+		urlPath      string
+		wantCode     int
+		wantBody     string
+		wantRedirect bool
+	}{
+		{
+			name:     "Current slug",
+			urlPath:  "/api/v1/posts/by-slug/mocked-post-title",
+			wantCode: http.StatusOK,
+			wantBody: "Mocked Post Title",
+		},
+		{
+			name:         "Old slug",
+			urlPath:      "/api/v1/posts/by-slug/old-mocked-post-title",
+			wantCode:     http.StatusOK,
+			wantBody:     `"location": "/api/v1/posts/by-slug/mocked-post-title"`,
+			wantRedirect: true,
+		},
+		{
+			name:     "Draft is hidden from anonymous users",
+			urlPath:  "/api/v1/posts/by-slug/mocked-draft-title",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Non-existent slug",
+			urlPath:  "/api/v1/posts/by-slug/missing",
+			wantCode: http.StatusNotFound,
+		},
 	}
 
 	for _, tt := range tests {
@@ -153,6 +409,45 @@ func TestShowSinglePostHandler(t *testing.T) {
 			if tt.wantBody != "" {
 				assert.StringContains(t, body, tt.wantBody)
 			}
+
+			assert.Equal(t, strings.Contains(body, `"redirect"`), tt.wantRedirect)
+		})
+	}
+}
+
+func TestLikePostHandler(t *testing.T) {
+	// Mocked post 1 is liked by users 1 and 2, post 3 is a draft of user 1.
+	tests := []struct {
+		name     string
+		userID   int64
+		method   string
+		urlPath  string
+		wantCode int
+		wantBody []string
+	}{
+		{name: "Like", userID: 3, method: http.MethodPatch, urlPath: "/api/v1/posts/like/1", wantCode: http.StatusOK, wantBody: []string{`"likesCount": 3`, `"likedByMe": true`}},
+		{name: "Dislike", userID: 3, method: http.MethodPatch, urlPath: "/api/v1/posts/dislike/1", wantCode: http.StatusOK, wantBody: []string{`"likedByMe": false`}},
+		{name: "Draft of another user", userID: 3, method: http.MethodPatch, urlPath: "/api/v1/posts/like/3", wantCode: http.StatusNotFound},
+		{name: "Non-existent ID", userID: 3, method: http.MethodPatch, urlPath: "/api/v1/posts/like/2", wantCode: http.StatusNotFound},
+		{name: "Shown to a liker", userID: 2, method: http.MethodGet, urlPath: "/api/v1/post/1", wantCode: http.StatusOK, wantBody: []string{`"likesCount": 2`, `"likedByMe": true`}},
+		{name: "Shown to another user", userID: 3, method: http.MethodGet, urlPath: "/api/v1/post/1", wantCode: http.StatusOK, wantBody: []string{`"likedByMe": false`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, tt.userID, data.RoleReader)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, tt.method, tt.urlPath, data.GenerateTestToken(), "")
+
+			assert.Equal(t, code, tt.wantCode)
+
+			for _, want := range tt.wantBody {
+				assert.StringContains(t, body, want)
+			}
 		})
 	}
 }
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+)
+
+// Public profile of the user in the id param. Emails are never part of a profile.
+func (app *application) showUserProfileHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	profile, err := app.models.Users.GetProfile(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"profile": profileResponseBody(profile)}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) updateProfileHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	profile, err := app.models.Users.GetProfile(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	var input dto.UpdateProfileRequestBody
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.DisplayName != nil {
+		profile.DisplayName = strings.TrimSpace(*input.DisplayName)
+	}
+	if input.Bio != nil {
+		profile.Bio = strings.TrimSpace(*input.Bio)
+	}
+	if input.AvatarURL != nil {
+		profile.AvatarURL = strings.TrimSpace(*input.AvatarURL)
+	}
+	if input.Website != nil {
+		profile.Website = strings.TrimSpace(*input.Website)
+	}
+	if input.SocialLinks != nil {
+		profile.SocialLinks = map[string]string{}
+		for platform, link := range *input.SocialLinks {
+			profile.SocialLinks[strings.ToLower(strings.TrimSpace(platform))] = strings.TrimSpace(link)
+		}
+	}
+
+	v := validator.New()
+
+	if data.ValidateProfile(v, profile); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Users.UpdateProfile(profile)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"profile": profileResponseBody(profile)}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func profileResponseBody(profile *data.Profile) dto.ProfileResponseBody {
+	return dto.ProfileResponseBody{
+		ID:          profile.UserID,
+		Name:        profile.Name,
+		DisplayName: profile.DisplayName,
+		Bio:         profile.Bio,
+		AvatarURL:   profile.AvatarURL,
+		Website:     profile.Website,
+		SocialLinks: profile.SocialLinks,
+		Stats: dto.ProfileStatsResponseBody{
+			Posts:         profile.PostsCount,
+			LikesReceived: profile.LikesReceived,
+			Followers:     profile.Followers,
+			Following:     profile.Following,
+		},
+		CreatedAt: profile.CreatedAt,
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+)
+
+func TestShowUserProfileHandler(t *testing.T) {
+	// Users 1 to 4 have a profile. User 1 wrote the mocked post.
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Profile", urlPath: "/api/v1/users/1", wantCode: http.StatusOK, wantBody: `"bio": "Mocked bio"`},
+		{name: "Stats", urlPath: "/api/v1/users/1", wantCode: http.StatusOK, wantBody: `"likesReceived": 2`},
+		{name: "Social links", urlPath: "/api/v1/users/1", wantCode: http.StatusOK, wantBody: `"github": "https://github.com/mocked"`},
+		{name: "Non-existent user", urlPath: "/api/v1/users/99", wantCode: http.StatusNotFound},
+		{name: "Invalid ID", urlPath: "/api/v1/users/abc", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.get(t, tt.urlPath)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+
+	t.Run("Email is never shown", func(t *testing.T) {
+		app := newTestApplication(t)
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		_, _, body := ts.get(t, "/api/v1/users/1")
+
+		assert.Equal(t, strings.Contains(strings.ToLower(body), "email"), false)
+	})
+}
+
+func TestUpdateProfileHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Update bio", body: `{"bio": "  New bio  "}`, wantCode: http.StatusOK, wantBody: `"bio": "New bio"`},
+		{name: "Keep unchanged fields", body: `{"displayName": "Mocked"}`, wantCode: http.StatusOK, wantBody: `"github": "https://github.com/mocked"`},
+		{name: "Replace social links", body: `{"socialLinks": {"Mastodon": "https://mastodon.social/@mocked"}}`, wantCode: http.StatusOK, wantBody: `"mastodon": "https://mastodon.social/@mocked"`},
+		{name: "Invalid avatar URL", body: `{"avatarUrl": "javascript:alert(1)"}`, wantCode: http.StatusUnprocessableEntity, wantBody: `"avatarUrl": "must be a valid http or https URL"`},
+		{name: "Invalid website", body: `{"website": "example.com"}`, wantCode: http.StatusUnprocessableEntity, wantBody: `"website"`},
+		{name: "Unknown social platform", body: `{"socialLinks": {"myspace": "https://myspace.com/mocked"}}`, wantCode: http.StatusUnprocessableEntity, wantBody: `"socialLinks.myspace"`},
+		{name: "Bio too long", body: `{"bio": "` + strings.Repeat("a", 1001) + `"}`, wantCode: http.StatusUnprocessableEntity, wantBody: `"bio"`},
+		{name: "Email cannot be changed", body: `{"email": "new@example.com"}`, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, 1, data.RoleReader)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodPatch, "/api/v1/users/me", data.GenerateTestToken(), tt.body)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+
+	t.Run("Anonymous user", func(t *testing.T) {
+		app := newTestApplication(t)
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		code, _, _ := ts.do(t, http.MethodPatch, "/api/v1/users/me", "", `{"bio": "New bio"}`)
+
+		assert.Equal(t, code, http.StatusUnauthorized)
+	})
+}
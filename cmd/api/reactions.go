@@ -0,0 +1,225 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+	"github.com/julienschmidt/httprouter"
+)
+
+func (app *application) showPostReactionsHandler(w http.ResponseWriter, r *http.Request) {
+	app.showReactions(w, r, data.ReactionTargetPost)
+}
+
+func (app *application) addPostReactionHandler(w http.ResponseWriter, r *http.Request) {
+	app.changeReaction(w, r, data.ReactionTargetPost, true)
+}
+
+func (app *application) removePostReactionHandler(w http.ResponseWriter, r *http.Request) {
+	app.changeReaction(w, r, data.ReactionTargetPost, false)
+}
+
+func (app *application) showCommentReactionsHandler(w http.ResponseWriter, r *http.Request) {
+	app.showReactions(w, r, data.ReactionTargetComment)
+}
+
+func (app *application) addCommentReactionHandler(w http.ResponseWriter, r *http.Request) {
+	app.changeReaction(w, r, data.ReactionTargetComment, true)
+}
+
+func (app *application) removeCommentReactionHandler(w http.ResponseWriter, r *http.Request) {
+	app.changeReaction(w, r, data.ReactionTargetComment, false)
+}
+
+// List who reacted to a post or comment with what, optionally only those who reacted with one emoji.
+func (app *application) showReactions(w http.ResponseWriter, r *http.Request, targetType string) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Emoji string
+		data.Filters
+	}
+
+	v := validator.New()
+
+	queryString := r.URL.Query()
+
+	input.Emoji = app.readString(queryString, "emoji", "")
+	input.Filters.Page = app.readInt(queryString, "page", 1, v)
+	input.Filters.Limit = app.readInt(queryString, "limit", 20, v)
+
+	// Newest reactions are always listed first.
+	input.Filters.Sort = "-id"
+	input.Filters.SortSafeList = []string{"-id"}
+
+	if input.Emoji != "" {
+		data.ValidateReaction(v, input.Emoji, app.config.reactions.allowed)
+	}
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.checkReactionTarget(targetType, id, app.contextGetUser(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	reactions, metadata, err := app.models.Reactions.GetAllForTarget(targetType, id, input.Emoji, input.Filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"reactions": reactions, "metadata": metadata}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Add or remove one of the user's reactions and respond with the target's updated reaction counts.
+func (app *application) changeReaction(w http.ResponseWriter, r *http.Request, targetType string, add bool) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	emoji := httprouter.ParamsFromContext(r.Context()).ByName("emoji")
+
+	v := validator.New()
+
+	if data.ValidateReaction(v, emoji, app.config.reactions.allowed); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	err = app.checkReactionTarget(targetType, id, user)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if add {
+		err = app.models.Reactions.Insert(targetType, id, user.ID, emoji)
+	} else {
+		err = app.models.Reactions.Delete(targetType, id, user.ID, emoji)
+	}
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	summaries, err := app.models.Reactions.GetSummaries(targetType, []int64{id}, user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"reactions": summaries[id]}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Posts and comments the user can't see, including comments on such posts, and deleted comments can't be reacted to
+// and their reactions aren't listed.
+func (app *application) checkReactionTarget(targetType string, id int64, user *data.User) error {
+	switch targetType {
+	case data.ReactionTargetPost:
+		post, err := app.models.Posts.Get(id)
+		if err != nil {
+			return err
+		}
+
+		if !post.VisibleTo(user) {
+			return data.ErrRecordNotFound
+		}
+	default:
+		comment, err := app.models.Comments.Get(id)
+		if err != nil {
+			return err
+		}
+
+		visible, err := app.commentVisibleTo(comment, user)
+		if err != nil {
+			return err
+		}
+
+		if !visible {
+			return data.ErrRecordNotFound
+		}
+	}
+
+	return nil
+}
+
+// Fill in the reaction counts of posts, and the reactions of the user reading them.
+func (app *application) attachPostReactions(posts []*dto.PostResponseBody, user *data.User) error {
+	ids := make([]int64, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+
+	summaries, err := app.models.Reactions.GetSummaries(data.ReactionTargetPost, ids, user.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		post.Reactions = summaries[post.ID]
+	}
+
+	return nil
+}
+
+// Fill in the reaction counts of comments and all their replies, and the reactions of the user reading them.
+// Tombstones of deleted comments are left without reactions.
+func (app *application) attachCommentReactions(comments []*dto.CommentResponseBody, user *data.User) error {
+	ids := []int64{}
+	walkComments(comments, func(comment *dto.CommentResponseBody) {
+		ids = append(ids, comment.ID)
+	})
+
+	summaries, err := app.models.Reactions.GetSummaries(data.ReactionTargetComment, ids, user.ID)
+	if err != nil {
+		return err
+	}
+
+	walkComments(comments, func(comment *dto.CommentResponseBody) {
+		if !comment.Deleted {
+			comment.Reactions = summaries[comment.ID]
+		}
+	})
+
+	return nil
+}
+
+// Call fn for every comment of the threads, parents before their replies.
+func walkComments(comments []*dto.CommentResponseBody, fn func(comment *dto.CommentResponseBody)) {
+	for _, comment := range comments {
+		fn(comment)
+		walkComments(comment.Replies, fn)
+	}
+}
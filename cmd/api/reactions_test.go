@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+)
+
+func TestChangeReactionHandler(t *testing.T) {
+	// Users 1 and 2 reacted to mocked post 1 with a thumbs up. Post 3 is a draft of user 1, comment 7 is on
+	// it and comment 4 is deleted.
+	tests := []struct {
+		name     string
+		method   string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{name: "React to post", method: http.MethodPut, urlPath: "/api/v1/post/1/reactions/👍", wantCode: http.StatusOK, wantBody: `"mine": [`},
+		{name: "Remove post reaction", method: http.MethodDelete, urlPath: "/api/v1/post/1/reactions/🎉", wantCode: http.StatusOK, wantBody: `"👍": 2`},
+		{name: "React to comment", method: http.MethodPut, urlPath: "/api/v1/posts/comment/1/reactions/❤️", wantCode: http.StatusOK, wantBody: `"❤️": 1`},
+		{name: "Emoji not allowed", method: http.MethodPut, urlPath: "/api/v1/post/1/reactions/😀", wantCode: http.StatusUnprocessableEntity, wantBody: "must be one of"},
+		{name: "Draft of another user", method: http.MethodPut, urlPath: "/api/v1/post/3/reactions/👍", wantCode: http.StatusNotFound},
+		{name: "Deleted comment", method: http.MethodPut, urlPath: "/api/v1/posts/comment/4/reactions/👍", wantCode: http.StatusNotFound},
+		{name: "Comment on draft of another user", method: http.MethodPut, urlPath: "/api/v1/posts/comment/7/reactions/👍", wantCode: http.StatusNotFound},
+		{name: "Non-existent post", method: http.MethodPut, urlPath: "/api/v1/post/2/reactions/👍", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, 2, data.RoleReader)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, tt.method, tt.urlPath, data.GenerateTestToken(), "")
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+
+	t.Run("Anonymous user", func(t *testing.T) {
+		app := newTestApplication(t)
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		code, _, _ := ts.do(t, http.MethodPut, "/api/v1/post/1/reactions/👍", "", "")
+
+		assert.Equal(t, code, http.StatusUnauthorized)
+	})
+}
+
+func TestShowReactionsHandler(t *testing.T) {
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Post reactions", urlPath: "/api/v1/post/1/reactions", wantCode: http.StatusOK, wantBody: "Another User"},
+		{name: "Comment reactions", urlPath: "/api/v1/posts/comment/1/reactions", wantCode: http.StatusOK, wantBody: `"emoji": "❤️"`},
+		{name: "Filtered by emoji", urlPath: "/api/v1/post/1/reactions?emoji=" + url.QueryEscape("❤️"), wantCode: http.StatusOK, wantBody: `"reactions": []`},
+		{name: "Invalid emoji filter", urlPath: "/api/v1/post/1/reactions?emoji=x", wantCode: http.StatusUnprocessableEntity},
+		{name: "Draft", urlPath: "/api/v1/post/3/reactions", wantCode: http.StatusNotFound},
+		{name: "Counts in post listing", urlPath: "/api/v1/posts", wantCode: http.StatusOK, wantBody: `"👍": 2`},
+		{name: "Counts in single post", urlPath: "/api/v1/post/1", wantCode: http.StatusOK, wantBody: `"👍": 2`},
+		{name: "Counts in comment tree", urlPath: "/api/v1/posts/comments/1?view=tree", wantCode: http.StatusOK, wantBody: `"❤️": 1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.get(t, tt.urlPath)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
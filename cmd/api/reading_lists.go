@@ -0,0 +1,445 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+)
+
+func (app *application) createReadingListHandler(w http.ResponseWriter, r *http.Request) {
+	var input dto.CreateReadingListRequestBody
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	list := &data.ReadingList{
+		UserID:      user.ID,
+		Name:        strings.TrimSpace(input.Name),
+		Description: strings.TrimSpace(input.Description),
+		Public:      input.Public,
+	}
+
+	v := validator.New()
+
+	if data.ValidateReadingList(v, list); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.ReadingLists.Insert(list)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateListName):
+			v.AddError("name", "You already have a reading list with this name")
+			app.validationFailedResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"list": readingListResponseBody(list)}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// List the current user's reading lists, private ones included.
+func (app *application) showReadingListsHandler(w http.ResponseWriter, r *http.Request) {
+	app.showReadingLists(w, r, app.contextGetUser(r).ID)
+}
+
+// List the reading lists of any user. Others only get to see the public ones.
+func (app *application) showUserReadingListsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	app.showReadingLists(w, r, id)
+}
+
+func (app *application) showReadingLists(w http.ResponseWriter, r *http.Request, userID int64) {
+	var filters data.Filters
+
+	v := validator.New()
+
+	queryString := r.URL.Query()
+
+	filters.Page = app.readInt(queryString, "page", 1, v)
+	filters.Limit = app.readInt(queryString, "limit", 20, v)
+
+	// Lists are always ordered by name.
+	filters.Sort = "name"
+	filters.SortSafeList = []string{"name"}
+
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	lists, metadata, err := app.models.ReadingLists.GetAllForUser(userID, user.ReaderID(data.PermissionPostsRead), user.ID != userID, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"lists": lists, "metadata": metadata}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Show a reading list along with a page of its items in order.
+func (app *application) showReadingListHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var filters data.Filters
+
+	v := validator.New()
+
+	queryString := r.URL.Query()
+
+	filters.Page = app.readInt(queryString, "page", 1, v)
+	filters.Limit = app.readInt(queryString, "limit", 20, v)
+
+	filters.Sort = "position"
+	filters.SortSafeList = []string{"position"}
+
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	list, err := app.models.ReadingLists.Get(id, user.ReaderID(data.PermissionPostsRead))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !list.VisibleTo(user) {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	items, metadata, err := app.models.ReadingLists.GetItems(list.ID, user.ReaderID(data.PermissionPostsRead), filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"list": readingListResponseBody(list), "items": items, "metadata": metadata}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) updateReadingListHandler(w http.ResponseWriter, r *http.Request) {
+	list, ok := app.getOwnReadingList(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.UpdateReadingListRequestBody
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Name != nil {
+		list.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Description != nil {
+		list.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.Public != nil {
+		list.Public = *input.Public
+	}
+
+	v := validator.New()
+
+	if data.ValidateReadingList(v, list); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.ReadingLists.Update(list)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		case errors.Is(err, data.ErrDuplicateListName):
+			v.AddError("name", "You already have a reading list with this name")
+			app.validationFailedResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"list": readingListResponseBody(list)}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteReadingListHandler(w http.ResponseWriter, r *http.Request) {
+	list, ok := app.getOwnReadingList(w, r)
+	if !ok {
+		return
+	}
+
+	err := app.models.ReadingLists.Delete(list.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "reading list deleted successfully"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Add a post the user can see to the end of one of their lists.
+func (app *application) addReadingListItemHandler(w http.ResponseWriter, r *http.Request) {
+	list, ok := app.getOwnReadingList(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.AddReadingListItemRequestBody
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	item := &data.ReadingListItem{
+		ListID: list.ID,
+		PostID: input.PostID,
+		Note:   strings.TrimSpace(input.Note),
+	}
+
+	v := validator.New()
+
+	if data.ValidateReadingListItem(v, item); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	post, err := app.models.Posts.Get(item.PostID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("post", "Post does not exist")
+			app.validationFailedResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !post.VisibleTo(app.contextGetUser(r)) {
+		v.AddError("post", "Post does not exist")
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.ReadingLists.AddItem(item)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateListItem):
+			v.AddError("post", "Post is already in this reading list")
+			app.validationFailedResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"item": readingListItemResponseBody(item)}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Change the note of an item or move it to another position.
+func (app *application) updateReadingListItemHandler(w http.ResponseWriter, r *http.Request) {
+	list, ok := app.getOwnReadingList(w, r)
+	if !ok {
+		return
+	}
+
+	postID, err := app.readPostParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	item, err := app.models.ReadingLists.GetItem(list.ID, postID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	var input dto.UpdateReadingListItemRequestBody
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Note != nil {
+		item.Note = strings.TrimSpace(*input.Note)
+	}
+	if input.Position != nil {
+		item.Position = *input.Position
+	}
+
+	v := validator.New()
+
+	v.Check(item.Position > 0, "position", "must be greater than zero")
+
+	if data.ValidateReadingListItem(v, item); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.ReadingLists.UpdateItem(item)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"item": readingListItemResponseBody(item)}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) removeReadingListItemHandler(w http.ResponseWriter, r *http.Request) {
+	list, ok := app.getOwnReadingList(w, r)
+	if !ok {
+		return
+	}
+
+	postID, err := app.readPostParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.models.ReadingLists.RemoveItem(list.ID, postID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "post removed from reading list successfully"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Get the reading list from the id param, making sure it belongs to the current user. Private lists of other users
+// are reported as not found. It writes the error response itself and returns false when the handler should stop.
+func (app *application) getOwnReadingList(w http.ResponseWriter, r *http.Request) (*data.ReadingList, bool) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	user := app.contextGetUser(r)
+
+	list, err := app.models.ReadingLists.Get(id, user.ReaderID(data.PermissionPostsRead))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, false
+	}
+
+	switch {
+	case !list.VisibleTo(user):
+		app.notFoundResponse(w, r)
+		return nil, false
+	case list.UserID != user.ID:
+		app.notPermittedResponse(w, r)
+		return nil, false
+	}
+
+	return list, true
+}
+
+func readingListResponseBody(list *data.ReadingList) dto.ReadingListResponseBody {
+	return dto.ReadingListResponseBody{
+		ID:          list.ID,
+		UserID:      list.UserID,
+		Name:        list.Name,
+		Description: list.Description,
+		Public:      list.Public,
+		ItemsCount:  list.ItemsCount,
+		CreatedAt:   list.CreatedAt,
+	}
+}
+
+// Responses to changes of an item don't carry the post, the client already has it.
+func readingListItemResponseBody(item *data.ReadingListItem) dto.ReadingListItemResponseBody {
+	return dto.ReadingListItemResponseBody{
+		Position: item.Position,
+		Note:     item.Note,
+		AddedAt:  item.AddedAt,
+	}
+}
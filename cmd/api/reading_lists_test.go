@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+)
+
+func TestReadingListHandlers(t *testing.T) {
+	// User 1 owns public list 1, which holds mocked post 1, and private list 2. Post 3 is a draft of user 1.
+	tests := []struct {
+		name     string
+		userID   int64
+		method   string
+		urlPath  string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Create", userID: 1, method: http.MethodPost, urlPath: "/api/v1/lists", body: `{"name": "Later", "public": true}`, wantCode: http.StatusCreated, wantBody: `"name": "Later"`},
+		{name: "Create with taken name", userID: 1, method: http.MethodPost, urlPath: "/api/v1/lists", body: `{"name": "Mocked Reading List"}`, wantCode: http.StatusUnprocessableEntity, wantBody: "You already have a reading list with this name"},
+		{name: "Create without name", userID: 1, method: http.MethodPost, urlPath: "/api/v1/lists", body: `{"name": " "}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "Own lists", userID: 1, method: http.MethodGet, urlPath: "/api/v1/lists", wantCode: http.StatusOK, wantBody: "Mocked Private Reading List"},
+		{name: "Own private list", userID: 1, method: http.MethodGet, urlPath: "/api/v1/lists/2", wantCode: http.StatusOK},
+		{name: "Private list of another user", userID: 2, method: http.MethodGet, urlPath: "/api/v1/lists/2", wantCode: http.StatusNotFound},
+		{name: "Public list of another user", userID: 2, method: http.MethodGet, urlPath: "/api/v1/lists/1", wantCode: http.StatusOK, wantBody: "Mocked Note"},
+		{name: "Rename", userID: 1, method: http.MethodPatch, urlPath: "/api/v1/lists/1", body: `{"name": "Renamed"}`, wantCode: http.StatusOK, wantBody: `"name": "Renamed"`},
+		{name: "Rename to taken name", userID: 1, method: http.MethodPatch, urlPath: "/api/v1/lists/2", body: `{"name": "Mocked Reading List"}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "Rename list of another user", userID: 2, method: http.MethodPatch, urlPath: "/api/v1/lists/1", body: `{"name": "Renamed"}`, wantCode: http.StatusForbidden},
+		{name: "Delete", userID: 1, method: http.MethodDelete, urlPath: "/api/v1/lists/2", wantCode: http.StatusOK},
+		{name: "Delete private list of another user", userID: 2, method: http.MethodDelete, urlPath: "/api/v1/lists/2", wantCode: http.StatusNotFound},
+		{name: "Add own draft", userID: 1, method: http.MethodPost, urlPath: "/api/v1/lists/1/items", body: `{"post": 3, "note": "Proofread"}`, wantCode: http.StatusCreated, wantBody: `"position": 2`},
+		{name: "Add post twice", userID: 1, method: http.MethodPost, urlPath: "/api/v1/lists/1/items", body: `{"post": 1}`, wantCode: http.StatusUnprocessableEntity, wantBody: "Post is already in this reading list"},
+		{name: "Add non-existent post", userID: 1, method: http.MethodPost, urlPath: "/api/v1/lists/1/items", body: `{"post": 2}`, wantCode: http.StatusUnprocessableEntity, wantBody: "Post does not exist"},
+		{name: "Add to list of another user", userID: 2, method: http.MethodPost, urlPath: "/api/v1/lists/1/items", body: `{"post": 1}`, wantCode: http.StatusForbidden},
+		{name: "Move past the end", userID: 1, method: http.MethodPatch, urlPath: "/api/v1/lists/1/items/1", body: `{"position": 5, "note": "First"}`, wantCode: http.StatusOK, wantBody: `"position": 1`},
+		{name: "Move before the start", userID: 1, method: http.MethodPatch, urlPath: "/api/v1/lists/1/items/1", body: `{"position": 0}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "Update missing item", userID: 1, method: http.MethodPatch, urlPath: "/api/v1/lists/1/items/3", body: `{"note": "x"}`, wantCode: http.StatusNotFound},
+		{name: "Remove item", userID: 1, method: http.MethodDelete, urlPath: "/api/v1/lists/1/items/1", wantCode: http.StatusOK},
+		{name: "Remove missing item", userID: 1, method: http.MethodDelete, urlPath: "/api/v1/lists/1/items/3", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, tt.userID, data.RoleReader)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, tt.method, tt.urlPath, data.GenerateTestToken(), tt.body)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
+
+func TestShowUserReadingListsHandler(t *testing.T) {
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, _, body := ts.get(t, "/api/v1/users/1/lists")
+
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "Mocked Reading List")
+	assert.Equal(t, strings.Contains(body, "Mocked Private Reading List"), false)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+)
+
+func TestRefreshAuthenticationTokenHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		refreshErr error
+		token      string
+		wantCode   int
+		wantBody   string
+	}{
+		{name: "Valid refresh token", token: data.GenerateTestToken(), wantCode: http.StatusCreated, wantBody: `"refresh_token"`},
+		{name: "Expired refresh token", refreshErr: data.ErrRecordNotFound, token: data.GenerateTestToken(), wantCode: http.StatusUnauthorized, wantBody: "refresh token is invalid or expired"},
+		{name: "Reused refresh token", refreshErr: data.ErrRefreshTokenReused, token: data.GenerateTestToken(), wantCode: http.StatusUnauthorized, wantBody: "refresh token is invalid or expired"},
+		{name: "Malformed refresh token", token: "abc", wantCode: http.StatusUnprocessableEntity, wantBody: "must be 26 bytes long"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+
+			app.models.Tokens = data.MockTokenModel{
+				MockRefresh: func(refreshPlainText string, accessTTL, refreshTTL time.Duration) (*data.Token, *data.Token, error) {
+					assert.Equal(t, refreshPlainText, tt.token)
+					assert.Equal(t, accessTTL, app.config.tokens.accessTTL)
+					assert.Equal(t, refreshTTL, app.config.tokens.refreshTTL)
+
+					if tt.refreshErr != nil {
+						return nil, nil, tt.refreshErr
+					}
+
+					access := &data.Token{UserID: 1, Expiry: time.Now().Add(accessTTL), Scope: data.ScopeAuthentication}
+					refresh := &data.Token{UserID: 1, Expiry: time.Now().Add(refreshTTL), Scope: data.ScopeRefresh}
+
+					return access, refresh, nil
+				},
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodPost, "/api/v1/auth/refresh", "", `{"refreshToken": "`+tt.token+`"}`)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+
+	t.Run("Signed access token", func(t *testing.T) {
+		app := newTestApplication(t)
+		useSignedTokens(t, app)
+
+		app.models.Tokens = data.MockTokenModel{
+			MockRefresh: func(refreshPlainText string, accessTTL, refreshTTL time.Duration) (*data.Token, *data.Token, error) {
+				access := &data.Token{Plaintext: "opaque", UserID: 1, Expiry: time.Now().Add(accessTTL), Scope: data.ScopeAuthentication, Family: 3}
+				refresh := &data.Token{UserID: 1, Expiry: time.Now().Add(refreshTTL), Scope: data.ScopeRefresh, Family: 3}
+
+				return access, refresh, nil
+			},
+		}
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		code, _, body := ts.do(t, http.MethodPost, "/api/v1/auth/refresh", "", `{"refreshToken": "`+data.GenerateTestToken()+`"}`)
+		assert.Equal(t, code, http.StatusCreated)
+
+		var response struct {
+			Token struct {
+				Plaintext string `json:"token"`
+			} `json:"authentication_token"`
+		}
+
+		err := json.Unmarshal([]byte(body), &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		claims, err := app.config.tokens.keys.Verify(response.Token.Plaintext, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, claims.UserID, int64(1))
+		assert.Equal(t, claims.Session, int64(3))
+	})
+}
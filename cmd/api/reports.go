@@ -0,0 +1,259 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+)
+
+// Report a post or comment. Each user can report the same content once, content with too many open reports is hidden
+// until a moderator reviews it.
+func (app *application) createReportHandler(w http.ResponseWriter, r *http.Request) {
+	var input dto.CreateReportRequestBody
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	report := &data.Report{
+		TargetType: input.TargetType,
+		TargetID:   input.TargetID,
+		ReporterID: user.ID,
+		Reason:     input.Reason,
+		Details:    strings.TrimSpace(input.Details),
+	}
+
+	v := validator.New()
+
+	if data.ValidateReport(v, report); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	ownerID, err := app.reportTargetOwner(report, user)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("targetId", "Reported content does not exist")
+			app.validationFailedResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if ownerID == user.ID {
+		v.AddError("targetId", "You cannot report your own content")
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	hidden, err := app.models.Reports.Insert(report, app.config.reports.hideThreshold)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateReport):
+			v.AddError("targetId", "You have already reported this content")
+			app.validationFailedResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if hidden {
+		app.logger.PrintInfo("content hidden by reports", map[string]string{
+			"target_type": report.TargetType,
+			"target_id":   strconv.FormatInt(report.TargetID, 10),
+		})
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"report": reportResponseBody(report)}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// List reports, oldest first. Only users with the content:moderate permission reach this handler.
+func (app *application) showReportsHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Status     string
+		TargetType string
+		data.Filters
+	}
+
+	v := validator.New()
+
+	queryString := r.URL.Query()
+
+	input.Status = app.readString(queryString, "status", data.ReportStatusOpen)
+	input.TargetType = app.readString(queryString, "target_type", "")
+	input.Filters.Page = app.readInt(queryString, "page", 1, v)
+	input.Filters.Limit = app.readInt(queryString, "limit", 20, v)
+
+	input.Filters.Sort = "id"
+	input.Filters.SortSafeList = []string{"id"}
+
+	data.ValidateReportFilters(v, input.Status, input.TargetType)
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	reports, metadata, err := app.models.Reports.GetAll(input.Status, input.TargetType, input.Filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"reports": reports, "metadata": metadata}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Show a report along with the audit trail of the reported content.
+// Only users with the content:moderate permission reach this handler.
+func (app *application) showReportHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	report, err := app.models.Reports.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	actions, err := app.models.Reports.GetActions(report.TargetType, report.TargetID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"report": reportResponseBody(report), "actions": actions}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Hide the reported content or dismiss the reports, closing every open report of the same content.
+// Only users with the content:moderate permission reach this handler.
+func (app *application) resolveReportHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input dto.ResolveReportRequestBody
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	input.Note = strings.TrimSpace(input.Note)
+
+	v := validator.New()
+
+	if data.ValidateReportAction(v, input.Action, input.Note); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	report, err := app.models.Reports.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	err = app.models.Reports.Resolve(report, input.Action, user.ID, input.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrReportResolved):
+			app.reportResolvedResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"report": reportResponseBody(report)}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Creator of the reported content. Content the user can't see, including comments on posts they can't see, is
+// reported as not found.
+func (app *application) reportTargetOwner(report *data.Report, user *data.User) (int64, error) {
+	switch report.TargetType {
+	case data.ReportTargetPost:
+		post, err := app.models.Posts.Get(report.TargetID)
+		if err != nil {
+			return 0, err
+		}
+
+		if !post.VisibleTo(user) {
+			return 0, data.ErrRecordNotFound
+		}
+
+		return post.CreatedBy, nil
+	default:
+		comment, err := app.models.Comments.Get(report.TargetID)
+		if err != nil {
+			return 0, err
+		}
+
+		visible, err := app.commentVisibleTo(comment, user)
+		if err != nil {
+			return 0, err
+		}
+
+		if !visible {
+			return 0, data.ErrRecordNotFound
+		}
+
+		return comment.CreatedBy, nil
+	}
+}
+
+func reportResponseBody(report *data.Report) dto.ReportResponseBody {
+	return dto.ReportResponseBody{
+		ID:         report.ID,
+		TargetType: report.TargetType,
+		TargetID:   report.TargetID,
+		ReporterID: report.ReporterID,
+		Reason:     report.Reason,
+		Details:    report.Details,
+		Status:     report.Status,
+		CreatedAt:  report.CreatedAt,
+		ResolvedBy: report.ResolvedBy,
+		ResolvedAt: report.ResolvedAt,
+	}
+}
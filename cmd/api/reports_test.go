@@ -0,0 +1,98 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+)
+
+func TestCreateReportHandler(t *testing.T) {
+	// Mocked post 1 and comment 1 are created by user 1, user 2 has already reported post 1.
+	tests := []struct {
+		name     string
+		userID   int64
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Post", userID: 3, body: `{"targetType": "post", "targetId": 1, "reason": "spam"}`, wantCode: http.StatusCreated, wantBody: `"status": "open"`},
+		{name: "Comment", userID: 3, body: `{"targetType": "comment", "targetId": 1, "reason": "harassment", "details": "Rude"}`, wantCode: http.StatusCreated, wantBody: `"details": "Rude"`},
+		{name: "Duplicate report", userID: 2, body: `{"targetType": "post", "targetId": 1, "reason": "spam"}`, wantCode: http.StatusUnprocessableEntity, wantBody: "You have already reported this content"},
+		{name: "Own post", userID: 1, body: `{"targetType": "post", "targetId": 1, "reason": "spam"}`, wantCode: http.StatusUnprocessableEntity, wantBody: "You cannot report your own content"},
+		{name: "Non-existent post", userID: 3, body: `{"targetType": "post", "targetId": 2, "reason": "spam"}`, wantCode: http.StatusUnprocessableEntity, wantBody: "Reported content does not exist"},
+		{name: "Draft of another user", userID: 3, body: `{"targetType": "post", "targetId": 3, "reason": "spam"}`, wantCode: http.StatusUnprocessableEntity, wantBody: "Reported content does not exist"},
+		{name: "Pending comment of another user", userID: 3, body: `{"targetType": "comment", "targetId": 6, "reason": "spam"}`, wantCode: http.StatusUnprocessableEntity, wantBody: "Reported content does not exist"},
+		{name: "Deleted comment", userID: 3, body: `{"targetType": "comment", "targetId": 4, "reason": "spam"}`, wantCode: http.StatusUnprocessableEntity, wantBody: "Reported content does not exist"},
+		{name: "Comment on draft of another user", userID: 3, body: `{"targetType": "comment", "targetId": 7, "reason": "spam"}`, wantCode: http.StatusUnprocessableEntity, wantBody: "Reported content does not exist"},
+		{name: "Unknown reason", userID: 3, body: `{"targetType": "post", "targetId": 1, "reason": "boring"}`, wantCode: http.StatusUnprocessableEntity, wantBody: `"reason"`},
+		{name: "Unknown target type", userID: 3, body: `{"targetType": "user", "targetId": 1, "reason": "spam"}`, wantCode: http.StatusUnprocessableEntity, wantBody: `"targetType"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, tt.userID, data.RoleReader)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodPost, "/api/v1/reports", data.GenerateTestToken(), tt.body)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+
+	t.Run("Anonymous user", func(t *testing.T) {
+		app := newTestApplication(t)
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		code, _, _ := ts.do(t, http.MethodPost, "/api/v1/reports", "", `{"targetType": "post", "targetId": 1, "reason": "spam"}`)
+
+		assert.Equal(t, code, http.StatusUnauthorized)
+	})
+}
+
+func TestReportModerationHandlers(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		method   string
+		urlPath  string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "List as reader", role: data.RoleReader, method: http.MethodGet, urlPath: "/api/v1/reports", wantCode: http.StatusForbidden},
+		{name: "List open reports", role: data.RoleEditor, method: http.MethodGet, urlPath: "/api/v1/reports", wantCode: http.StatusOK, wantBody: "Mocked Report Details"},
+		{name: "List comment reports", role: data.RoleEditor, method: http.MethodGet, urlPath: "/api/v1/reports?target_type=comment", wantCode: http.StatusOK, wantBody: `"reports": []`},
+		{name: "List invalid status", role: data.RoleEditor, method: http.MethodGet, urlPath: "/api/v1/reports?status=closed", wantCode: http.StatusUnprocessableEntity},
+		{name: "Show with audit trail", role: data.RoleEditor, method: http.MethodGet, urlPath: "/api/v1/reports/1", wantCode: http.StatusOK, wantBody: `"action": "auto_hidden"`},
+		{name: "Show non-existent ID", role: data.RoleEditor, method: http.MethodGet, urlPath: "/api/v1/reports/2", wantCode: http.StatusNotFound},
+		{name: "Hide", role: data.RoleEditor, method: http.MethodPost, urlPath: "/api/v1/reports/1/resolve", body: `{"action": "hide"}`, wantCode: http.StatusOK, wantBody: `"status": "resolved"`},
+		{name: "Dismiss", role: data.RoleAdmin, method: http.MethodPost, urlPath: "/api/v1/reports/1/resolve", body: `{"action": "dismiss", "note": "Fine"}`, wantCode: http.StatusOK, wantBody: `"status": "dismissed"`},
+		{name: "Unknown action", role: data.RoleEditor, method: http.MethodPost, urlPath: "/api/v1/reports/1/resolve", body: `{"action": "delete"}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "Resolve as author", role: data.RoleAuthor, method: http.MethodPost, urlPath: "/api/v1/reports/1/resolve", body: `{"action": "hide"}`, wantCode: http.StatusForbidden},
+		{name: "Resolve non-existent ID", role: data.RoleEditor, method: http.MethodPost, urlPath: "/api/v1/reports/2/resolve", body: `{"action": "hide"}`, wantCode: http.StatusNotFound},
+		{name: "Resolve resolved report", role: data.RoleEditor, method: http.MethodPost, urlPath: "/api/v1/reports/3/resolve", body: `{"action": "dismiss"}`, wantCode: http.StatusConflict, wantBody: "the report has already been resolved"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, 4, tt.role)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, tt.method, tt.urlPath, data.GenerateTestToken(), tt.body)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// In-memory copy of the revoked sessions, so signed access tokens can be checked without a database lookup.
+// It's reloaded periodically, and sessions logged out through this server are added to it straight away.
+type revocationList struct {
+	mu       sync.RWMutex
+	sessions map[int64]time.Time
+	quit     chan struct{}
+}
+
+func newRevocationList() *revocationList {
+	return &revocationList{
+		sessions: make(map[int64]time.Time),
+		quit:     make(chan struct{}),
+	}
+}
+
+func (l *revocationList) revoked(family int64) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	_, ok := l.sessions[family]
+	return ok
+}
+
+func (l *revocationList) add(family int64, expiry time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if expiry.After(l.sessions[family]) {
+		l.sessions[family] = expiry
+	}
+}
+
+// Load the revoked sessions once, then keep reloading them in a background goroutine tracked by app.wg.
+func (app *application) startRevocationList() error {
+	app.revocations = newRevocationList()
+
+	err := app.reloadRevocations()
+	if err != nil {
+		return err
+	}
+
+	app.logger.PrintInfo("starting revocation list", map[string]string{
+		"interval": app.config.tokens.revocationInterval.String(),
+	})
+
+	app.background(func() {
+		ticker := time.NewTicker(app.config.tokens.revocationInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				// Recover panic so a single failed reload doesn't stop the reloads.
+				func() {
+					defer func() {
+						if err := recover(); err != nil {
+							app.logger.PrintError(fmt.Errorf("%s", err), nil)
+						}
+					}()
+
+					err := app.reloadRevocations()
+					if err != nil {
+						app.logger.PrintError(err, map[string]string{"task": "reload revoked sessions"})
+					}
+				}()
+			case <-app.revocations.quit:
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+// Signal the reloading goroutine to stop. It is a no-op when the list was never started.
+func (app *application) stopRevocationList() {
+	if app.revocations == nil {
+		return
+	}
+
+	close(app.revocations.quit)
+}
+
+// Replace the list with the revoked sessions in the database, dropping the expired ones there first.
+func (app *application) reloadRevocations() error {
+	err := app.models.RevokedSessions.DeleteExpired()
+	if err != nil {
+		return err
+	}
+
+	sessions, err := app.models.RevokedSessions.GetAll()
+	if err != nil {
+		return err
+	}
+
+	app.revocations.mu.Lock()
+	defer app.revocations.mu.Unlock()
+
+	// Keep sessions revoked through this server since the last reload, the database may not list them yet.
+	now := time.Now()
+	for family, expiry := range app.revocations.sessions {
+		if _, ok := sessions[family]; !ok && expiry.After(now) {
+			sessions[family] = expiry
+		}
+	}
+
+	app.revocations.sessions = sessions
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"expvar"
 	"net/http"
 
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
 	"github.com/julienschmidt/httprouter"
 )
 
@@ -23,21 +24,105 @@ func (app *application) routes() http.Handler {
 	// Post routes
 	router.HandlerFunc(http.MethodGet, "/api/v1/posts", app.showPostsHandler)
 	router.HandlerFunc(http.MethodGet, "/api/v1/post/:id", app.showSinglePostHandler)
-	router.HandlerFunc(http.MethodPost, "/api/v1/post", app.requireActivatedUser(app.createPostHandler))
-	router.HandlerFunc(http.MethodPatch, "/api/v1/post/:id", app.requireActivatedUser(app.updatePostHandler))
-	router.HandlerFunc(http.MethodDelete, "/api/v1/post/:id", app.requireActivatedUser(app.deletePostHandler))
+	// httprouter doesn't allow a static segment next to the :id wildcard, so slug lookups live under /posts.
+	router.HandlerFunc(http.MethodGet, "/api/v1/posts/by-slug/:slug", app.showPostBySlugHandler)
+	router.HandlerFunc(http.MethodPost, "/api/v1/post", app.requirePermission(data.PermissionPostsWrite, app.createPostHandler))
+	router.HandlerFunc(http.MethodPatch, "/api/v1/post/:id", app.requirePermission(data.PermissionPostsWrite, app.updatePostHandler))
+	router.HandlerFunc(http.MethodDelete, "/api/v1/post/:id", app.requirePermission(data.PermissionPostsWrite, app.deletePostHandler))
+	router.HandlerFunc(http.MethodPost, "/api/v1/post/:id/submit", app.requirePermission(data.PermissionPostsWrite, app.submitPostHandler))
+	router.HandlerFunc(http.MethodPost, "/api/v1/post/:id/publish", app.requirePermission(data.PermissionPostsPublish, app.publishPostHandler))
+	router.HandlerFunc(http.MethodPost, "/api/v1/post/:id/schedule", app.requirePermission(data.PermissionPostsPublish, app.schedulePostHandler))
+	router.HandlerFunc(http.MethodPost, "/api/v1/post/:id/archive", app.requirePermission(data.PermissionPostsWrite, app.archivePostHandler))
+	router.HandlerFunc(http.MethodPost, "/api/v1/post/:id/draft", app.requirePermission(data.PermissionPostsWrite, app.draftPostHandler))
+	router.HandlerFunc(http.MethodGet, "/api/v1/post/:id/history", app.requirePermission(data.PermissionPostsWrite, app.showPostStatusHistoryHandler))
+	router.HandlerFunc(http.MethodGet, "/api/v1/post/:id/revisions", app.requirePermission(data.PermissionPostsWrite, app.showPostRevisionsHandler))
+	router.HandlerFunc(http.MethodGet, "/api/v1/post/:id/revisions/:rev", app.requirePermission(data.PermissionPostsWrite, app.showPostRevisionHandler))
+	router.HandlerFunc(http.MethodPost, "/api/v1/post/:id/revisions/:rev/restore", app.requirePermission(data.PermissionPostsWrite, app.restorePostRevisionHandler))
+	router.HandlerFunc(http.MethodPut, "/api/v1/post/:id/moderation", app.requirePermission(data.PermissionPostsWrite, app.updatePostModerationHandler))
+	router.HandlerFunc(http.MethodGet, "/api/v1/post/:id/reactions", app.showPostReactionsHandler)
+	router.HandlerFunc(http.MethodPut, "/api/v1/post/:id/reactions/:emoji", app.requireAuthenticatedUser(app.addPostReactionHandler))
+	router.HandlerFunc(http.MethodDelete, "/api/v1/post/:id/reactions/:emoji", app.requireAuthenticatedUser(app.removePostReactionHandler))
 	router.HandlerFunc(http.MethodPatch, "/api/v1/posts/like/:id", app.requireAuthenticatedUser(app.likePostHandler))
 	router.HandlerFunc(http.MethodPatch, "/api/v1/posts/dislike/:id", app.requireAuthenticatedUser(app.dislikePostHandler))
 
+	// Bookmark routes
+	router.HandlerFunc(http.MethodPut, "/api/v1/post/:id/bookmark", app.requireAuthenticatedUser(app.bookmarkPostHandler))
+	router.HandlerFunc(http.MethodDelete, "/api/v1/post/:id/bookmark", app.requireAuthenticatedUser(app.unbookmarkPostHandler))
+	router.HandlerFunc(http.MethodGet, "/api/v1/bookmarks", app.requirePermission(data.PermissionPostsRead, app.showBookmarksHandler))
+
+	// Reading list routes
+	router.HandlerFunc(http.MethodGet, "/api/v1/lists", app.requirePermission(data.PermissionPostsRead, app.showReadingListsHandler))
+	router.HandlerFunc(http.MethodPost, "/api/v1/lists", app.requireActivatedUser(app.createReadingListHandler))
+	router.HandlerFunc(http.MethodGet, "/api/v1/lists/:id", app.showReadingListHandler)
+	router.HandlerFunc(http.MethodPatch, "/api/v1/lists/:id", app.requireActivatedUser(app.updateReadingListHandler))
+	router.HandlerFunc(http.MethodDelete, "/api/v1/lists/:id", app.requireActivatedUser(app.deleteReadingListHandler))
+	router.HandlerFunc(http.MethodPost, "/api/v1/lists/:id/items", app.requireActivatedUser(app.addReadingListItemHandler))
+	router.HandlerFunc(http.MethodPatch, "/api/v1/lists/:id/items/:post", app.requireActivatedUser(app.updateReadingListItemHandler))
+	router.HandlerFunc(http.MethodDelete, "/api/v1/lists/:id/items/:post", app.requireActivatedUser(app.removeReadingListItemHandler))
+	router.HandlerFunc(http.MethodGet, "/api/v1/users/:id/lists", app.showUserReadingListsHandler)
+
+	// Profile routes
+	router.HandlerFunc(http.MethodGet, "/api/v1/users/:id", app.showUserProfileHandler)
+	router.HandlerFunc(http.MethodPatch, "/api/v1/users/me", app.requireActivatedUser(app.updateProfileHandler))
+	router.HandlerFunc(http.MethodPost, "/api/v1/users/me/email", app.requireActivatedUser(app.requestEmailChangeHandler))
+
+	// Follow routes
+	router.HandlerFunc(http.MethodPut, "/api/v1/users/:id/follow", app.requireActivatedUser(app.followUserHandler))
+	router.HandlerFunc(http.MethodDelete, "/api/v1/users/:id/follow", app.requireActivatedUser(app.unfollowUserHandler))
+	router.HandlerFunc(http.MethodGet, "/api/v1/users/:id/followers", app.showFollowersHandler)
+	router.HandlerFunc(http.MethodGet, "/api/v1/users/:id/following", app.showFollowingHandler)
+	router.HandlerFunc(http.MethodGet, "/api/v1/feed", app.requirePermission(data.PermissionPostsRead, app.showFeedHandler))
+
+	// Search routes
+	router.HandlerFunc(http.MethodGet, "/api/v1/search", app.searchPostsHandler)
+
+	// Tag routes
+	router.HandlerFunc(http.MethodGet, "/api/v1/tags", app.showTagsHandler)
+
 	// Comment routes
 	router.HandlerFunc(http.MethodGet, "/api/v1/posts/comments/:id", app.showCommentsForPostHandler)
-	router.HandlerFunc(http.MethodPost, "/api/v1/posts/comment", app.requireAuthenticatedUser(app.createCommentHandler))
-	router.HandlerFunc(http.MethodDelete, "/api/v1/posts/comment/:id", app.requireAuthenticatedUser(app.deleteCommentHandler))
+	router.HandlerFunc(http.MethodPost, "/api/v1/posts/comment", app.requirePermission(data.PermissionCommentsWrite, app.createCommentHandler))
+	router.HandlerFunc(http.MethodPatch, "/api/v1/posts/comment/:id", app.requirePermission(data.PermissionCommentsWrite, app.updateCommentHandler))
+	router.HandlerFunc(http.MethodDelete, "/api/v1/posts/comment/:id", app.requirePermission(data.PermissionCommentsWrite, app.deleteCommentHandler))
+	router.HandlerFunc(http.MethodPatch, "/api/v1/posts/comment/:id/like", app.requireAuthenticatedUser(app.likeCommentHandler))
+	router.HandlerFunc(http.MethodPatch, "/api/v1/posts/comment/:id/dislike", app.requireAuthenticatedUser(app.dislikeCommentHandler))
+	router.HandlerFunc(http.MethodGet, "/api/v1/posts/comment/:id/replies", app.showCommentRepliesHandler)
+	router.HandlerFunc(http.MethodGet, "/api/v1/posts/comment/:id/reactions", app.showCommentReactionsHandler)
+	router.HandlerFunc(http.MethodPut, "/api/v1/posts/comment/:id/reactions/:emoji", app.requireAuthenticatedUser(app.addCommentReactionHandler))
+	router.HandlerFunc(http.MethodDelete, "/api/v1/posts/comment/:id/reactions/:emoji", app.requireAuthenticatedUser(app.removeCommentReactionHandler))
+	router.HandlerFunc(http.MethodGet, "/api/v1/posts/comment/:id/history", app.requirePermission(data.PermissionContentModerate, app.showCommentEditHistoryHandler))
+
+	// Moderation routes
+	router.HandlerFunc(http.MethodGet, "/api/v1/moderation/comments", app.requirePermission(data.PermissionContentModerate, app.showModerationQueueHandler))
+	router.HandlerFunc(http.MethodPatch, "/api/v1/moderation/comments", app.requirePermission(data.PermissionContentModerate, app.moderateCommentsHandler))
+
+	// Report routes
+	router.HandlerFunc(http.MethodPost, "/api/v1/reports", app.requireActivatedUser(app.createReportHandler))
+	router.HandlerFunc(http.MethodGet, "/api/v1/reports", app.requirePermission(data.PermissionContentModerate, app.showReportsHandler))
+	router.HandlerFunc(http.MethodGet, "/api/v1/reports/:id", app.requirePermission(data.PermissionContentModerate, app.showReportHandler))
+	router.HandlerFunc(http.MethodPost, "/api/v1/reports/:id/resolve", app.requirePermission(data.PermissionContentModerate, app.resolveReportHandler))
 
 	// Authentication routes
 	router.HandlerFunc(http.MethodPost, "/api/v1/auth/register", app.registerUserHandler)
 	router.HandlerFunc(http.MethodPut, "/api/v1/auth/activate", app.activateUserHandler)
 	router.HandlerFunc(http.MethodPost, "/api/v1/auth/login", app.createAuthenticationTokenHandler)
+	router.HandlerFunc(http.MethodPost, "/api/v1/auth/refresh", app.refreshAuthenticationTokenHandler)
+	router.HandlerFunc(http.MethodDelete, "/api/v1/auth/token", app.requireAuthenticatedUser(app.deleteAuthenticationTokenHandler))
+	router.HandlerFunc(http.MethodGet, "/api/v1/auth/sessions", app.requireAuthenticatedUser(app.showSessionsHandler))
+	router.HandlerFunc(http.MethodDelete, "/api/v1/auth/sessions", app.requireAuthenticatedUser(app.deleteOtherSessionsHandler))
+	router.HandlerFunc(http.MethodDelete, "/api/v1/auth/sessions/:id", app.requireAuthenticatedUser(app.deleteSessionHandler))
+	router.HandlerFunc(http.MethodGet, "/api/v1/auth/personal-tokens", app.requireAuthenticatedUser(app.showPersonalTokensHandler))
+	router.HandlerFunc(http.MethodPost, "/api/v1/auth/personal-tokens", app.requireActivatedUser(app.createPersonalTokenHandler))
+	router.HandlerFunc(http.MethodDelete, "/api/v1/auth/personal-tokens/:id", app.requireAuthenticatedUser(app.deletePersonalTokenHandler))
+	router.HandlerFunc(http.MethodPost, "/api/v1/auth/2fa/verify", app.verifyTwoFactorHandler)
+	router.HandlerFunc(http.MethodPost, "/api/v1/auth/2fa/enroll", app.requireActivatedUser(app.enrollTwoFactorHandler))
+	router.HandlerFunc(http.MethodPost, "/api/v1/auth/2fa/enable", app.requireActivatedUser(app.enableTwoFactorHandler))
+	router.HandlerFunc(http.MethodDelete, "/api/v1/auth/2fa", app.requireActivatedUser(app.disableTwoFactorHandler))
+	router.HandlerFunc(http.MethodPost, "/api/v1/auth/2fa/recovery-codes", app.requireActivatedUser(app.regenerateRecoveryCodesHandler))
+	router.HandlerFunc(http.MethodPost, "/api/v1/auth/password-reset", app.createPasswordResetTokenHandler)
+	router.HandlerFunc(http.MethodPut, "/api/v1/auth/password", app.updateUserPasswordHandler)
+	router.HandlerFunc(http.MethodPut, "/api/v1/auth/email", app.confirmEmailChangeHandler)
+	router.HandlerFunc(http.MethodPut, "/api/v1/auth/email/revert", app.revertEmailChangeHandler)
 
 	return app.metrics(app.recoverPanic(app.secureHeaders(app.enableCORS(app.rateLimit(app.authenticate(router))))))
 }
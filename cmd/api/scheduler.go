@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Periodically publishes scheduled posts which are due and deletes expired tokens.
+type scheduler struct {
+	quit chan struct{}
+}
+
+// Start the scheduler in a background goroutine tracked by app.wg, so graceful shutdown waits for the current run to finish.
+func (app *application) startScheduler() {
+	app.scheduler = &scheduler{quit: make(chan struct{})}
+
+	app.logger.PrintInfo("starting scheduler", map[string]string{
+		"interval": app.config.scheduler.interval.String(),
+	})
+
+	app.background(func() {
+		ticker := time.NewTicker(app.config.scheduler.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				app.publishDuePosts()
+				app.deleteExpiredTokens()
+			case <-app.scheduler.quit:
+				return
+			}
+		}
+	})
+}
+
+// Signal the scheduler goroutine to stop. It is a no-op when the scheduler was never started.
+func (app *application) stopScheduler() {
+	if app.scheduler == nil {
+		return
+	}
+
+	app.logger.PrintInfo("stopping scheduler", nil)
+	close(app.scheduler.quit)
+}
+
+func (app *application) publishDuePosts() {
+	// Recover panic so a single failed run doesn't stop the scheduler.
+	defer func() {
+		if err := recover(); err != nil {
+			app.logger.PrintError(fmt.Errorf("%s", err), nil)
+		}
+	}()
+
+	posts, err := app.models.Posts.PublishDue(app.config.scheduler.batchSize)
+	if err != nil {
+		app.logger.PrintError(err, map[string]string{"task": "publish scheduled posts"})
+		return
+	}
+
+	for _, post := range posts {
+		app.logger.PrintInfo("scheduled post published", map[string]string{
+			"post_id":      strconv.FormatInt(post.ID, 10),
+			"title":        post.Title,
+			"created_by":   strconv.FormatInt(post.CreatedBy, 10),
+			"published_at": post.PublishedAt.Format(time.RFC3339),
+		})
+	}
+}
+
+// Expired tokens are never accepted, but would otherwise pile up, rotated refresh tokens in particular.
+func (app *application) deleteExpiredTokens() {
+	deleted, err := app.models.Tokens.DeleteExpired()
+	if err != nil {
+		app.logger.PrintError(err, map[string]string{"task": "delete expired tokens"})
+		return
+	}
+
+	if deleted > 0 {
+		app.logger.PrintInfo("expired tokens deleted", map[string]string{
+			"count": strconv.FormatInt(deleted, 10),
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+	"github.com/AthfanFasee/blog-post-backend/internal/jsonlog"
+)
+
+func TestPublishDuePosts(t *testing.T) {
+	var buf bytes.Buffer
+
+	app := &application{
+		logger: jsonlog.New(&buf, jsonlog.LevelInfo),
+		models: data.NewMockModels(),
+	}
+
+	app.publishDuePosts()
+
+	// The mocked PublishDue returns mocked post 1.
+	assert.StringContains(t, buf.String(), "scheduled post published")
+	assert.StringContains(t, buf.String(), `"post_id":"1"`)
+}
+
+func TestDeleteExpiredTokens(t *testing.T) {
+	var buf bytes.Buffer
+
+	app := &application{
+		logger: jsonlog.New(&buf, jsonlog.LevelInfo),
+		models: data.NewMockModels(),
+	}
+
+	app.models.Tokens = data.MockTokenModel{
+		MockDeleteExpired: func() (int64, error) {
+			return 3, nil
+		},
+	}
+
+	app.deleteExpiredTokens()
+
+	assert.StringContains(t, buf.String(), "expired tokens deleted")
+	assert.StringContains(t, buf.String(), `"count":"3"`)
+}
+
+func TestSchedulerStop(t *testing.T) {
+	app := newTestApplication(t)
+	app.config.scheduler.interval = time.Millisecond
+
+	app.startScheduler()
+
+	// Let the scheduler run a few times before stopping it.
+	time.Sleep(10 * time.Millisecond)
+	app.stopScheduler()
+
+	done := make(chan struct{})
+	go func() {
+		app.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+}
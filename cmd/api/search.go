@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+	"github.com/AthfanFasee/blog-post-backend/internal/tsquery"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+)
+
+// Full-text search over public posts. The q parameter supports "exact phrases", prefix* words and -excluded words.
+func (app *application) searchPostsHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Query string
+		data.Filters
+	}
+
+	v := validator.New()
+
+	queryString := r.URL.Query()
+
+	input.Query = tsquery.Parse(app.readString(queryString, "q", ""))
+	input.Filters.Page = app.readInt(queryString, "page", 1, v)
+	input.Filters.Limit = app.readInt(queryString, "limit", 10, v)
+
+	// Results are always ordered by relevance.
+	input.Filters.Sort = "rank"
+	input.Filters.SortSafeList = []string{"rank"}
+
+	v.Check(input.Query != "", "q", "must contain at least one search term")
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	results, metadata, err := app.models.Posts.Search(input.Query, input.Filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"results": results, "metadata": metadata}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
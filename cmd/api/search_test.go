@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+)
+
+func TestSearchPostsHandler(t *testing.T) {
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Matching Query",
+			urlPath:  "/api/v1/search?q=mocked",
+			wantCode: http.StatusOK,
+			wantBody: `\u003cmark\u003eMocked\u003c/mark\u003e post text`,
+		},
+		{
+			name:     "No Matches",
+			urlPath:  "/api/v1/search?q=unknown",
+			wantCode: http.StatusOK,
+			wantBody: `"results": []`,
+		},
+		{
+			name:     "Missing Query",
+			urlPath:  "/api/v1/search",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "must contain at least one search term",
+		},
+		{
+			name:     "Only Excluded Words",
+			urlPath:  "/api/v1/search?q=-java",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "must contain at least one search term",
+		},
+		{
+			name:     "Invalid Limit",
+			urlPath:  "/api/v1/search?q=mocked&limit=101",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "must be a maximum of 100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.get(t, tt.urlPath)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
@@ -44,6 +44,9 @@ func (app *application) serve() error {
 			shutdownError <- err
 		}
 
+		app.stopScheduler()
+		app.stopRevocationList()
+
 		app.logger.PrintInfo("completing background tasks", map[string]string{
 			"addr": srv.Addr,
 		})
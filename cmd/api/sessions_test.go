@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+)
+
+func TestSessionHandlers(t *testing.T) {
+	// Every user has two sessions, 1 being the current one.
+	tests := []struct {
+		name     string
+		method   string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{name: "List sessions", method: http.MethodGet, urlPath: "/api/v1/auth/sessions", wantCode: http.StatusOK, wantBody: `"current": true`},
+		{name: "Session details", method: http.MethodGet, urlPath: "/api/v1/auth/sessions", wantCode: http.StatusOK, wantBody: `"userAgent": "Other Agent"`},
+		{name: "Revoke session", method: http.MethodDelete, urlPath: "/api/v1/auth/sessions/2", wantCode: http.StatusOK, wantBody: "session revoked successfully"},
+		{name: "Revoke unknown session", method: http.MethodDelete, urlPath: "/api/v1/auth/sessions/3", wantCode: http.StatusNotFound},
+		{name: "Revoke other sessions", method: http.MethodDelete, urlPath: "/api/v1/auth/sessions", wantCode: http.StatusOK, wantBody: `"revoked": 1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, 1, data.RoleReader)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, tt.method, tt.urlPath, data.GenerateTestToken(), "")
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+
+	t.Run("Logout", func(t *testing.T) {
+		app := newTestApplication(t)
+		authenticateAs(app, 1, data.RoleReader)
+
+		var revoked data.SessionRef
+		app.models.Tokens = data.MockTokenModel{
+			MockDeleteSession: func(session data.SessionRef) error {
+				revoked = session
+				return nil
+			},
+		}
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		token := data.GenerateTestToken()
+
+		code, _, _ := ts.do(t, http.MethodDelete, "/api/v1/auth/token", token, "")
+
+		assert.Equal(t, code, http.StatusOK)
+		assert.Equal(t, revoked, data.SessionRef{TokenPlainText: token})
+	})
+
+	t.Run("Anonymous user", func(t *testing.T) {
+		app := newTestApplication(t)
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		code, _, _ := ts.get(t, "/api/v1/auth/sessions")
+
+		assert.Equal(t, code, http.StatusUnauthorized)
+	})
+}
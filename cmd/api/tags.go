@@ -0,0 +1,19 @@
+package main
+
+import (
+	"net/http"
+)
+
+// List the tags of public posts with their post counts, most used first.
+func (app *application) showTagsHandler(w http.ResponseWriter, r *http.Request) {
+	tags, err := app.models.Tags.GetAll()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"tags": tags}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
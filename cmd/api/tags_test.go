@@ -0,0 +1,20 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+)
+
+func TestShowTagsHandler(t *testing.T) {
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, _, body := ts.get(t, "/api/v1/tags")
+
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, `"postsCount": 1`)
+}
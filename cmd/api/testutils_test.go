@@ -5,17 +5,29 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/AthfanFasee/blog-post-backend/internal/data"
 	"github.com/AthfanFasee/blog-post-backend/internal/jsonlog"
+	"github.com/AthfanFasee/blog-post-backend/internal/signedtoken"
 )
 
 func newTestApplication(t *testing.T) *application {
-	return &application{
+	app := &application{
 		logger: jsonlog.New(io.Discard, jsonlog.LevelInfo),
 		models: data.NewMockModels(),
 	}
+
+	app.config.comments.maxDepth = 2
+	app.config.comments.moderation = data.ModerationModeOpen
+	app.config.reports.hideThreshold = 5
+	app.config.reactions.allowed = data.DefaultReactions
+	app.config.tokens.accessTTL = 15 * time.Minute
+	app.config.tokens.refreshTTL = 30 * 24 * time.Hour
+
+	return app
 }
 
 type testServer struct {
@@ -43,3 +55,58 @@ func (ts *testServer) get(t *testing.T, urlPath string) (int, http.Header, strin
 
 	return rs.StatusCode, rs.Header, string(body)
 }
+
+// Send a request with an optional bearer token and JSON body.
+func (ts *testServer) do(t *testing.T, method, urlPath, token, requestBody string) (int, http.Header, string) {
+	req, err := http.NewRequest(method, ts.URL+urlPath, strings.NewReader(requestBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rs, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer rs.Body.Close()
+	body, err := io.ReadAll(rs.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rs.StatusCode, rs.Header, string(body)
+}
+
+// Make the authenticate middleware resolve every token to a user with the given id and role.
+func authenticateAs(app *application, id int64, role string) {
+	app.models.Users = data.MockUserModel{
+		MockGetForToken: func(tokenScope, tokenPlainText string) (*data.User, error) {
+			return &data.User{ID: id, Name: "Mocked Name", Activated: true, Role: role}, nil
+		},
+	}
+}
+
+// Make the app issue signed access tokens, with the revoked sessions of the mocked model loaded.
+func useSignedTokens(t *testing.T, app *application) {
+	key, err := signedtoken.NewHMACKey("test", bytes.Repeat([]byte("k"), 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.config.tokens.keys, err = signedtoken.NewKeySet(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.config.tokens.format = tokenFormatSigned
+	app.revocations = newRevocationList()
+
+	err = app.reloadRevocations()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
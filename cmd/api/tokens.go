@@ -6,9 +6,41 @@ import (
 	"time"
 
 	"github.com/AthfanFasee/blog-post-backend/internal/data"
+	"github.com/AthfanFasee/blog-post-backend/internal/signedtoken"
 	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+	"github.com/tomasen/realip"
 )
 
+const (
+	tokenFormatOpaque = "opaque"
+	tokenFormatSigned = "signed"
+)
+
+// In signed mode, swap the access token's opaque plaintext for a signed token carrying the user, so requests made with
+// it don't need a database lookup. The opaque token stays in the database as the session's record.
+func (app *application) signAccessToken(user *data.User, token *data.Token) error {
+	if app.config.tokens.format != tokenFormatSigned {
+		return nil
+	}
+
+	signed, err := app.config.tokens.keys.Sign(signedtoken.Claims{
+		UserID:    user.ID,
+		Name:      user.Name,
+		Role:      user.Role,
+		Activated: user.Activated,
+		Session:   token.Family,
+		IssuedAt:  time.Now().Unix(),
+		ExpiresAt: token.Expiry.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	token.Plaintext = signed
+
+	return nil
+}
+
 // When user logs in, create a token and send it to them.
 func (app *application) createAuthenticationTokenHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
@@ -58,13 +90,175 @@ func (app *application) createAuthenticationTokenHandler(w http.ResponseWriter,
 		return
 	}
 
-	token, err := app.models.Tokens.New(user.ID, 30*24*time.Hour, data.ScopeAuthentication)
+	twoFactor, err := app.models.TwoFactor.Get(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// The password alone isn't enough, the session is only created once a code is verified with the challenge token.
+	if twoFactor.Enabled {
+		challenge, err := app.models.Tokens.New(user.ID, 5*time.Minute, data.Scope2FAChallenge)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		env := envelope{"2fa_challenge": challenge, "message": "a two-factor authentication code is required"}
+
+		err = app.writeJSON(w, http.StatusAccepted, env, nil)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.createSession(w, r, user)
+}
+
+// Start a session for a user who just logged in, and send them its tokens.
+func (app *application) createSession(w http.ResponseWriter, r *http.Request, user *data.User) {
+	token, refreshToken, err := app.models.Tokens.NewSession(user.ID, app.config.tokens.accessTTL, app.config.tokens.refreshTTL)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Record where the session was started from.
+	err = app.models.Tokens.Touch(token.Plaintext, realip.FromRequest(r), r.UserAgent())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.signAccessToken(user, token)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	env := envelope{"authentication_token": token, "refresh_token": refreshToken, "userName": user.Name}
+
+	err = app.writeJSON(w, http.StatusCreated, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Trade a refresh token for a new access token and refresh token. A refresh token can only be used once.
+func (app *application) refreshAuthenticationTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		TokenPlainText string `json:"refreshToken"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if data.ValidateTokenPlainText(v, input.TokenPlainText); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	token, refreshToken, err := app.models.Tokens.Refresh(input.TokenPlainText, app.config.tokens.accessTTL, app.config.tokens.refreshTTL)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.invalidRefreshTokenResponse(w, r)
+		case errors.Is(err, data.ErrRefreshTokenReused):
+			app.logger.PrintInfo("refresh token reused, session revoked", map[string]string{"ip": realip.FromRequest(r)})
+			app.invalidRefreshTokenResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.models.Tokens.Touch(token.Plaintext, realip.FromRequest(r), r.UserAgent())
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusCreated, envelope{"authentication_token": token, "userName": user.Name}, nil)
+	// The user may have changed since the last token was signed, so the claims are read fresh.
+	if app.config.tokens.format == tokenFormatSigned {
+		user, err := app.models.Users.Get(token.UserID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		err = app.signAccessToken(user, token)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"authentication_token": token, "refresh_token": refreshToken}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Email a password reset token to the user with the given email. The response is the same whether or not such a
+// user exists, so the endpoint can't be used to find out which emails have an account.
+func (app *application) createPasswordResetTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Email string `json:"email"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if data.ValidateEmail(v, input.Email); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	env := envelope{"message": "if an account with that email exists, you will receive an email with password reset instructions"}
+
+	user, err := app.models.Users.GetByEmail(input.Email)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			err = app.writeJSON(w, http.StatusAccepted, env, nil)
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+			}
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	token, err := app.models.Tokens.New(user.ID, 45*time.Minute, data.ScopePasswordReset)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.background(func() {
+		data := map[string]interface{}{
+			"passwordResetToken": token.Plaintext,
+		}
+
+		err := app.mailer.Send(user.Email, "user_password_reset.tmpl", data)
+		if err != nil {
+			app.logger.PrintError(err, nil)
+		}
+	})
+
+	err = app.writeJSON(w, http.StatusAccepted, env, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -39,22 +39,35 @@ func TestCreateAuthenticationTokenHandler(t *testing.T) {
 				UserAnonymous: false,
 			},
 			Tokens: data.MockTokenModel{
-				MockNew: func(userID int64, ttl time.Duration, scope string) (*data.Token, error) {
-					if userID != 1 || ttl != 30*24*time.Hour || scope != data.ScopeAuthentication {
-						t.Error("Unexpected parameters to MockNew")
+				MockNewSession: func(userID int64, accessTTL, refreshTTL time.Duration) (*data.Token, *data.Token, error) {
+					if userID != 1 || accessTTL != 15*time.Minute || refreshTTL != 30*24*time.Hour {
+						t.Error("Unexpected parameters to MockNewSession")
 					}
 
-					return &data.Token{
+					access := &data.Token{
 						UserID: userID,
-						Expiry: time.Now().Add(ttl),
-						Scope:  scope,
+						Expiry: time.Now().Add(accessTTL),
+						Scope:  data.ScopeAuthentication,
 						Hash:   []byte("mocked-hash"),
-					}, nil
+					}
+
+					refresh := &data.Token{
+						UserID: userID,
+						Expiry: time.Now().Add(refreshTTL),
+						Scope:  data.ScopeRefresh,
+						Hash:   []byte("mocked-refresh-hash"),
+					}
+
+					return access, refresh, nil
 				},
 			},
+			TwoFactor: data.MockTwoFactorModel{},
 		},
 	}
 
+	app.config.tokens.accessTTL = 15 * time.Minute
+	app.config.tokens.refreshTTL = 30 * 24 * time.Hour
+
 	tests := []struct {
 		name           string
 		requestBody    string
@@ -70,6 +83,10 @@ func TestCreateAuthenticationTokenHandler(t *testing.T) {
 					"token":  "",
 					"userID": float64(1), // After unmarshalling, numeric values become float64, not int
 				},
+				"refresh_token": map[string]interface{}{
+					"token":  "",
+					"userID": float64(1),
+				},
 				"userName": "Mocked Name",
 			},
 		},
@@ -105,9 +122,11 @@ func TestCreateAuthenticationTokenHandler(t *testing.T) {
 				if err := json.Unmarshal(body, &responseMap); err != nil {
 					t.Errorf("Failed to unmarshal response body: %v", err)
 				}
-				tokenMap := responseMap["authentication_token"].(map[string]interface{})
-				// Remove the expiry key as it changes rapidly and is hard to test.
-				delete(tokenMap, "expiry")
+				// Remove the expiry keys as they change rapidly and are hard to test.
+				for _, key := range []string{"authentication_token", "refresh_token"} {
+					tokenMap := responseMap[key].(map[string]interface{})
+					delete(tokenMap, "expiry")
+				}
 
 				if !mapEquals(responseMap, tt.wantBody) {
 					t.Errorf("Unexpected body. Want \n%+v\n; got \n%+v", tt.wantBody, responseMap)
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+	"github.com/AthfanFasee/blog-post-backend/internal/totp"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+)
+
+// Number of codes that can be tried with one challenge token.
+const maxChallengeAttempts = 5
+
+// Exchange the challenge token from logging in and a code for a session.
+func (app *application) verifyTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		TokenPlainText string `json:"token"`
+		Code           string `json:"code"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	data.ValidateTokenPlainText(v, input.TokenPlainText)
+	data.ValidateTwoFactorCode(v, input.Code)
+
+	if !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	user, err := app.models.Users.GetForToken(data.Scope2FAChallenge, input.TokenPlainText)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("token", "invalid or expired two-factor challenge")
+			app.validationFailedResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	ok, err := app.models.Tokens.CountAttempt(data.Scope2FAChallenge, input.TokenPlainText, maxChallengeAttempts)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !ok {
+		v.AddError("token", "invalid or expired two-factor challenge")
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	if !app.checkTwoFactorCode(w, r, user.ID, input.Code) {
+		return
+	}
+
+	err = app.models.Tokens.DeleteAllForUser(data.Scope2FAChallenge, user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.createSession(w, r, user)
+}
+
+// Generate a new secret for the user's authenticator app. Codes are only asked for on login after one was verified
+// with enableTwoFactorHandler.
+func (app *application) enrollTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Password string `json:"password"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user, ok := app.confirmPassword(w, r, input.Password)
+	if !ok {
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.TwoFactor.SetSecret(user.ID, secret)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrTwoFactorEnabled):
+			app.twoFactorEnabledResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	env := envelope{"secret": secret, "uri": totp.URI(app.config.twoFactor.issuer, user.Email, secret)}
+
+	err = app.writeJSON(w, http.StatusOK, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Turn on two-factor authentication with a code from the enrolled authenticator, and send the recovery codes.
+// They can't be shown again.
+func (app *application) enableTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Code string `json:"code"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if data.ValidateTwoFactorCode(v, input.Code); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	twoFactor, err := app.models.TwoFactor.Get(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	switch {
+	case twoFactor.Enabled:
+		app.twoFactorEnabledResponse(w, r)
+		return
+	case twoFactor.Secret == "":
+		v.AddError("code", "two-factor authentication must be enrolled first")
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	step, ok := totp.Validate(twoFactor.Secret, input.Code, time.Now())
+	if !ok {
+		v.AddError("code", "invalid code")
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	recoveryCodes, err := data.GenerateRecoveryCodes()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.TwoFactor.Enable(user.ID, step, recoveryCodes)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrTwoFactorEnabled):
+			app.twoFactorEnabledResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	env := envelope{"message": "two-factor authentication enabled", "recovery_codes": recoveryCodes}
+
+	err = app.writeJSON(w, http.StatusOK, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Turning two-factor authentication off needs both the password and a code.
+func (app *application) disableTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Password string `json:"password"`
+		Code     string `json:"code"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if data.ValidateTwoFactorCode(v, input.Code); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	user, ok := app.confirmPassword(w, r, input.Password)
+	if !ok {
+		return
+	}
+
+	if !app.checkTwoFactorCode(w, r, user.ID, input.Code) {
+		return
+	}
+
+	err = app.models.TwoFactor.Disable(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "two-factor authentication disabled"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Replace the recovery codes, for when they were lost or are running out.
+func (app *application) regenerateRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Code string `json:"code"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if data.ValidateTwoFactorCode(v, input.Code); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	if !app.checkTwoFactorCode(w, r, user.ID, input.Code) {
+		return
+	}
+
+	recoveryCodes, err := data.GenerateRecoveryCodes()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.TwoFactor.ReplaceRecoveryCodes(user.ID, recoveryCodes)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"recovery_codes": recoveryCodes}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Check a code of a user with two-factor authentication enabled, which is either from their authenticator or one of
+// their recovery codes. Both can only be used once, and codes are refused for a while after too many invalid ones.
+// Writes the error response and returns false if it isn't valid.
+func (app *application) checkTwoFactorCode(w http.ResponseWriter, r *http.Request, userID int64, code string) bool {
+	twoFactor, err := app.models.TwoFactor.Get(userID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return false
+	}
+
+	switch {
+	case !twoFactor.Enabled:
+		app.twoFactorNotEnabledResponse(w, r)
+		return false
+	case twoFactor.IsLocked():
+		app.twoFactorLockedResponse(w, r)
+		return false
+	}
+
+	var valid bool
+
+	if step, ok := totp.Validate(twoFactor.Secret, code, time.Now()); ok {
+		valid, err = app.models.TwoFactor.UseStep(userID, step)
+	} else {
+		valid, err = app.models.TwoFactor.UseRecoveryCode(userID, code)
+	}
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return false
+	}
+
+	if !valid {
+		err = app.models.TwoFactor.AddFailedAttempt(userID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return false
+		}
+
+		v := validator.New()
+		v.AddError("code", "invalid or already used code")
+		app.validationFailedResponse(w, r, v.Errors)
+		return false
+	}
+
+	return true
+}
+
+// Make the current user re-enter their password. The user is read fresh, as users authenticated with a signed token
+// don't carry their password hash. Writes the error response and returns false if it doesn't match.
+func (app *application) confirmPassword(w http.ResponseWriter, r *http.Request, password string) (*data.User, bool) {
+	v := validator.New()
+
+	if data.ValidatePasswordPlaintext(v, password); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return nil, false
+	}
+
+	user, err := app.models.Users.Get(app.contextGetUser(r).ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return nil, false
+	}
+
+	match, err := user.Password.Matches(password)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return nil, false
+	}
+
+	if !match {
+		app.invalidCredentialsResponse(w, r)
+		return nil, false
+	}
+
+	return user, true
+}
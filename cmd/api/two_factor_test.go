@@ -0,0 +1,342 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+	"github.com/AthfanFasee/blog-post-backend/internal/data"
+	"github.com/AthfanFasee/blog-post-backend/internal/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+
+func currentTOTPCode(t *testing.T) string {
+	code, err := totp.Code(testTOTPSecret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// A user with a password and, when enabled is set, two-factor authentication.
+func useTwoFactorUser(t *testing.T, app *application, enabled bool) {
+	user := &data.User{ID: 1, Name: "Mocked Name", Email: "mocked@email.com", Activated: true, Role: data.RoleAuthor}
+
+	err := user.Password.Set("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.models.Users = data.MockUserModel{
+		MockGetForToken: func(tokenScope, tokenPlainText string) (*data.User, error) {
+			return user, nil
+		},
+		MockGet: func(id int64) (*data.User, error) {
+			return user, nil
+		},
+	}
+
+	app.models.TwoFactor = data.MockTwoFactorModel{
+		MockGet: func(userID int64) (*data.TwoFactor, error) {
+			return &data.TwoFactor{UserID: userID, Secret: testTOTPSecret, Enabled: enabled}, nil
+		},
+	}
+}
+
+func TestLoginWithTwoFactor(t *testing.T) {
+	app := newTestApplication(t)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Logging in looks up the mocked user.
+	data.SetMockUserPassword(hashedPassword)
+	app.models.Users = data.MockUserModel{UserActivated: true}
+
+	app.models.TwoFactor = data.MockTwoFactorModel{
+		MockGet: func(userID int64) (*data.TwoFactor, error) {
+			return &data.TwoFactor{UserID: userID, Secret: testTOTPSecret, Enabled: true}, nil
+		},
+	}
+
+	sessionStarted := false
+	app.models.Tokens = data.MockTokenModel{
+		MockNewSession: func(userID int64, accessTTL, refreshTTL time.Duration) (*data.Token, *data.Token, error) {
+			sessionStarted = true
+			return &data.Token{}, &data.Token{}, nil
+		},
+	}
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, _, body := ts.do(t, http.MethodPost, "/api/v1/auth/login", "", `{"email": "mocked@email.com", "password": "password"}`)
+
+	assert.Equal(t, code, http.StatusAccepted)
+	assert.StringContains(t, body, `"2fa_challenge"`)
+	assert.Equal(t, sessionStarted, false)
+}
+
+func TestVerifyTwoFactorHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		code           string
+		stepUnused     bool
+		recoveryCode   bool
+		challengeFound bool
+		wantCode       int
+		wantBody       string
+	}{
+		{name: "Valid code", code: "current", stepUnused: true, challengeFound: true, wantCode: http.StatusCreated, wantBody: `"authentication_token"`},
+		{name: "Reused code", code: "current", challengeFound: true, wantCode: http.StatusUnprocessableEntity, wantBody: "invalid or already used code"},
+		{name: "Recovery code", code: "abcde-fghij", recoveryCode: true, challengeFound: true, wantCode: http.StatusCreated, wantBody: `"refresh_token"`},
+		{name: "Invalid code", code: "abcde-fghij", challengeFound: true, wantCode: http.StatusUnprocessableEntity, wantBody: "invalid or already used code"},
+		{name: "Invalid challenge", code: "current", stepUnused: true, wantCode: http.StatusUnprocessableEntity, wantBody: "invalid or expired two-factor challenge"},
+		{name: "Missing code", code: "", challengeFound: true, wantCode: http.StatusUnprocessableEntity, wantBody: "must be provided"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+
+			app.models.Users = data.MockUserModel{
+				MockGetForToken: func(tokenScope, tokenPlainText string) (*data.User, error) {
+					assert.Equal(t, tokenScope, data.Scope2FAChallenge)
+
+					if !tt.challengeFound {
+						return nil, data.ErrRecordNotFound
+					}
+					return &data.User{ID: 1, Name: "Mocked Name", Activated: true, Role: data.RoleAuthor}, nil
+				},
+			}
+
+			app.models.TwoFactor = data.MockTwoFactorModel{
+				MockGet: func(userID int64) (*data.TwoFactor, error) {
+					return &data.TwoFactor{UserID: userID, Secret: testTOTPSecret, Enabled: true}, nil
+				},
+				MockUseStep: func(userID int64, step int64) (bool, error) {
+					return tt.stepUnused, nil
+				},
+				MockUseRecoveryCode: func(userID int64, code string) (bool, error) {
+					return tt.recoveryCode, nil
+				},
+			}
+
+			challengeDeleted := false
+			app.models.Tokens = data.MockTokenModel{
+				MockDeleteAllForUser: func(scope string, userID int64) error {
+					challengeDeleted = scope == data.Scope2FAChallenge
+					return nil
+				},
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code := tt.code
+			if code == "current" {
+				code = currentTOTPCode(t)
+			}
+
+			status, _, body := ts.do(t, http.MethodPost, "/api/v1/auth/2fa/verify", "", `{"token": "`+data.GenerateTestToken()+`", "code": "`+code+`"}`)
+
+			assert.Equal(t, status, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+			assert.Equal(t, challengeDeleted, tt.wantCode == http.StatusCreated)
+		})
+	}
+}
+
+func TestVerifyTwoFactorAttemptLimits(t *testing.T) {
+	app := newTestApplication(t)
+
+	app.models.Users = data.MockUserModel{
+		MockGetForToken: func(tokenScope, tokenPlainText string) (*data.User, error) {
+			return &data.User{ID: 1, Name: "Mocked Name", Activated: true, Role: data.RoleAuthor}, nil
+		},
+	}
+
+	// Like the database, the challenge is gone after its last attempt, and codes are refused after too many invalid ones.
+	attempts := 0
+	app.models.Tokens = data.MockTokenModel{
+		MockCountAttempt: func(scope, tokenPlainText string, maxAttempts int) (bool, error) {
+			if attempts == maxAttempts {
+				return false, nil
+			}
+			attempts++
+			return true, nil
+		},
+	}
+
+	failedAttempts := 0
+	var lockedUntil *time.Time
+	app.models.TwoFactor = data.MockTwoFactorModel{
+		MockGet: func(userID int64) (*data.TwoFactor, error) {
+			return &data.TwoFactor{UserID: userID, Secret: testTOTPSecret, Enabled: true, LockedUntil: lockedUntil}, nil
+		},
+		MockAddFailedAttempt: func(userID int64) error {
+			failedAttempts++
+			return nil
+		},
+	}
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	challenge := `{"token": "` + data.GenerateTestToken() + `", "code": "`
+
+	for i := 0; i < maxChallengeAttempts; i++ {
+		code, _, body := ts.do(t, http.MethodPost, "/api/v1/auth/2fa/verify", "", challenge+`abcde-fghij"}`)
+
+		assert.Equal(t, code, http.StatusUnprocessableEntity)
+		assert.StringContains(t, body, "invalid or already used code")
+	}
+
+	assert.Equal(t, failedAttempts, maxChallengeAttempts)
+
+	t.Run("Exhausted challenge", func(t *testing.T) {
+		code, _, body := ts.do(t, http.MethodPost, "/api/v1/auth/2fa/verify", "", challenge+currentTOTPCode(t)+`"}`)
+
+		assert.Equal(t, code, http.StatusUnprocessableEntity)
+		assert.StringContains(t, body, "invalid or expired two-factor challenge")
+	})
+
+	t.Run("Locked user", func(t *testing.T) {
+		attempts = 0
+		locked := time.Now().Add(time.Minute)
+		lockedUntil = &locked
+
+		code, _, body := ts.do(t, http.MethodPost, "/api/v1/auth/2fa/verify", "", challenge+currentTOTPCode(t)+`"}`)
+
+		assert.Equal(t, code, http.StatusTooManyRequests)
+		assert.StringContains(t, body, "too many invalid two-factor codes")
+	})
+}
+
+func TestEnrollTwoFactorHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		password     string
+		setSecretErr error
+		wantCode     int
+		wantBody     string
+	}{
+		{name: "Valid request", password: "password", wantCode: http.StatusOK, wantBody: "otpauth://totp/"},
+		{name: "Wrong password", password: "wrong password", wantCode: http.StatusUnauthorized},
+		{name: "Already enabled", password: "password", setSecretErr: data.ErrTwoFactorEnabled, wantCode: http.StatusConflict, wantBody: "two-factor authentication is already enabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			app.config.twoFactor.issuer = "Blog"
+			useTwoFactorUser(t, app, false)
+
+			secret := ""
+			app.models.TwoFactor = data.MockTwoFactorModel{
+				MockSetSecret: func(userID int64, s string) error {
+					secret = s
+					return tt.setSecretErr
+				},
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodPost, "/api/v1/auth/2fa/enroll", data.GenerateTestToken(), `{"password": "`+tt.password+`"}`)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+
+			if code == http.StatusOK {
+				assert.StringContains(t, body, secret)
+			}
+		})
+	}
+}
+
+func TestEnableTwoFactorHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		twoFactor data.TwoFactor
+		code      string
+		wantCode  int
+		wantBody  string
+	}{
+		{name: "Valid code", twoFactor: data.TwoFactor{Secret: testTOTPSecret}, code: "current", wantCode: http.StatusOK, wantBody: `"recovery_codes"`},
+		{name: "Invalid code", twoFactor: data.TwoFactor{Secret: testTOTPSecret}, code: "000000", wantCode: http.StatusUnprocessableEntity, wantBody: "invalid code"},
+		{name: "Not enrolled", code: "current", wantCode: http.StatusUnprocessableEntity, wantBody: "must be enrolled first"},
+		{name: "Already enabled", twoFactor: data.TwoFactor{Secret: testTOTPSecret, Enabled: true}, code: "current", wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			authenticateAs(app, 1, data.RoleAuthor)
+
+			var recoveryCodes []string
+			app.models.TwoFactor = data.MockTwoFactorModel{
+				MockGet: func(userID int64) (*data.TwoFactor, error) {
+					twoFactor := tt.twoFactor
+					return &twoFactor, nil
+				},
+				MockEnable: func(userID int64, step int64, codes []string) error {
+					recoveryCodes = codes
+					return nil
+				},
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code := tt.code
+			if code == "current" {
+				code = currentTOTPCode(t)
+			}
+
+			status, _, body := ts.do(t, http.MethodPost, "/api/v1/auth/2fa/enable", data.GenerateTestToken(), `{"code": "`+code+`"}`)
+
+			assert.Equal(t, status, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+
+			if status == http.StatusOK {
+				assert.Equal(t, len(recoveryCodes), 10)
+				assert.StringContains(t, body, recoveryCodes[0])
+			}
+		})
+	}
+}
+
+func TestDisableTwoFactorHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		password string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Valid request", enabled: true, password: "password", wantCode: http.StatusOK, wantBody: "two-factor authentication disabled"},
+		{name: "Wrong password", enabled: true, password: "wrong password", wantCode: http.StatusUnauthorized},
+		{name: "Not enabled", password: "password", wantCode: http.StatusConflict, wantBody: "two-factor authentication is not enabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			useTwoFactorUser(t, app, tt.enabled)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.do(t, http.MethodDelete, "/api/v1/auth/2fa", data.GenerateTestToken(), `{"password": "`+tt.password+`", "code": "`+currentTOTPCode(t)+`"}`)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
@@ -24,6 +24,7 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 		Name:      strings.TrimSpace(input.Name),
 		Email:     strings.TrimSpace(input.Email),
 		Activated: true,
+		Role:      data.RoleAuthor,
 	}
 
 	err = user.Password.Set(input.Password)
@@ -130,3 +131,346 @@ func (app *application) activateUserHandler(w http.ResponseWriter, r *http.Reque
 		app.serverErrorResponse(w, r, err)
 	}
 }
+
+// Set a new password with a password reset token. Users with two-factor authentication also need a code, which may be
+// one of their recovery codes. Every session of the user is logged out.
+func (app *application) updateUserPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Password       string `json:"password"`
+		TokenPlainText string `json:"token"`
+		Code           string `json:"code"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	data.ValidatePasswordPlaintext(v, input.Password)
+	data.ValidateTokenPlainText(v, input.TokenPlainText)
+
+	if !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	user, err := app.models.Users.GetForToken(data.ScopePasswordReset, input.TokenPlainText)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("token", "invalid or expired password reset token")
+			app.validationFailedResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	twoFactor, err := app.models.TwoFactor.Get(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if twoFactor.Enabled {
+		if data.ValidateTwoFactorCode(v, input.Code); !v.Valid() {
+			app.validationFailedResponse(w, r, v.Errors)
+			return
+		}
+
+		if !app.checkTwoFactorCode(w, r, user.ID, input.Code) {
+			return
+		}
+	}
+
+	err = user.Password.Set(input.Password)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.Users.Update(user)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// Pending email changes go too, so whoever had access can't move the account to their address after the reset.
+	for _, scope := range []string{data.ScopePasswordReset, data.ScopeEmailChange, data.ScopeEmailRevert, data.ScopeAuthentication, data.ScopeRefresh, data.Scope2FAChallenge} {
+		err = app.models.Tokens.DeleteAllForUser(scope, user.ID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	err = app.models.PersonalTokens.DeleteAllForUser(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.background(func() {
+		err := app.mailer.Send(user.Email, "user_password_changed.tmpl", nil)
+		if err != nil {
+			app.logger.PrintError(err, nil)
+		}
+	})
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "your password was successfully reset"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Users re-enter their password to change their email. Nothing changes until the new address is confirmed
+// with the token mailed to it.
+func (app *application) requestEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	email := strings.TrimSpace(input.Email)
+
+	v := validator.New()
+
+	data.ValidateEmail(v, email)
+	data.ValidatePasswordPlaintext(v, input.Password)
+
+	if !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	// Users authenticated with a signed token only carry their claims, so read the password hash and email fresh.
+	user, err := app.models.Users.Get(app.contextGetUser(r).ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	match, err := user.Password.Matches(input.Password)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !match {
+		app.invalidCredentialsResponse(w, r)
+		return
+	}
+
+	if strings.EqualFold(email, user.Email) {
+		v.AddError("email", "must be different from your current email address")
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	}
+
+	_, err = app.models.Users.GetByEmail(email)
+	switch {
+	case err == nil:
+		v.AddError("email", "email address already exists")
+		app.validationFailedResponse(w, r, v.Errors)
+		return
+	case !errors.Is(err, data.ErrRecordNotFound):
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Only the latest requested address can be confirmed.
+	err = app.models.Tokens.DeleteAllForUser(data.ScopeEmailChange, user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	token, err := app.models.Tokens.NewForEmail(user.ID, 24*time.Hour, data.ScopeEmailChange, email)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.background(func() {
+		data := map[string]interface{}{
+			"emailChangeToken": token.Plaintext,
+		}
+
+		err := app.mailer.Send(email, "user_email_change.tmpl", data)
+		if err != nil {
+			app.logger.PrintError(err, nil)
+		}
+	})
+
+	err = app.writeJSON(w, http.StatusAccepted, envelope{"message": "a confirmation email was sent to the new address"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Switch to the new email once it's confirmed. The old address gets a notice with a token to revert the change.
+func (app *application) confirmEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	user, token, ok := app.getUserForEmailToken(w, r, data.ScopeEmailChange)
+	if !ok {
+		return
+	}
+
+	oldEmail := user.Email
+
+	if !app.changeUserEmail(w, r, user, token.Email) {
+		return
+	}
+
+	err := app.models.Tokens.DeleteAllForUser(data.ScopeEmailChange, user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	revertToken, err := app.models.Tokens.NewForEmail(user.ID, 7*24*time.Hour, data.ScopeEmailRevert, oldEmail)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.background(func() {
+		data := map[string]interface{}{
+			"newEmail":         user.Email,
+			"emailRevertToken": revertToken.Plaintext,
+		}
+
+		err := app.mailer.Send(oldEmail, "user_email_changed.tmpl", data)
+		if err != nil {
+			app.logger.PrintError(err, nil)
+		}
+	})
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "email address changed successfully"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Switch back to the old email from the notice sent to it. Whoever changed the email may have had access to the
+// account, so every session, pending token and personal access token of the user is dropped as well. Two-factor
+// authentication is turned off, as they may have enrolled their own authenticator to lock the user out.
+func (app *application) revertEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	user, token, ok := app.getUserForEmailToken(w, r, data.ScopeEmailRevert)
+	if !ok {
+		return
+	}
+
+	if !app.changeUserEmail(w, r, user, token.Email) {
+		return
+	}
+
+	for _, scope := range []string{data.ScopeEmailRevert, data.ScopeEmailChange, data.ScopePasswordReset, data.ScopeAuthentication, data.ScopeRefresh, data.Scope2FAChallenge} {
+		err := app.models.Tokens.DeleteAllForUser(scope, user.ID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	err := app.models.PersonalTokens.DeleteAllForUser(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.TwoFactor.Disable(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	message := "email address restored, you have been logged out everywhere and two-factor authentication was turned off"
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": message}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Read the token from the request body and get the user it belongs to along with the token itself.
+// It writes the error response itself and returns false when the handler should stop.
+func (app *application) getUserForEmailToken(w http.ResponseWriter, r *http.Request, scope string) (*data.User, *data.Token, bool) {
+	var input struct {
+		TokenPlainText string `json:"token"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return nil, nil, false
+	}
+
+	v := validator.New()
+
+	if data.ValidateTokenPlainText(v, input.TokenPlainText); !v.Valid() {
+		app.validationFailedResponse(w, r, v.Errors)
+		return nil, nil, false
+	}
+
+	token, err := app.models.Tokens.Get(scope, input.TokenPlainText)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("token", "invalid or expired token")
+			app.validationFailedResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, nil, false
+	}
+
+	user, err := app.models.Users.GetForToken(scope, input.TokenPlainText)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("token", "invalid or expired token")
+			app.validationFailedResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, nil, false
+	}
+
+	return user, token, true
+}
+
+// It writes the error response itself and returns false when the handler should stop.
+func (app *application) changeUserEmail(w http.ResponseWriter, r *http.Request, user *data.User, email string) bool {
+	user.Email = email
+
+	err := app.models.Users.Update(user)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateEmail):
+			v := validator.New()
+			v.AddError("email", "email address already exists")
+			app.validationFailedResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return false
+	}
+
+	return true
+}
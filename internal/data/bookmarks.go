@@ -0,0 +1,130 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+	"github.com/lib/pq"
+)
+
+type BookmarkModel struct {
+	DB *sql.DB
+}
+
+// Bookmarking a post twice has no effect.
+func (m BookmarkModel) Insert(userID int64, postID int64) error {
+	query := `
+	INSERT INTO bookmarks (user_id, post_id)
+	VALUES ($1, $2)
+	ON CONFLICT DO NOTHING`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, userID, postID)
+
+	return err
+}
+
+func (m BookmarkModel) Delete(userID int64, postID int64) error {
+	query := `
+	DELETE FROM bookmarks
+	WHERE user_id = $1 AND post_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, userID, postID)
+
+	return err
+}
+
+// Posts the user has bookmarked, most recently bookmarked first. Bookmarked posts which were unpublished or hidden
+// since are left out, unless the user created them.
+func (m BookmarkModel) GetAllForUser(userID int64, filters Filters) ([]*dto.PostResponseBody, Metadata, error) {
+	query := `
+	SELECT count(*) OVER(), p.id, p.title, p.slug, p.post_text, p.img, p.read_time, p.likes_count, p.created_by, p.created_at, p.status, p.publish_at, p.published_at, p.comments_count, p.hidden, u.name,
+	EXISTS(SELECT 1 FROM post_likes pl WHERE pl.post_id = p.id AND pl.user_id = $1),
+	ARRAY(SELECT t.name FROM post_tags pt INNER JOIN tags t ON pt.tag_id = t.id WHERE pt.post_id = p.id ORDER BY t.name)
+	FROM bookmarks b
+	INNER JOIN posts p ON b.post_id = p.id
+	INNER JOIN users u ON p.created_by = u.id
+	WHERE b.user_id = $1
+	AND ((NOT p.hidden AND (p.status = 'published' OR (p.status = 'scheduled' AND p.publish_at <= NOW()))) OR p.created_by = $1)
+	ORDER BY b.created_at DESC, p.id DESC
+	LIMIT $2 OFFSET $3`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	posts := []*dto.PostResponseBody{}
+
+	for rows.Next() {
+		var post Post
+		var userName string
+		err := rows.Scan(
+			&totalRecords,
+			&post.ID,
+			&post.Title,
+			&post.Slug,
+			&post.PostText,
+			&post.Img,
+			&post.ReadTime,
+			&post.LikesCount,
+			&post.CreatedBy,
+			&post.CreatedAt,
+			&post.Status,
+			&post.PublishAt,
+			&post.PublishedAt,
+			&post.CommentsCount,
+			&post.Hidden,
+			&userName,
+			&post.LikedByMe,
+			pq.Array(&post.Tags),
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		PostResponseBody := dto.PostResponseBody{
+			ID:             post.ID,
+			Title:          post.Title,
+			Slug:           post.Slug,
+			PostText:       post.PostText,
+			Img:            post.Img,
+			ReadTime:       post.ReadTime,
+			LikesCount:     post.LikesCount,
+			LikedByMe:      post.LikedByMe,
+			BookmarkedByMe: true,
+			Tags:           post.Tags,
+			Status:         post.Status,
+			PublishAt:      post.PublishAt,
+			PublishedAt:    post.PublishedAt,
+			CommentsCount:  post.CommentsCount,
+			Hidden:         post.Hidden,
+			CreatedAt:      post.CreatedAt,
+			CreatedBy:      post.CreatedBy,
+			UserName:       userName,
+		}
+
+		posts = append(posts, &PostResponseBody)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.Limit)
+
+	return posts, metadata, nil
+}
@@ -0,0 +1,22 @@
+package data
+
+import (
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+)
+
+type MockBookmarkModel struct{}
+
+func (b MockBookmarkModel) Insert(userID int64, postID int64) error {
+	return nil
+}
+
+func (b MockBookmarkModel) Delete(userID int64, postID int64) error {
+	return nil
+}
+
+func (b MockBookmarkModel) GetAllForUser(userID int64, filters Filters) ([]*dto.PostResponseBody, Metadata, error) {
+	post := *mockPostResponseBody
+	post.BookmarkedByMe = true
+
+	return []*dto.PostResponseBody{&post}, mockMetadata, nil
+}
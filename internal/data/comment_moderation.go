@@ -0,0 +1,202 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+	"github.com/lib/pq"
+)
+
+// Comment statuses. Only approved comments are shown to everyone.
+const (
+	CommentStatusPending  = "pending"
+	CommentStatusApproved = "approved"
+	CommentStatusRejected = "rejected"
+	CommentStatusSpam     = "spam"
+)
+
+// Moderation modes, set globally and optionally overridden per post.
+const (
+	ModerationModeOpen      = "open"
+	ModerationModeFirstTime = "first_time"
+	ModerationModeAll       = "all"
+	ModerationModeClosed    = "closed"
+)
+
+var moderationModes = []string{ModerationModeOpen, ModerationModeFirstTime, ModerationModeAll, ModerationModeClosed}
+
+var commentStatuses = []string{CommentStatusPending, CommentStatusApproved, CommentStatusRejected, CommentStatusSpam}
+
+// Maximum number of comments moderated in one request.
+const maxModerationBatch = 100
+
+// Approved comments are visible to everyone, other comments only to their author and moderators, when using a
+// personal access token only with the comments:read scope.
+func (c *Comment) VisibleTo(user *User) bool {
+	return c.Status == CommentStatusApproved || (user.HasPermission(PermissionCommentsRead) && user.CanModify(c.CreatedBy))
+}
+
+// Status of a new comment under the given moderation mode. firstTime is true when the author has no approved comments yet.
+// Comments on closed posts are never created, so the mode is treated like 'all' to be safe.
+func NewCommentStatus(mode string, firstTime bool) string {
+	switch {
+	case mode == ModerationModeOpen:
+		return CommentStatusApproved
+	case mode == ModerationModeFirstTime && !firstTime:
+		return CommentStatusApproved
+	default:
+		return CommentStatusPending
+	}
+}
+
+// Status of a comment after its author edits it. Under 'all' an approved comment goes back to the queue, so it can't be
+// edited into something that wouldn't have been approved.
+func EditedCommentStatus(mode, status string) string {
+	if mode == ModerationModeAll && status == CommentStatusApproved {
+		return CommentStatusPending
+	}
+	return status
+}
+
+// Comments of the given status across all posts, oldest first so the queue is worked through in order.
+func (c CommentModel) GetModerationQueue(status string, filters Filters) ([]*dto.CommentResponseBody, Metadata, error) {
+	query := `
+	SELECT count(*) OVER(), c.id, c.text, c.created_by, c.post_id, c.parent_id, c.depth, c.deleted, c.edited_at, c.likes_count, c.status, u.name
+	FROM comments c
+	INNER JOIN users u ON c.created_by = u.id
+	WHERE c.status = $1 AND NOT c.deleted
+	ORDER BY c.id
+	LIMIT $2 OFFSET $3`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := c.DB.QueryContext(ctx, query, status, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	comments := []*dto.CommentResponseBody{}
+
+	for rows.Next() {
+		var comment Comment
+		var userName string
+
+		err := rows.Scan(
+			&totalRecords,
+			&comment.ID,
+			&comment.Text,
+			&comment.CreatedBy,
+			&comment.PostID,
+			&comment.ParentID,
+			&comment.Depth,
+			&comment.Deleted,
+			&comment.EditedAt,
+			&comment.LikesCount,
+			&comment.Status,
+			&userName,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		comments = append(comments, commentResponseBody(&comment, userName))
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.Limit)
+
+	return comments, metadata, nil
+}
+
+// Set the status of several comments at once and return how many were changed. Tombstones and unknown ids are skipped.
+func (c CommentModel) Moderate(ids []int64, status string, moderatorID int64) (int64, error) {
+	query := `
+	UPDATE comments
+	SET status = $1, moderated_by = $2, moderated_at = NOW()
+	WHERE id = ANY($3) AND NOT deleted AND status <> $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := c.DB.ExecContext(ctx, query, status, moderatorID, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// Reports whether the user has had a comment approved before.
+func (c CommentModel) HasApproved(userID int64) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM comments WHERE created_by = $1 AND status = 'approved')`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var approved bool
+
+	err := c.DB.QueryRowContext(ctx, query, userID).Scan(&approved)
+
+	return approved, err
+}
+
+// Set the moderation mode of a post. A nil mode makes the post follow the global mode.
+func (p PostModel) SetCommentModeration(postID int64, mode *string) error {
+	query := `
+	UPDATE posts
+	SET comment_moderation = $1
+	WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := p.DB.ExecContext(ctx, query, mode, postID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// The post's own mode when it has one, otherwise the global mode.
+func (p *Post) CommentModerationMode(globalMode string) string {
+	if p.CommentModeration != nil {
+		return *p.CommentModeration
+	}
+	return globalMode
+}
+
+func ValidateModerationMode(v *validator.Validator, mode string) {
+	v.Check(validator.In(mode, moderationModes...), "mode", "must be one of open, first_time, all or closed")
+}
+
+func ValidateCommentStatus(v *validator.Validator, status string) {
+	v.Check(validator.In(status, commentStatuses...), "status", "must be one of pending, approved, rejected or spam")
+}
+
+func ValidateModerationBatch(v *validator.Validator, ids []int64) {
+	v.Check(len(ids) > 0, "ids", "must contain at least one comment id")
+	v.Check(len(ids) <= maxModerationBatch, "ids", "must not contain more than 100 comment ids")
+
+	for _, id := range ids {
+		v.Check(id > 0, "ids", "must only contain valid comment ids")
+	}
+}
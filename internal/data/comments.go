@@ -3,114 +3,643 @@ package data
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/AthfanFasee/blog-post-backend/internal/dto"
 	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+	"github.com/lib/pq"
 )
 
+// Text shown in place of a deleted comment which still has replies.
+const deletedCommentText = "[deleted]"
+
 type Comment struct {
-	ID        int64
-	CreatedAt time.Time
-	Text      string
-	CreatedBy int64
-	PostID    int64
+	ID         int64
+	CreatedAt  time.Time
+	Text       string
+	CreatedBy  int64
+	PostID     int64
+	ParentID   *int64
+	Depth      int
+	Deleted    bool
+	EditedAt   *time.Time
+	LikesCount int
+	Status     string
+	Version    int32
 }
 
 type CommentModel struct {
 	DB *sql.DB
 }
 
-func (c CommentModel) GetAllForPost(postID int64) ([]*dto.CommentResponseBody, error) {
-	query := `SELECT c.id, c.text, c.created_by, c.post_id, u.name
+// Sortable fields of the comment listing, keyed by their sort value.
+var commentSortColumns = map[string]sortColumn{
+	"id":         {expr: "c.id", typ: "bigint"},
+	"likescount": {expr: "c.likes_count", typ: "integer"},
+}
+
+// Returns a page of top level comments with all their replies nested under them, oldest reply first.
+// Pages are read by offset, or after/before a cursor when the filters have one.
+//
+// Moderators see comments of every status. Other users see approved comments and their own pending ones.
+func (c CommentModel) GetAllForPost(postID int64, viewerID int64, moderator bool, filters Filters) ([]*dto.CommentResponseBody, Metadata, error) {
+	sortColumn := filters.sortColumn(commentSortColumns)
+
+	count := "count(*) OVER()"
+	if filters.usesCursor() {
+		count = "0"
+	}
+
+	query := fmt.Sprintf(`SELECT %s, %s::text, c.id, c.text, c.created_by, c.post_id, c.parent_id, c.depth, c.deleted, c.edited_at, c.likes_count, c.status, u.name
 	FROM comments c
 	INNER JOIN users u ON c.created_by = u.id
-	WHERE post_id = $1
-	ORDER BY id DESC`
+	WHERE post_id = $1 AND parent_id IS NULL
+	AND (c.status = 'approved' OR $5 OR (c.status = 'pending' AND c.created_by = $4))
+	%s
+	ORDER BY %s %s, c.id %s
+	LIMIT $2 OFFSET $3`, count, sortColumn.expr, filters.cursorCondition(sortColumn, "c.id", 6), sortColumn.expr, filters.sortDirection(), filters.sortDirection())
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	rows, err := c.DB.QueryContext(ctx, query, postID)
+	args := []interface{}{postID, filters.fetchLimit(), filters.offset(), viewerID, moderator}
+
+	if filters.usesCursor() {
+		cursor, err := filters.cursor()
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		args = append(args, cursor.Key, cursor.ID)
+	}
+
+	rows, err := c.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, Metadata{}, err
 	}
 
 	defer rows.Close()
 
+	totalRecords := 0
 	comments := []*dto.CommentResponseBody{}
+	positions := []Cursor{}
 
 	for rows.Next() {
 		var comment Comment
 		var userName string
+		var sortKey string
 
 		err := rows.Scan(
+			&totalRecords,
+			&sortKey,
 			&comment.ID,
 			&comment.Text,
 			&comment.CreatedBy,
 			&comment.PostID,
+			&comment.ParentID,
+			&comment.Depth,
+			&comment.Deleted,
+			&comment.EditedAt,
+			&comment.LikesCount,
+			&comment.Status,
 			&userName,
 		)
 
 		if err != nil {
-			return nil, err
+			return nil, Metadata{}, err
 		}
 
-		CommentResponseBody := dto.CommentResponseBody{
-			ID:        comment.ID,
-			Text:      comment.Text,
-			CreatedBy: comment.CreatedBy,
-			PostID:    comment.PostID,
-			UserName:  userName,
+		comments = append(comments, commentResponseBody(&comment, userName))
+		positions = append(positions, Cursor{Key: sortKey, ID: comment.ID})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	n, metadata := filters.calculatePage(positions, totalRecords)
+	comments = comments[:n]
+
+	if filters.Before != "" {
+		for i, j := 0, len(comments)-1; i < j; i, j = i+1, j-1 {
+			comments[i], comments[j] = comments[j], comments[i]
 		}
+	}
 
-		comments = append(comments, &CommentResponseBody)
+	err = c.addReplies(ctx, comments, viewerID, moderator)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	return comments, metadata, nil
+}
+
+// Replies are loaded along with their comments, oldest first, up to maxRepliesPerComment replies of each comment and
+// maxReplies replies in total. Comments with more replies than that have MoreReplies set, and the rest is read with
+// GetReplies.
+const (
+	maxRepliesPerComment = 10
+	maxReplies           = 500
+)
+
+// Load the replies under the given comments and nest them under their parents. Replies the viewer can't see are left
+// out along with everything under them.
+func (c CommentModel) addReplies(ctx context.Context, comments []*dto.CommentResponseBody, viewerID int64, moderator bool) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	// Replies are cut off by maxReplies level by level, so a reply is never loaded without its parent.
+	query := `
+	WITH RECURSIVE thread AS (
+		SELECT r.id, 1 AS level FROM unnest($1::bigint[]) AS p (id)
+		CROSS JOIN LATERAL (
+			SELECT id FROM comments
+			WHERE parent_id = p.id AND (status = 'approved' OR $3 OR (status = 'pending' AND created_by = $2))
+			ORDER BY id
+			LIMIT $4
+		) r
+		UNION ALL
+		SELECT r.id, t.level + 1 FROM thread t
+		CROSS JOIN LATERAL (
+			SELECT id FROM comments
+			WHERE parent_id = t.id AND (status = 'approved' OR $3 OR (status = 'pending' AND created_by = $2))
+			ORDER BY id
+			LIMIT $4
+		) r
+	), shown AS (
+		SELECT id FROM thread ORDER BY level, id LIMIT $5
+	)
+	SELECT c.id, c.text, c.created_by, c.post_id, c.parent_id, c.depth, c.deleted, c.edited_at, c.likes_count, c.status, u.name
+	FROM shown
+	INNER JOIN comments c ON c.id = shown.id
+	INNER JOIN users u ON c.created_by = u.id
+	ORDER BY c.depth, c.id`
+
+	nodes := make(map[int64]*dto.CommentResponseBody, len(comments))
+	ids := make([]int64, 0, len(comments))
+
+	for _, comment := range comments {
+		nodes[comment.ID] = comment
+		ids = append(ids, comment.ID)
+	}
+
+	rows, err := c.DB.QueryContext(ctx, query, pq.Array(ids), viewerID, moderator, maxRepliesPerComment, maxReplies)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var comment Comment
+		var userName string
+
+		err := rows.Scan(
+			&comment.ID,
+			&comment.Text,
+			&comment.CreatedBy,
+			&comment.PostID,
+			&comment.ParentID,
+			&comment.Depth,
+			&comment.Deleted,
+			&comment.EditedAt,
+			&comment.LikesCount,
+			&comment.Status,
+			&userName,
+		)
+		if err != nil {
+			return err
+		}
+
+		// Ordering by depth makes sure a parent is always seen before its replies.
+		reply := commentResponseBody(&comment, userName)
+		parent := nodes[*comment.ParentID]
+		parent.Replies = append(parent.Replies, reply)
+		nodes[reply.ID] = reply
+		ids = append(ids, reply.ID)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, err
+		return err
+	}
+
+	return c.setMoreReplies(ctx, nodes, ids, viewerID, moderator)
+}
+
+// Set MoreReplies of the comments which have replies the viewer can see that weren't loaded.
+func (c CommentModel) setMoreReplies(ctx context.Context, nodes map[int64]*dto.CommentResponseBody, ids []int64, viewerID int64, moderator bool) error {
+	query := `
+	SELECT parent_id, count(*)
+	FROM comments
+	WHERE parent_id = ANY($1) AND (status = 'approved' OR $3 OR (status = 'pending' AND created_by = $2))
+	GROUP BY parent_id`
+
+	rows, err := c.DB.QueryContext(ctx, query, pq.Array(ids), viewerID, moderator)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var parentID int64
+		var replies int
+
+		err := rows.Scan(&parentID, &replies)
+		if err != nil {
+			return err
+		}
+
+		node := nodes[parentID]
+		node.MoreReplies = replies > len(node.Replies)
+	}
+
+	return rows.Err()
+}
+
+// Replies to a comment after the reply with the id after, oldest first, with the replies under them loaded like in
+// GetAllForPost. Returns true when there are more replies after these.
+func (c CommentModel) GetReplies(parentID int64, viewerID int64, moderator bool, after int64) ([]*dto.CommentResponseBody, bool, error) {
+	query := `
+	SELECT c.id, c.text, c.created_by, c.post_id, c.parent_id, c.depth, c.deleted, c.edited_at, c.likes_count, c.status, u.name
+	FROM comments c
+	INNER JOIN users u ON c.created_by = u.id
+	WHERE c.parent_id = $1 AND c.id > $4
+	AND (c.status = 'approved' OR $3 OR (c.status = 'pending' AND c.created_by = $2))
+	ORDER BY c.id
+	LIMIT $5`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := c.DB.QueryContext(ctx, query, parentID, viewerID, moderator, after, maxRepliesPerComment+1)
+	if err != nil {
+		return nil, false, err
+	}
+
+	defer rows.Close()
+
+	replies := []*dto.CommentResponseBody{}
+
+	for rows.Next() {
+		var comment Comment
+		var userName string
+
+		err := rows.Scan(
+			&comment.ID,
+			&comment.Text,
+			&comment.CreatedBy,
+			&comment.PostID,
+			&comment.ParentID,
+			&comment.Depth,
+			&comment.Deleted,
+			&comment.EditedAt,
+			&comment.LikesCount,
+			&comment.Status,
+			&userName,
+		)
+		if err != nil {
+			return nil, false, err
+		}
+
+		replies = append(replies, commentResponseBody(&comment, userName))
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	more := len(replies) > maxRepliesPerComment
+	if more {
+		replies = replies[:maxRepliesPerComment]
+	}
+
+	err = c.addReplies(ctx, replies, viewerID, moderator)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return replies, more, nil
+}
+
+// The author of a deleted comment is hidden along with its text.
+func commentResponseBody(comment *Comment, userName string) *dto.CommentResponseBody {
+	CommentResponseBody := dto.CommentResponseBody{
+		ID:         comment.ID,
+		Text:       comment.Text,
+		CreatedBy:  comment.CreatedBy,
+		PostID:     comment.PostID,
+		ParentID:   comment.ParentID,
+		Depth:      comment.Depth,
+		Deleted:    comment.Deleted,
+		EditedAt:   comment.EditedAt,
+		LikesCount: comment.LikesCount,
+		Status:     comment.Status,
+		UserName:   userName,
+	}
+
+	if comment.Deleted {
+		CommentResponseBody.Text = deletedCommentText
+		CommentResponseBody.CreatedBy = 0
+		CommentResponseBody.UserName = ""
+	}
+
+	return &CommentResponseBody
+}
+
+func (c CommentModel) Get(id int64) (*Comment, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+	SELECT id, created_at, text, created_by, post_id, parent_id, depth, deleted, edited_at, likes_count, status, version
+	FROM comments
+	WHERE id = $1`
+
+	var comment Comment
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := c.DB.QueryRowContext(ctx, query, id).Scan(
+		&comment.ID,
+		&comment.CreatedAt,
+		&comment.Text,
+		&comment.CreatedBy,
+		&comment.PostID,
+		&comment.ParentID,
+		&comment.Depth,
+		&comment.Deleted,
+		&comment.EditedAt,
+		&comment.LikesCount,
+		&comment.Status,
+		&comment.Version,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
 	}
 
-	return comments, nil
+	return &comment, nil
 }
 
+// A reply is one level deeper than its parent. The status is set by the caller from the post's moderation mode.
 func (c CommentModel) Insert(comment *Comment) error {
 	query := `
-	INSERT INTO comments (text, post_id, created_by)
-	VALUES ($1, $2, $3)
-	RETURNING id`
+	INSERT INTO comments (text, post_id, created_by, parent_id, depth, status)
+	VALUES ($1, $2, $3, $4, COALESCE((SELECT depth + 1 FROM comments WHERE id = $4), 0), $5)
+	RETURNING id, created_at, depth, version`
+
+	args := []interface{}{comment.Text, comment.PostID, comment.CreatedBy, comment.ParentID, comment.Status}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return c.DB.QueryRowContext(ctx, query, args...).Scan(&comment.ID, &comment.CreatedAt, &comment.Depth, &comment.Version)
+}
+
+func (c CommentModel) GetWithUserName(id int64) (*Comment, *string, error) {
+	if id < 1 {
+		return nil, nil, ErrRecordNotFound
+	}
+
+	query := `
+	SELECT c.id, c.created_at, c.text, c.created_by, c.post_id, c.parent_id, c.depth, c.deleted, c.edited_at, c.likes_count, c.status, c.version, u.name
+	FROM comments c
+	INNER JOIN users u ON c.created_by = u.id
+	WHERE c.id = $1`
+
+	var comment Comment
+	var userName string
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := c.DB.QueryRowContext(ctx, query, id).Scan(
+		&comment.ID,
+		&comment.CreatedAt,
+		&comment.Text,
+		&comment.CreatedBy,
+		&comment.PostID,
+		&comment.ParentID,
+		&comment.Depth,
+		&comment.Deleted,
+		&comment.EditedAt,
+		&comment.LikesCount,
+		&comment.Status,
+		&comment.Version,
+		&userName,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, nil, ErrRecordNotFound
+		default:
+			return nil, nil, err
+		}
+	}
+
+	return &comment, &userName, nil
+}
+
+// Save the new text of a comment, keeping the previous text in its edit history. The version the comment was read
+// with must still be current, otherwise ErrEditConflict is returned and nothing changes.
+func (c CommentModel) Update(comment *Comment, editorID int64) error {
+	query := `
+	INSERT INTO comment_edits (comment_id, text, edited_by)
+	SELECT id, text, $3
+	FROM comments
+	WHERE id = $1 AND version = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := c.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, query, comment.ID, comment.Version, editorID)
+	if err != nil {
+		return err
+	}
+
+	// A comment sent back to the queue is no longer moderated.
+	query = `
+	UPDATE comments
+	SET text = $1, edited_at = NOW(), version = version + 1, status = $4,
+	moderated_by = CASE WHEN status = $4 THEN moderated_by END,
+	moderated_at = CASE WHEN status = $4 THEN moderated_at END
+	WHERE id = $2 AND version = $3
+	RETURNING edited_at, version`
+
+	err = tx.QueryRowContext(ctx, query, comment.Text, comment.ID, comment.Version, comment.Status).Scan(&comment.EditedAt, &comment.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
 
-	args := []interface{}{comment.Text, comment.PostID, comment.CreatedBy}
+// Previous texts of a comment, oldest first.
+func (c CommentModel) GetEditHistory(commentID int64) ([]*dto.CommentEditResponseBody, error) {
+	query := `
+	SELECT e.text, e.edited_by, u.name, e.edited_at
+	FROM comment_edits e
+	INNER JOIN users u ON e.edited_by = u.id
+	WHERE e.comment_id = $1
+	ORDER BY e.id`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	return c.DB.QueryRowContext(ctx, query, args...).Scan(&comment.ID)
+	rows, err := c.DB.QueryContext(ctx, query, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	edits := []*dto.CommentEditResponseBody{}
+
+	for rows.Next() {
+		var edit dto.CommentEditResponseBody
+
+		err := rows.Scan(
+			&edit.Text,
+			&edit.EditedBy,
+			&edit.UserName,
+			&edit.EditedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		edits = append(edits, &edit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return edits, nil
 }
 
+// A comment with replies is turned into a tombstone instead of being deleted, so the replies stay in place.
+// Deleting the last reply of a tombstone removes the tombstone as well, all the way up the thread.
 func (c CommentModel) Delete(id int64) error {
 	query := `
-	DELETE FROM comments
-	WHERE id = $1`
+	SELECT parent_id, EXISTS (SELECT 1 FROM comments r WHERE r.parent_id = c.id)
+	FROM comments c
+	WHERE id = $1
+	FOR UPDATE`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	result, err := c.DB.ExecContext(ctx, query, id)
+	tx, err := c.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	defer tx.Rollback()
+
+	var parentID *int64
+	var hasReplies bool
+
+	err = tx.QueryRowContext(ctx, query, id).Scan(&parentID, &hasReplies)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	if hasReplies {
+		_, err = tx.ExecContext(ctx, `UPDATE comments SET text = '', deleted = true WHERE id = $1`, id)
+		if err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM comments WHERE id = $1`, id)
 	if err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return ErrRecordNotFound
+	query = `
+	DELETE FROM comments c
+	WHERE id = $1 AND deleted AND NOT EXISTS (SELECT 1 FROM comments r WHERE r.parent_id = c.id)
+	RETURNING parent_id`
+
+	for parentID != nil {
+		err = tx.QueryRowContext(ctx, query, *parentID).Scan(&parentID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				break
+			}
+			return err
+		}
 	}
 
-	return nil
+	return tx.Commit()
+}
+
+// Liking a comment twice has no effect. The like count is kept up to date by a trigger, which runs after the
+// statement's snapshot was taken, so the new like is added to the count read here.
+func (c CommentModel) AddLike(comment *Comment, userID int64) error {
+	query := `
+	WITH liked AS (
+		INSERT INTO comment_likes (comment_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+		RETURNING comment_id
+	)
+	SELECT likes_count + (SELECT count(*) FROM liked)
+	FROM comments
+	WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return c.DB.QueryRowContext(ctx, query, comment.ID, userID).Scan(&comment.LikesCount)
+}
+
+func (c CommentModel) RemoveLike(comment *Comment, userID int64) error {
+	query := `
+	WITH unliked AS (
+		DELETE FROM comment_likes
+		WHERE comment_id = $1 AND user_id = $2
+		RETURNING comment_id
+	)
+	SELECT likes_count - (SELECT count(*) FROM unliked)
+	FROM comments
+	WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return c.DB.QueryRowContext(ctx, query, comment.ID, userID).Scan(&comment.LikesCount)
 }
 
 func ValidateComment(v *validator.Validator, comment *Comment) {
@@ -120,3 +649,11 @@ func ValidateComment(v *validator.Validator, comment *Comment) {
 	v.Check(comment.PostID != 0, "post", "Post id must be provided")
 	v.Check(comment.PostID > 0, "post", "Post id must be valid")
 }
+
+// Replies must stay on the parent's post and threads can't grow deeper than maxDepth levels of replies.
+func ValidateReply(v *validator.Validator, comment, parent *Comment, maxDepth int) {
+	v.Check(parent.PostID == comment.PostID, "parentId", "Parent comment must belong to the same post")
+	v.Check(!parent.Deleted, "parentId", "Parent comment has been deleted")
+	v.Check(parent.Status == CommentStatusApproved, "parentId", "Parent comment has not been approved")
+	v.Check(parent.Depth < maxDepth, "parentId", fmt.Sprintf("Replies can only be nested %d levels deep", maxDepth))
+}
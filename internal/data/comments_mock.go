@@ -12,30 +12,143 @@ var mockComment = &Comment{
 	Text:      "Mocked Comment",
 	CreatedBy: 1,
 	PostID:    1,
+	Status:    CommentStatusApproved,
+	Version:   1,
 }
 
-var mockCommentResponseBody = &dto.CommentResponseBody{
-	ID:        mockComment.ID,
-	Text:      mockComment.Text,
-	CreatedBy: mockComment.CreatedBy,
+// Reply to mockComment at the deepest level allowed in tests.
+var mockReply = &Comment{
+	ID:        3,
+	CreatedAt: time.Now(),
+	Text:      "Mocked Reply",
+	CreatedBy: 2,
 	PostID:    1,
+	ParentID:  &mockComment.ID,
+	Depth:     2,
+	Status:    CommentStatusApproved,
+	Version:   1,
+}
+
+var mockDeletedComment = &Comment{
+	ID:        4,
+	CreatedAt: time.Now(),
+	CreatedBy: 2,
+	PostID:    1,
+	Deleted:   true,
+	Status:    CommentStatusApproved,
+	Version:   1,
+}
+
+// Awaiting approval, only visible to moderators and user 2 who wrote it.
+var mockPendingComment = &Comment{
+	ID:        6,
+	CreatedAt: time.Now(),
+	Text:      "Mocked Pending Comment",
+	CreatedBy: 2,
+	PostID:    1,
+	Status:    CommentStatusPending,
+	Version:   1,
+}
+
+// Approved, but on mocked draft post 3, so only visible to user 1 who wrote the post and moderators.
+var mockDraftPostComment = &Comment{
+	ID:        7,
+	CreatedAt: time.Now(),
+	Text:      "Mocked Draft Post Comment",
+	CreatedBy: 2,
+	PostID:    3,
+	Status:    CommentStatusApproved,
+	Version:   1,
 }
 
 type MockCommentModel struct{}
 
-func (c MockCommentModel) GetAllForPost(postID int64) ([]*dto.CommentResponseBody, error) {
+func (c MockCommentModel) GetAllForPost(postID int64, viewerID int64, moderator bool, filters Filters) ([]*dto.CommentResponseBody, Metadata, error) {
 	switch postID {
 	case 1:
-		return []*dto.CommentResponseBody{mockCommentResponseBody}, nil
+		// Build a new tree every time, the flat view takes the replies out of it.
+		comment := commentResponseBody(mockComment, "Mocked User")
+		comment.Replies = []*dto.CommentResponseBody{commentResponseBody(mockReply, "Another User")}
+		comments := []*dto.CommentResponseBody{comment}
+
+		if moderator || viewerID == mockPendingComment.CreatedBy {
+			comments = append(comments, commentResponseBody(mockPendingComment, "Another User"))
+		}
+
+		return comments, mockMetadata, nil
+	case 3:
+		return []*dto.CommentResponseBody{commentResponseBody(mockDraftPostComment, "Another User")}, mockMetadata, nil
+	default:
+		return nil, Metadata{}, ErrRecordNotFound
+	}
+}
+
+// Mocked comment 1 has a single reply.
+func (c MockCommentModel) GetReplies(parentID int64, viewerID int64, moderator bool, after int64) ([]*dto.CommentResponseBody, bool, error) {
+	if parentID != mockComment.ID || after >= mockReply.ID {
+		return []*dto.CommentResponseBody{}, false, nil
+	}
+
+	return []*dto.CommentResponseBody{commentResponseBody(mockReply, "Another User")}, false, nil
+}
+
+func (c MockCommentModel) Get(id int64) (*Comment, error) {
+	// Return a copy so handlers mutating the comment don't leak changes into other tests.
+	var comment Comment
+
+	switch id {
+	case 1:
+		comment = *mockComment
+	case 3:
+		comment = *mockReply
+	case 4:
+		comment = *mockDeletedComment
+	case 6:
+		comment = *mockPendingComment
+	case 7:
+		comment = *mockDraftPostComment
 	default:
 		return nil, ErrRecordNotFound
 	}
+
+	return &comment, nil
+}
+
+func (c MockCommentModel) GetWithUserName(id int64) (*Comment, *string, error) {
+	comment, err := c.Get(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userName := "Mocked User"
+
+	return comment, &userName, nil
 }
 
 func (c MockCommentModel) Insert(comment *Comment) error {
+	comment.ID = 5
+	if comment.ParentID != nil {
+		comment.Depth = 1
+	}
 	return nil
 }
 
+func (c MockCommentModel) Update(comment *Comment, editorID int64) error {
+	now := time.Now()
+	comment.EditedAt = &now
+	comment.Version++
+	return nil
+}
+
+func (c MockCommentModel) GetEditHistory(commentID int64) ([]*dto.CommentEditResponseBody, error) {
+	return []*dto.CommentEditResponseBody{{
+		Text:     "Mocked Comment Before Edit",
+		EditedBy: mockComment.CreatedBy,
+		UserName: "Mocked User",
+		EditedAt: time.Now(),
+	}}, nil
+}
+
 func (c MockCommentModel) Delete(id int64) error {
 	switch id {
 	case 1:
@@ -44,3 +157,37 @@ func (c MockCommentModel) Delete(id int64) error {
 		return ErrRecordNotFound
 	}
 }
+
+func (c MockCommentModel) AddLike(comment *Comment, userID int64) error {
+	comment.LikesCount++
+	return nil
+}
+
+func (c MockCommentModel) RemoveLike(comment *Comment, userID int64) error {
+	if comment.LikesCount > 0 {
+		comment.LikesCount--
+	}
+	return nil
+}
+
+func (c MockCommentModel) GetModerationQueue(status string, filters Filters) ([]*dto.CommentResponseBody, Metadata, error) {
+	if status != CommentStatusPending {
+		return []*dto.CommentResponseBody{}, Metadata{}, nil
+	}
+	return []*dto.CommentResponseBody{commentResponseBody(mockPendingComment, "Another User")}, mockMetadata, nil
+}
+
+func (c MockCommentModel) Moderate(ids []int64, status string, moderatorID int64) (int64, error) {
+	var updated int64
+	for _, id := range ids {
+		if id == mockPendingComment.ID {
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+// Only user 1 has had comments approved.
+func (c MockCommentModel) HasApproved(userID int64) (bool, error) {
+	return userID == mockComment.CreatedBy, nil
+}
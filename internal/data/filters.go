@@ -1,53 +1,129 @@
 package data
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math"
 	"strings"
 
 	"github.com/AthfanFasee/blog-post-backend/internal/validator"
 )
 
+var errInvalidCursor = errors.New("invalid cursor")
+
 type Filters struct {
 	ID           int
+	Tags         []string
+	TagMode      string
 	Page         int
 	Limit        int
 	Sort         string
 	SortSafeList []string
+	// Opaque cursors from a previous page's metadata. When either is set, Page is ignored.
+	After  string
+	Before string
+}
+
+// A sortable field of a listing. The cursor stores the sort key as text, so it's cast back to typ for comparing.
+type sortColumn struct {
+	expr string
+	typ  string
+}
+
+// Cursor marks the position of a row in a listing, by the sort it was read with, its sort key and its id.
+type Cursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   int64  `json:"i"`
+}
+
+func (c Cursor) encode() string {
+	js, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(js)
+}
+
+func decodeCursor(s string) (Cursor, error) {
+	var c Cursor
+
+	js, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, errInvalidCursor
+	}
+
+	err = json.Unmarshal(js, &c)
+	if err != nil || c.ID < 1 {
+		return c, errInvalidCursor
+	}
+
+	return c, nil
 }
 
-func (f Filters) sortParam() string {
-	var sortParam string
+// Look up the sort column in columns, which are keyed by the sort value without its '-' prefix.
+func (f Filters) sortColumn(columns map[string]sortColumn) sortColumn {
 	for _, safeValue := range f.SortSafeList {
 		if f.Sort == safeValue {
-			switch {
-			case f.Sort == "-likescount":
-				sortParam = "ARRAY_LENGTH(liked_by, 1)"
-				return sortParam
-			default:
-				sortParam = strings.TrimPrefix(f.Sort, "-")
-				return sortParam
+			if column, ok := columns[strings.TrimPrefix(f.Sort, "-")]; ok {
+				return column
 			}
 		}
 	}
 	panic("unsafe sort parameter: " + f.Sort)
 }
 
+// Paging backwards from a before cursor reads rows in the opposite order, the page is flipped back afterwards.
 func (f Filters) sortDirection() string {
-	switch {
-	case f.Sort == "-likescount":
-		return "DESC NULLS LAST"
-	case strings.HasPrefix(f.Sort, "-"):
+	desc := strings.HasPrefix(f.Sort, "-")
+	if f.Before != "" {
+		desc = !desc
+	}
+
+	if desc {
 		return "DESC"
-	default:
-		return "ASC"
 	}
+	return "ASC"
+}
+
+func (f Filters) usesCursor() bool {
+	return f.After != "" || f.Before != ""
+}
+
+func (f Filters) cursor() (Cursor, error) {
+	if f.After != "" {
+		return decodeCursor(f.After)
+	}
+	return decodeCursor(f.Before)
+}
+
+// Returns the condition selecting rows past the cursor in reading order, with the cursor key and id as parameters
+// $keyParam and $keyParam+1. It's empty when the listing is paged by offset.
+func (f Filters) cursorCondition(column sortColumn, idExpr string, keyParam int) string {
+	if !f.usesCursor() {
+		return ""
+	}
+
+	operator := ">"
+	if f.sortDirection() == "DESC" {
+		operator = "<"
+	}
+
+	return fmt.Sprintf("AND (%s, %s) %s (CAST($%d AS %s), $%d)", column.expr, idExpr, operator, keyParam, column.typ, keyParam+1)
 }
 
 func (f Filters) limit() int {
 	return f.Limit
 }
 
+// One extra row is read to find out whether there is a next page.
+func (f Filters) fetchLimit() int {
+	return f.Limit + 1
+}
+
 func (f Filters) offset() int {
+	if f.usesCursor() {
+		return 0
+	}
 	return (f.Page - 1) * f.Limit
 }
 
@@ -62,14 +138,24 @@ func ValidateFilters(v *validator.Validator, f Filters) {
 	// Check that the sort parameter matches a value in safelist
 	v.Check(validator.In(f.Sort, f.SortSafeList...), "sort", "invalid sort value")
 
+	if f.usesCursor() {
+		v.Check(f.After == "" || f.Before == "", "before", "cannot be used together with after")
+
+		// A cursor holds the sort key of the sort it was made for, which can't be compared with another sort's keys.
+		cursor, err := f.cursor()
+		v.Check(err == nil, "cursor", "must be a cursor from a previous page")
+		v.Check(err != nil || cursor.Sort == f.Sort, "cursor", "was made for a different sort")
+	}
 }
 
 type Metadata struct {
-	CurrentPage  int `json:"current_page,omitempty"`
-	PageSize     int `json:"page_size,omitempty"`
-	FirstPage    int `json:"first_page,omitempty"`
-	LastPage     int `json:"last_page,omitempty"`
-	TotalRecords int `json:"total_records,omitempty"`
+	CurrentPage  int    `json:"current_page,omitempty"`
+	PageSize     int    `json:"page_size,omitempty"`
+	FirstPage    int    `json:"first_page,omitempty"`
+	LastPage     int    `json:"last_page,omitempty"`
+	TotalRecords int    `json:"total_records,omitempty"`
+	NextCursor   string `json:"next_cursor,omitempty"`
+	PrevCursor   string `json:"prev_cursor,omitempty"`
 }
 
 func calculateMetadata(totalRecords, page, limit int) Metadata {
@@ -85,3 +171,49 @@ func calculateMetadata(totalRecords, page, limit int) Metadata {
 		TotalRecords: totalRecords,
 	}
 }
+
+// Works out the size of a page read with fetchLimit and its metadata. positions holds the sort key and id of every
+// row read, in reading order. Callers keep the first n rows and reverse them when paging backwards.
+//
+// Cursor pages skip counting the matching rows, so their metadata only has the page size and cursors.
+func (f Filters) calculatePage(positions []Cursor, totalRecords int) (n int, metadata Metadata) {
+	n = len(positions)
+	more := n > f.Limit
+	if more {
+		n = f.Limit
+	}
+
+	if f.usesCursor() {
+		metadata = Metadata{PageSize: f.Limit}
+	} else {
+		metadata = calculateMetadata(totalRecords, f.Page, f.Limit)
+	}
+
+	if n == 0 {
+		// Paging past either end, the cursor we came from leads back.
+		switch {
+		case f.After != "":
+			metadata.PrevCursor = f.After
+		case f.Before != "":
+			metadata.NextCursor = f.Before
+		}
+		return n, metadata
+	}
+
+	first, last := positions[0], positions[n-1]
+	if f.Before != "" {
+		first, last = last, first
+	}
+
+	first.Sort, last.Sort = f.Sort, f.Sort
+
+	if (f.Before == "" && more) || f.Before != "" {
+		metadata.NextCursor = last.encode()
+	}
+
+	if (f.Before != "" && more) || f.After != "" || f.offset() > 0 {
+		metadata.PrevCursor = first.encode()
+	}
+
+	return n, metadata
+}
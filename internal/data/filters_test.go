@@ -0,0 +1,90 @@
+package data
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+)
+
+func TestCalculatePage(t *testing.T) {
+	// Rows as read from the database, one more than the limit of 2.
+	positions := []Cursor{{Key: "5", ID: 5}, {Key: "4", ID: 4}, {Key: "3", ID: 3}}
+
+	cursorFor := func(id int64) string {
+		return Cursor{Sort: "-id", Key: positions[5-id].Key, ID: id}.encode()
+	}
+
+	tests := []struct {
+		name      string
+		filters   Filters
+		positions []Cursor
+		wantN     int
+		wantNext  string
+		wantPrev  string
+	}{
+		{
+			name:      "First page",
+			filters:   Filters{Page: 1, Limit: 2, Sort: "-id"},
+			positions: positions,
+			wantN:     2,
+			wantNext:  cursorFor(4),
+		},
+		{
+			name:      "Last offset page",
+			filters:   Filters{Page: 2, Limit: 2, Sort: "-id"},
+			positions: positions[2:],
+			wantN:     1,
+			wantPrev:  cursorFor(3),
+		},
+		{
+			name:      "After cursor with more rows",
+			filters:   Filters{Page: 1, Limit: 2, Sort: "-id", After: "after"},
+			positions: positions,
+			wantN:     2,
+			wantNext:  cursorFor(4),
+			wantPrev:  cursorFor(5),
+		},
+		{
+			name:      "Before cursor with more rows",
+			filters:   Filters{Page: 1, Limit: 2, Sort: "-id", Before: "before"},
+			positions: positions,
+			wantN:     2,
+			wantNext:  cursorFor(5),
+			wantPrev:  cursorFor(4),
+		},
+		{
+			name:      "Before cursor at the start",
+			filters:   Filters{Page: 1, Limit: 2, Sort: "-id", Before: "before"},
+			positions: positions[:1],
+			wantN:     1,
+			wantNext:  cursorFor(5),
+		},
+		{
+			name:     "After cursor past the end",
+			filters:  Filters{Page: 1, Limit: 2, Sort: "-id", After: "after"},
+			wantPrev: "after",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, metadata := tt.filters.calculatePage(tt.positions, 3)
+
+			assert.Equal(t, n, tt.wantN)
+			assert.Equal(t, metadata.NextCursor, tt.wantNext)
+			assert.Equal(t, metadata.PrevCursor, tt.wantPrev)
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	cursor := Cursor{Sort: "title", Key: "Go, \"quoted\" & more", ID: 7}
+
+	decoded, err := decodeCursor(cursor.encode())
+	assert.Equal(t, err == nil, true)
+	assert.Equal(t, decoded, cursor)
+
+	_, err = decodeCursor("bm90IGpzb24")
+	assert.Equal(t, errors.Is(err, errInvalidCursor), true)
+}
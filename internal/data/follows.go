@@ -0,0 +1,145 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+)
+
+type FollowModel struct {
+	DB *sql.DB
+}
+
+// Following a user twice has no effect. It returns ErrRecordNotFound when the followed user doesn't exist.
+func (m FollowModel) Insert(followerID int64, followeeID int64) error {
+	query := `
+	INSERT INTO follows (follower_id, followee_id)
+	VALUES ($1, $2)
+	ON CONFLICT DO NOTHING`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, followerID, followeeID)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: insert or update on table "follows" violates foreign key constraint "follows_followee_id_fkey"`:
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m FollowModel) Delete(followerID int64, followeeID int64) error {
+	query := `
+	DELETE FROM follows
+	WHERE follower_id = $1 AND followee_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, followerID, followeeID)
+
+	return err
+}
+
+// Users following userID, most recent follower first.
+func (m FollowModel) GetFollowers(userID int64, filters Filters) ([]*dto.FollowResponseBody, Metadata, error) {
+	query := `
+	SELECT count(*) OVER(), u.id, u.name, f.created_at
+	FROM follows f
+	INNER JOIN users u ON f.follower_id = u.id
+	WHERE f.followee_id = $1
+	ORDER BY f.created_at DESC, u.id DESC
+	LIMIT $2 OFFSET $3`
+
+	return m.getAll(query, userID, filters)
+}
+
+// Users userID follows, most recently followed first.
+func (m FollowModel) GetFollowing(userID int64, filters Filters) ([]*dto.FollowResponseBody, Metadata, error) {
+	query := `
+	SELECT count(*) OVER(), u.id, u.name, f.created_at
+	FROM follows f
+	INNER JOIN users u ON f.followee_id = u.id
+	WHERE f.follower_id = $1
+	ORDER BY f.created_at DESC, u.id DESC
+	LIMIT $2 OFFSET $3`
+
+	return m.getAll(query, userID, filters)
+}
+
+func (m FollowModel) getAll(query string, userID int64, filters Filters) ([]*dto.FollowResponseBody, Metadata, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	follows := []*dto.FollowResponseBody{}
+
+	for rows.Next() {
+		var follow dto.FollowResponseBody
+
+		err := rows.Scan(
+			&totalRecords,
+			&follow.UserID,
+			&follow.UserName,
+			&follow.FollowedAt,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		follows = append(follows, &follow)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.Limit)
+
+	return follows, metadata, nil
+}
+
+// Follower and following counts of a user, and whether viewerID follows them.
+func (m FollowModel) GetCounts(userID int64, viewerID int64) (*dto.FollowCountsResponseBody, error) {
+	if userID < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+	SELECT u.followers_count, u.following_count,
+	EXISTS(SELECT 1 FROM follows f WHERE f.follower_id = $2 AND f.followee_id = u.id)
+	FROM users u
+	WHERE u.id = $1`
+
+	var counts dto.FollowCountsResponseBody
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, userID, viewerID).Scan(&counts.Followers, &counts.Following, &counts.FollowedByMe)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &counts, nil
+}
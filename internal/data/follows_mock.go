@@ -0,0 +1,61 @@
+package data
+
+import (
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+)
+
+// User 2 follows user 1. Users above 4 don't exist.
+var mockFollow = &dto.FollowResponseBody{
+	UserID:     2,
+	UserName:   "Mocked User 2",
+	FollowedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+type MockFollowModel struct{}
+
+func (f MockFollowModel) Insert(followerID int64, followeeID int64) error {
+	if followeeID > 4 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (f MockFollowModel) Delete(followerID int64, followeeID int64) error {
+	return nil
+}
+
+func (f MockFollowModel) GetFollowers(userID int64, filters Filters) ([]*dto.FollowResponseBody, Metadata, error) {
+	if userID != 1 {
+		return []*dto.FollowResponseBody{}, Metadata{}, nil
+	}
+
+	return []*dto.FollowResponseBody{mockFollow}, mockMetadata, nil
+}
+
+func (f MockFollowModel) GetFollowing(userID int64, filters Filters) ([]*dto.FollowResponseBody, Metadata, error) {
+	if userID != 2 {
+		return []*dto.FollowResponseBody{}, Metadata{}, nil
+	}
+
+	return []*dto.FollowResponseBody{{
+		UserID:     1,
+		UserName:   "Mocked User",
+		FollowedAt: mockFollow.FollowedAt,
+	}}, mockMetadata, nil
+}
+
+func (f MockFollowModel) GetCounts(userID int64, viewerID int64) (*dto.FollowCountsResponseBody, error) {
+	switch userID {
+	case 1:
+		return &dto.FollowCountsResponseBody{Followers: 1, FollowedByMe: viewerID == 2}, nil
+	case 2:
+		return &dto.FollowCountsResponseBody{Following: 1}, nil
+	case 3, 4:
+		return &dto.FollowCountsResponseBody{}, nil
+	default:
+		return nil, ErrRecordNotFound
+	}
+}
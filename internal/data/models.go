@@ -14,48 +14,166 @@ var (
 )
 
 type Models struct {
+	Bookmarks interface {
+		Insert(userID int64, postID int64) error
+		Delete(userID int64, postID int64) error
+		GetAllForUser(userID int64, filters Filters) ([]*dto.PostResponseBody, Metadata, error)
+	}
 	Comments interface {
-		GetAllForPost(postID int64) ([]*dto.CommentResponseBody, error)
+		GetAllForPost(postID int64, viewerID int64, moderator bool, filters Filters) ([]*dto.CommentResponseBody, Metadata, error)
+		GetReplies(parentID int64, viewerID int64, moderator bool, after int64) ([]*dto.CommentResponseBody, bool, error)
+		GetModerationQueue(status string, filters Filters) ([]*dto.CommentResponseBody, Metadata, error)
+		Get(id int64) (*Comment, error)
+		GetWithUserName(id int64) (*Comment, *string, error)
 		Insert(comment *Comment) error
+		Update(comment *Comment, editorID int64) error
+		GetEditHistory(commentID int64) ([]*dto.CommentEditResponseBody, error)
 		Delete(id int64) error
+		AddLike(comment *Comment, userID int64) error
+		RemoveLike(comment *Comment, userID int64) error
+		Moderate(ids []int64, status string, moderatorID int64) (int64, error)
+		HasApproved(userID int64) (bool, error)
+	}
+	Follows interface {
+		Insert(followerID int64, followeeID int64) error
+		Delete(followerID int64, followeeID int64) error
+		GetFollowers(userID int64, filters Filters) ([]*dto.FollowResponseBody, Metadata, error)
+		GetFollowing(userID int64, filters Filters) ([]*dto.FollowResponseBody, Metadata, error)
+		GetCounts(userID int64, viewerID int64) (*dto.FollowCountsResponseBody, error)
+	}
+	PersonalTokens interface {
+		Insert(token *PersonalToken) error
+		GetAllForUser(userID int64) ([]*dto.PersonalTokenResponseBody, error)
+		Delete(userID int64, id int64) error
+		DeleteAllForUser(userID int64) error
+		GetUserForToken(tokenPlainText string) (*User, error)
+		Touch(tokenPlainText, ip string) error
 	}
 	Posts interface {
-		GetAll(title string, filters Filters) ([]*dto.PostResponseBody, Metadata, error)
+		GetAll(title, status string, viewerID int64, filters Filters) ([]*dto.PostResponseBody, Metadata, error)
+		GetFeed(userID int64, followedOnly bool, filters Filters) ([]*dto.PostResponseBody, Metadata, error)
+		Search(query string, filters Filters) ([]*dto.SearchResultResponseBody, Metadata, error)
 		Get(id int64) (*Post, error)
 		GetWithUserName(id int64) (*Post, *string, error)
+		GetBySlug(slug string) (*Post, *string, error)
 		Insert(post *Post) error
-		Update(post *Post) error
+		Update(post *Post, editorID int64) error
+		UpdateStatus(post *Post, status string, changedBy int64) error
+		SetCommentModeration(postID int64, mode *string) error
+		PublishDue(limit int) ([]*Post, error)
+		GetStatusHistory(postID int64) ([]*dto.PostStatusChangeResponseBody, error)
 		Delete(id int64) error
 		AddLike(post *Post, userID int64) error
 		RemoveLike(post *Post, userID int64) error
+		GetViewerState(post *Post, userID int64) error
+	}
+	PostRevisions interface {
+		GetAllForPost(postID int64) ([]*dto.PostRevisionResponseBody, error)
+		Get(postID int64, revision int32) (*PostRevision, error)
+	}
+	ReadingLists interface {
+		Insert(list *ReadingList) error
+		Get(id int64, viewerID int64) (*ReadingList, error)
+		GetAllForUser(userID int64, viewerID int64, publicOnly bool, filters Filters) ([]*dto.ReadingListResponseBody, Metadata, error)
+		Update(list *ReadingList) error
+		Delete(id int64) error
+		GetItems(listID int64, viewerID int64, filters Filters) ([]*dto.ReadingListItemResponseBody, Metadata, error)
+		GetItem(listID int64, postID int64) (*ReadingListItem, error)
+		AddItem(item *ReadingListItem) error
+		UpdateItem(item *ReadingListItem) error
+		RemoveItem(listID int64, postID int64) error
+	}
+	Reactions interface {
+		Insert(targetType string, targetID int64, userID int64, emoji string) error
+		Delete(targetType string, targetID int64, userID int64, emoji string) error
+		GetSummaries(targetType string, targetIDs []int64, userID int64) (map[int64]*dto.ReactionSummary, error)
+		GetAllForTarget(targetType string, targetID int64, emoji string, filters Filters) ([]*dto.ReactionResponseBody, Metadata, error)
+	}
+	Reports interface {
+		Insert(report *Report, threshold int) (bool, error)
+		GetAll(status, targetType string, filters Filters) ([]*dto.ReportResponseBody, Metadata, error)
+		Get(id int64) (*Report, error)
+		GetActions(targetType string, targetID int64) ([]*dto.ReportActionResponseBody, error)
+		Resolve(report *Report, action string, moderatorID int64, note string) error
+	}
+	RevokedSessions interface {
+		GetAll() (map[int64]time.Time, error)
+		DeleteExpired() error
+	}
+	Tags interface {
+		GetAll() ([]*dto.TagResponseBody, error)
 	}
 	Tokens interface {
 		Insert(token *Token) error
 		New(userID int64, timeToLive time.Duration, scope string) (*Token, error)
+		NewForEmail(userID int64, timeToLive time.Duration, scope string, email string) (*Token, error)
+		Get(scope, tokenPlainText string) (*Token, error)
 		DeleteAllForUser(scope string, userID int64) error
+		CountAttempt(scope, tokenPlainText string, maxAttempts int) (bool, error)
+		DeleteExpired() (int64, error)
+		NewSession(userID int64, accessTTL, refreshTTL time.Duration) (*Token, *Token, error)
+		Refresh(refreshPlainText string, accessTTL, refreshTTL time.Duration) (*Token, *Token, error)
+		Touch(tokenPlainText, ip, userAgent string) error
+		GetSessionsForUser(userID int64, current SessionRef) ([]*dto.SessionResponseBody, error)
+		DeleteSession(session SessionRef) error
+		DeleteSessionByID(userID int64, id int64) error
+		DeleteOtherSessions(userID int64, current SessionRef) (int64, error)
+	}
+	TwoFactor interface {
+		Get(userID int64) (*TwoFactor, error)
+		SetSecret(userID int64, secret string) error
+		Enable(userID int64, step int64, recoveryCodes []string) error
+		Disable(userID int64) error
+		UseStep(userID int64, step int64) (bool, error)
+		UseRecoveryCode(userID int64, code string) (bool, error)
+		AddFailedAttempt(userID int64) error
+		ReplaceRecoveryCodes(userID int64, recoveryCodes []string) error
 	}
 	Users interface {
 		Insert(user *User) error
+		Get(id int64) (*User, error)
 		GetByEmail(email string) (*User, error)
 		Update(user *User) error
 		GetForToken(tokenScope, tokenPlainText string) (*User, error)
+		GetProfile(userID int64) (*Profile, error)
+		UpdateProfile(profile *Profile) error
 	}
 }
 
 func NewModels(db *sql.DB) Models {
 	return Models{
-		Comments: CommentModel{DB: db},
-		Posts:    PostModel{DB: db},
-		Tokens:   TokenModel{DB: db},
-		Users:    UserModel{DB: db},
+		Bookmarks:       BookmarkModel{DB: db},
+		Comments:        CommentModel{DB: db},
+		Follows:         FollowModel{DB: db},
+		PersonalTokens:  PersonalTokenModel{DB: db},
+		Posts:           PostModel{DB: db},
+		PostRevisions:   PostRevisionModel{DB: db},
+		ReadingLists:    ReadingListModel{DB: db},
+		Reactions:       ReactionModel{DB: db},
+		Reports:         ReportModel{DB: db},
+		RevokedSessions: RevokedSessionModel{DB: db},
+		Tags:            TagModel{DB: db},
+		Tokens:          TokenModel{DB: db},
+		TwoFactor:       TwoFactorModel{DB: db},
+		Users:           UserModel{DB: db},
 	}
 }
 
 func NewMockModels() Models {
 	return Models{
-		Comments: MockCommentModel{},
-		Posts:    MockPostModel{},
-		Tokens:   MockTokenModel{},
-		Users:    MockUserModel{},
+		Bookmarks:       MockBookmarkModel{},
+		Comments:        MockCommentModel{},
+		Follows:         MockFollowModel{},
+		PersonalTokens:  MockPersonalTokenModel{},
+		Posts:           MockPostModel{},
+		PostRevisions:   MockPostRevisionModel{},
+		ReadingLists:    MockReadingListModel{},
+		Reactions:       MockReactionModel{},
+		Reports:         MockReportModel{},
+		RevokedSessions: MockRevokedSessionModel{},
+		Tags:            MockTagModel{},
+		Tokens:          MockTokenModel{},
+		TwoFactor:       MockTwoFactorModel{},
+		Users:           MockUserModel{},
 	}
 }
@@ -0,0 +1,55 @@
+package data
+
+import (
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+)
+
+// Roles a user can have. Every role is granted the permissions of the roles before it.
+const (
+	RoleReader = "reader"
+	RoleAuthor = "author"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+// Permission codes checked by the requirePermission middleware. Every role can read, the read permissions exist so
+// personal access tokens can be kept from reading anything but public content.
+const (
+	PermissionPostsRead       = "posts:read"
+	PermissionCommentsRead    = "comments:read"
+	PermissionCommentsWrite   = "comments:write"
+	PermissionPostsWrite      = "posts:write"
+	PermissionPostsPublish    = "posts:publish"
+	PermissionContentModerate = "content:moderate"
+)
+
+// Every permission code. Personal access tokens can be scoped to any of them.
+var AllPermissions = Permissions{PermissionPostsRead, PermissionCommentsRead, PermissionCommentsWrite, PermissionPostsWrite, PermissionPostsPublish, PermissionContentModerate}
+
+type Permissions []string
+
+// Returns true if the permission code is in the Permissions slice.
+func (p Permissions) Include(code string) bool {
+	for i := range p {
+		if code == p[i] {
+			return true
+		}
+	}
+	return false
+}
+
+var rolePermissions = map[string]Permissions{
+	RoleReader: {PermissionPostsRead, PermissionCommentsRead, PermissionCommentsWrite},
+	RoleAuthor: {PermissionPostsRead, PermissionCommentsRead, PermissionCommentsWrite, PermissionPostsWrite},
+	RoleEditor: {PermissionPostsRead, PermissionCommentsRead, PermissionCommentsWrite, PermissionPostsWrite, PermissionPostsPublish, PermissionContentModerate},
+	RoleAdmin:  {PermissionPostsRead, PermissionCommentsRead, PermissionCommentsWrite, PermissionPostsWrite, PermissionPostsPublish, PermissionContentModerate},
+}
+
+// Returns the permissions granted to a role. Unknown roles get no permissions.
+func PermissionsForRole(role string) Permissions {
+	return rolePermissions[role]
+}
+
+func ValidateRole(v *validator.Validator, role string) {
+	v.Check(validator.In(role, RoleReader, RoleAuthor, RoleEditor, RoleAdmin), "role", "invalid role")
+}
@@ -0,0 +1,249 @@
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+	"github.com/lib/pq"
+)
+
+// Personal access tokens start with a prefix, so they can be told apart from session tokens and spotted if leaked.
+const PersonalTokenPrefix = "pat_"
+
+var ErrDuplicateTokenName = errors.New("duplicate personal access token name")
+
+type PersonalToken struct {
+	ID        int64
+	UserID    int64
+	Name      string
+	Plaintext string
+	Hash      []byte
+	Scopes    Permissions
+	CreatedAt time.Time
+	// Nil for tokens that never expire.
+	Expiry *time.Time
+}
+
+func IsPersonalToken(tokenPlainText string) bool {
+	return strings.HasPrefix(tokenPlainText, PersonalTokenPrefix)
+}
+
+type PersonalTokenModel struct {
+	DB *sql.DB
+}
+
+// Generate the token's plaintext and insert it to db. The plaintext isn't stored, it's only available on the token.
+func (m PersonalTokenModel) Insert(token *PersonalToken) error {
+	// Generate the random part the same way as other tokens, with the prefix on top.
+	generated, err := generateToken(token.UserID, 0, "")
+	if err != nil {
+		return err
+	}
+
+	token.Plaintext = PersonalTokenPrefix + generated.Plaintext
+
+	hash := sha256.Sum256([]byte(token.Plaintext))
+	token.Hash = hash[:]
+
+	query := `
+	INSERT INTO personal_access_tokens (user_id, name, hash, scopes, expiry)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING id, created_at`
+
+	args := []interface{}{token.UserID, token.Name, token.Hash, pq.Array([]string(token.Scopes)), token.Expiry}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err = m.DB.QueryRowContext(ctx, query, args...).Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "personal_access_tokens_user_id_name_key"`:
+			return ErrDuplicateTokenName
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Personal access tokens of the user ordered by name, including expired ones so users can see why a script stopped working.
+func (m PersonalTokenModel) GetAllForUser(userID int64) ([]*dto.PersonalTokenResponseBody, error) {
+	query := `
+	SELECT id, name, scopes, created_at, expiry, last_used_at, last_used_ip
+	FROM personal_access_tokens
+	WHERE user_id = $1
+	ORDER BY name, id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tokens := []*dto.PersonalTokenResponseBody{}
+
+	for rows.Next() {
+		var token dto.PersonalTokenResponseBody
+
+		err := rows.Scan(
+			&token.ID,
+			&token.Name,
+			pq.Array(&token.Scopes),
+			&token.CreatedAt,
+			&token.Expiry,
+			&token.LastUsedAt,
+			&token.LastUsedIP,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, &token)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Delete a personal access token of the user. Tokens of other users are reported as not found.
+func (m PersonalTokenModel) Delete(userID int64, id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+	DELETE FROM personal_access_tokens
+	WHERE id = $1 AND user_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m PersonalTokenModel) DeleteAllForUser(userID int64) error {
+	query := `
+	DELETE FROM personal_access_tokens
+	WHERE user_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, userID)
+
+	return err
+}
+
+// Get the user of an unexpired personal access token, with the token's scopes set.
+func (m PersonalTokenModel) GetUserForToken(tokenPlainText string) (*User, error) {
+	query := `
+	SELECT users.id, users.created_at, users.name, users.email, users.password_hash, users.activated, users.role, users.version,
+	personal_access_tokens.scopes
+	FROM users
+	INNER JOIN personal_access_tokens
+	ON users.id = personal_access_tokens.user_id
+	WHERE personal_access_tokens.hash = $1
+	AND (personal_access_tokens.expiry IS NULL OR personal_access_tokens.expiry > $2)`
+
+	tokenHash := sha256.Sum256([]byte(tokenPlainText))
+
+	var user User
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, tokenHash[:], time.Now()).Scan(
+		&user.ID,
+		&user.CreatedAt,
+		&user.Name,
+		&user.Email,
+		&user.Password.hash,
+		&user.Activated,
+		&user.Role,
+		&user.Version,
+		pq.Array((*[]string)(&user.Scopes)),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &user, nil
+}
+
+// Record that the token was just used, and from where. Like sessions, requests in quick succession only write once a minute.
+func (m PersonalTokenModel) Touch(tokenPlainText, ip string) error {
+	query := `
+	UPDATE personal_access_tokens
+	SET last_used_at = NOW(), last_used_ip = $2
+	WHERE hash = $1
+	AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute' OR last_used_ip <> $2)`
+
+	tokenHash := sha256.Sum256([]byte(tokenPlainText))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, tokenHash[:], ip)
+
+	return err
+}
+
+func ValidatePersonalTokenPlainText(v *validator.Validator, tokenPlainText string) {
+	v.Check(len(tokenPlainText) == len(PersonalTokenPrefix)+26, "token", "must be 30 bytes long")
+}
+
+// Tokens can only be scoped to permissions the user's role grants.
+func ValidatePersonalToken(v *validator.Validator, token *PersonalToken, role string) {
+	v.Check(token.Name != "", "name", "must be provided")
+	v.Check(len(token.Name) <= 100, "name", "must not be more than 100 bytes long")
+
+	v.Check(len(token.Scopes) != 0, "scopes", "must contain at least one scope")
+	v.Check(validator.Unique(token.Scopes), "scopes", "must not contain duplicate values")
+
+	for _, scope := range token.Scopes {
+		switch {
+		case !AllPermissions.Include(scope):
+			v.AddError("scopes", "must only contain valid scopes: "+strings.Join(AllPermissions, ", "))
+		case !PermissionsForRole(role).Include(scope):
+			v.AddError("scopes", "your role doesn't grant the "+scope+" permission")
+		}
+	}
+
+	if token.Expiry != nil {
+		v.Check(token.Expiry.After(time.Now()), "expiry", "must be in the future")
+	}
+}
@@ -0,0 +1,72 @@
+package data
+
+import (
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+)
+
+// User 1 has token 1, scoped to posts:write. Every personal access token belongs to mocked user 1 with that scope.
+var mockPersonalToken = &dto.PersonalTokenResponseBody{
+	ID:        1,
+	Name:      "Mocked CI",
+	Scopes:    []string{PermissionPostsWrite},
+	CreatedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+type MockPersonalTokenModel struct {
+	MockInsert           func(token *PersonalToken) error
+	MockGetUserForToken  func(tokenPlainText string) (*User, error)
+	MockDeleteAllForUser func(userID int64) error
+}
+
+func (m MockPersonalTokenModel) Insert(token *PersonalToken) error {
+	if m.MockInsert != nil {
+		return m.MockInsert(token)
+	}
+
+	token.ID = 2
+	token.Plaintext = PersonalTokenPrefix + GenerateTestToken()
+	token.CreatedAt = time.Now()
+
+	return nil
+}
+
+func (m MockPersonalTokenModel) GetAllForUser(userID int64) ([]*dto.PersonalTokenResponseBody, error) {
+	if userID != 1 {
+		return []*dto.PersonalTokenResponseBody{}, nil
+	}
+
+	return []*dto.PersonalTokenResponseBody{mockPersonalToken}, nil
+}
+
+func (m MockPersonalTokenModel) Delete(userID int64, id int64) error {
+	if userID != 1 || id != mockPersonalToken.ID {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m MockPersonalTokenModel) DeleteAllForUser(userID int64) error {
+	if m.MockDeleteAllForUser != nil {
+		return m.MockDeleteAllForUser(userID)
+	}
+
+	return nil
+}
+
+func (m MockPersonalTokenModel) GetUserForToken(tokenPlainText string) (*User, error) {
+	if m.MockGetUserForToken != nil {
+		return m.MockGetUserForToken(tokenPlainText)
+	}
+
+	user := *mockUser
+	user.Scopes = Permissions{PermissionPostsWrite}
+
+	return &user, nil
+}
+
+func (m MockPersonalTokenModel) Touch(tokenPlainText, ip string) error {
+	return nil
+}
@@ -0,0 +1,119 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+)
+
+type PostRevision struct {
+	ID        int64
+	PostID    int64
+	Revision  int32
+	Title     string
+	PostText  string
+	CreatedBy int64
+	CreatedAt time.Time
+}
+
+type PostRevisionModel struct {
+	DB *sql.DB
+}
+
+// Store the current title and text of a post as its next revision.
+// It is called by PostModel within the same transaction that writes the post.
+func insertPostRevision(ctx context.Context, tx *sql.Tx, post *Post, createdBy int64) error {
+	query := `
+	INSERT INTO post_revisions (post_id, revision, title, post_text, created_by)
+	SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4
+	FROM post_revisions
+	WHERE post_id = $1`
+
+	_, err := tx.ExecContext(ctx, query, post.ID, post.Title, post.PostText, createdBy)
+	return err
+}
+
+// Get the revisions of a post, newest first. Texts are left out to keep the list small.
+func (p PostRevisionModel) GetAllForPost(postID int64) ([]*dto.PostRevisionResponseBody, error) {
+	query := `
+	SELECT r.revision, r.title, COALESCE(r.created_by, 0), COALESCE(u.name, ''), r.created_at
+	FROM post_revisions r
+	LEFT JOIN users u ON r.created_by = u.id
+	WHERE r.post_id = $1
+	ORDER BY r.revision DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, query, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	revisions := []*dto.PostRevisionResponseBody{}
+
+	for rows.Next() {
+		var revision dto.PostRevisionResponseBody
+
+		err := rows.Scan(
+			&revision.Revision,
+			&revision.Title,
+			&revision.CreatedBy,
+			&revision.UserName,
+			&revision.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, &revision)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (p PostRevisionModel) Get(postID int64, revision int32) (*PostRevision, error) {
+	if revision < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+	SELECT id, post_id, revision, title, post_text, COALESCE(created_by, 0), created_at
+	FROM post_revisions
+	WHERE post_id = $1 AND revision = $2`
+
+	var postRevision PostRevision
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := p.DB.QueryRowContext(ctx, query, postID, revision).Scan(
+		&postRevision.ID,
+		&postRevision.PostID,
+		&postRevision.Revision,
+		&postRevision.Title,
+		&postRevision.PostText,
+		&postRevision.CreatedBy,
+		&postRevision.CreatedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &postRevision, nil
+}
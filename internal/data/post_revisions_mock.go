@@ -0,0 +1,40 @@
+package data
+
+import (
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+)
+
+var mockPostRevision = &PostRevision{
+	ID:        1,
+	PostID:    1,
+	Revision:  1,
+	Title:     "Mocked Revision Title",
+	PostText:  "Mocked Revision PostText",
+	CreatedBy: 1,
+	CreatedAt: time.Now(),
+}
+
+type MockPostRevisionModel struct{}
+
+func (p MockPostRevisionModel) GetAllForPost(postID int64) ([]*dto.PostRevisionResponseBody, error) {
+	return []*dto.PostRevisionResponseBody{
+		{
+			Revision:  mockPostRevision.Revision,
+			Title:     mockPostRevision.Title,
+			CreatedBy: mockPostRevision.CreatedBy,
+			UserName:  "Mocked User",
+			CreatedAt: mockPostRevision.CreatedAt,
+		},
+	}, nil
+}
+
+func (p MockPostRevisionModel) Get(postID int64, revision int32) (*PostRevision, error) {
+	switch {
+	case postID == 1 && revision == 1:
+		return mockPostRevision, nil
+	default:
+		return nil, ErrRecordNotFound
+	}
+}
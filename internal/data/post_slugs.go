@@ -0,0 +1,136 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/slug"
+)
+
+// Slug used for posts whose title has no letters or digits which can be turned into a slug.
+const defaultPostSlug = "post"
+
+func postSlugBase(title string) string {
+	base := slug.Make(title)
+	if base == "" {
+		return defaultPostSlug
+	}
+
+	// Keep slugs reasonably short, without leaving a trailing dash behind.
+	if len(base) > 80 {
+		base = strings.TrimRight(base[:80], "-")
+	}
+
+	return base
+}
+
+// Returns true if postSlug is base itself or base with a numeric collision suffix.
+func hasSlugBase(postSlug, base string) bool {
+	if postSlug == base {
+		return true
+	}
+
+	suffix := strings.TrimPrefix(postSlug, base+"-")
+	if suffix == postSlug {
+		return false
+	}
+
+	_, err := strconv.Atoi(suffix)
+	return err == nil
+}
+
+// Give a renamed post a unique slug generated from its new title, unless its current slug already matches the title.
+// Old slugs stay in post_slugs so they keep pointing at the post. It is called by PostModel within the
+// same transaction that updates the post.
+func setPostSlug(ctx context.Context, tx *sql.Tx, post *Post) error {
+	base := postSlugBase(post.Title)
+
+	if post.Slug != "" && hasSlugBase(post.Slug, base) {
+		return nil
+	}
+
+	postSlug, err := uniquePostSlug(ctx, tx, post.ID, base)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE posts SET slug = $1 WHERE id = $2`, postSlug, post.ID)
+	if err != nil {
+		return err
+	}
+
+	err = insertPostSlug(ctx, tx, postSlug, post.ID)
+	if err != nil {
+		return err
+	}
+
+	post.Slug = postSlug
+
+	return nil
+}
+
+// Record a slug in the post's slug history. A slug the post used before is already there.
+func insertPostSlug(ctx context.Context, tx *sql.Tx, postSlug string, postID int64) error {
+	query := `
+	INSERT INTO post_slugs (slug, post_id)
+	VALUES ($1, $2)
+	ON CONFLICT (slug) DO NOTHING`
+
+	_, err := tx.ExecContext(ctx, query, postSlug, postID)
+	return err
+}
+
+// Find the first free slug among base, base-2, base-3... A slug the post used before counts as free.
+// New posts pass a postID of 0.
+func uniquePostSlug(ctx context.Context, tx *sql.Tx, postID int64, base string) (string, error) {
+	// Serialize slug generation for the same base until the transaction ends, so concurrent inserts don't pick the same suffix.
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, base)
+	if err != nil {
+		return "", err
+	}
+
+	// A base only contains letters, digits and dashes, so it is safe to use inside the regular expression.
+	query := `
+	SELECT slug, post_id
+	FROM post_slugs
+	WHERE slug = $1 OR slug ~ ('^' || $1 || '-[0-9]+$')`
+
+	rows, err := tx.QueryContext(ctx, query, base)
+	if err != nil {
+		return "", err
+	}
+
+	defer rows.Close()
+
+	taken := make(map[string]bool)
+
+	for rows.Next() {
+		var takenSlug string
+		var takenBy int64
+
+		err := rows.Scan(&takenSlug, &takenBy)
+		if err != nil {
+			return "", err
+		}
+
+		taken[takenSlug] = takenBy != postID
+	}
+
+	if err = rows.Err(); err != nil {
+		return "", err
+	}
+
+	if !taken[base] {
+		return base, nil
+	}
+
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", base, i)
+		if !taken[candidate] {
+			return candidate, nil
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"html"
+	"strings"
 	"time"
 
 	"github.com/AthfanFasee/blog-post-backend/internal/dto"
@@ -12,37 +14,127 @@ import (
 	"github.com/lib/pq"
 )
 
+// Post lifecycle statuses. Only published posts are visible to everyone.
+const (
+	PostStatusDraft     = "draft"
+	PostStatusInReview  = "in_review"
+	PostStatusScheduled = "scheduled"
+	PostStatusPublished = "published"
+	PostStatusArchived  = "archived"
+)
+
+// Allowed status transitions, keyed by the current status.
+var postStatusTransitions = map[string][]string{
+	PostStatusDraft:     {PostStatusInReview},
+	PostStatusInReview:  {PostStatusDraft, PostStatusScheduled, PostStatusPublished},
+	PostStatusScheduled: {PostStatusDraft, PostStatusPublished},
+	PostStatusPublished: {PostStatusArchived},
+	PostStatusArchived:  {PostStatusDraft},
+}
+
 type Post struct {
-	ID        int64
-	CreatedAt time.Time
-	Title     string
-	PostText  string
-	Img       string
-	ReadTime  dto.ReadTime
-	LikedBy   []int64
-	CreatedBy int64
-	Version   int32
+	ID         int64
+	CreatedAt  time.Time
+	Title      string
+	Slug       string
+	PostText   string
+	Img        string
+	ReadTime   dto.ReadTime
+	LikesCount int
+	// Whether the user reading the post likes and has bookmarked it. Only set when there is such a user.
+	LikedByMe      bool
+	BookmarkedByMe bool
+	Tags           []string
+	CreatedBy      int64
+	Status         string
+	PublishAt      *time.Time
+	PublishedAt    *time.Time
+	CommentsCount  int
+	// Overrides the global comment moderation mode when set.
+	CommentModeration *string
+	// Set when the post was hidden because of reports.
+	Hidden  bool
+	Version int32
+}
+
+// Scheduled posts become public as soon as they are due, even before the scheduler has promoted them.
+// Hidden posts are never public.
+func (p *Post) IsPublic() bool {
+	if p.Hidden {
+		return false
+	}
+
+	switch p.Status {
+	case PostStatusPublished:
+		return true
+	case PostStatusScheduled:
+		return p.PublishAt != nil && !p.PublishAt.After(time.Now())
+	default:
+		return false
+	}
+}
+
+// Public posts are visible to everyone, other posts only to users who can modify them and, when using a personal
+// access token, have the posts:read scope.
+func (p *Post) VisibleTo(user *User) bool {
+	return p.IsPublic() || (user.HasPermission(PermissionPostsRead) && user.CanModify(p.CreatedBy))
 }
 
 type PostModel struct {
 	DB *sql.DB
 }
 
-func (p PostModel) GetAll(title string, filters Filters) ([]*dto.PostResponseBody, Metadata, error) {
+// Sortable fields of the post listing, keyed by their sort value.
+var postSortColumns = map[string]sortColumn{
+	"id":         {expr: "p.id", typ: "bigint"},
+	"title":      {expr: "p.title", typ: "text"},
+	"readtime":   {expr: "p.read_time", typ: "integer"},
+	"likescount": {expr: "p.likes_count", typ: "integer"},
+}
+
+// Published (and due scheduled) posts are returned to everyone. Other posts are only returned to the user who created them.
+// When status is set, every post with that status is returned instead, so callers must check the user may see them.
+// Pages are read by offset, or after/before a cursor when the filters have one.
+func (p PostModel) GetAll(title, status string, viewerID int64, filters Filters) ([]*dto.PostResponseBody, Metadata, error) {
+	sortColumn := filters.sortColumn(postSortColumns)
+
+	// Counting every matching post is what makes deep offset pages slow, so cursor pages skip it.
+	count := "count(*) OVER()"
+	if filters.usesCursor() {
+		count = "0"
+	}
+
 	// Get post data along with name of the user who created it
 	query := fmt.Sprintf(`
-	SELECT count(*) OVER(), p.id, p.title, p.post_text, p.img, p.read_time, p.liked_by, p.created_by, p.created_at, u.name
+	SELECT %s, %s::text, p.id, p.title, p.slug, p.post_text, p.img, p.read_time, p.likes_count, p.created_by, p.created_at, p.status, p.publish_at, p.published_at, p.comments_count, p.hidden, u.name,
+	EXISTS(SELECT 1 FROM post_likes pl WHERE pl.post_id = p.id AND pl.user_id = $3),
+	EXISTS(SELECT 1 FROM bookmarks b WHERE b.post_id = p.id AND b.user_id = $3),
+	ARRAY(SELECT t.name FROM post_tags pt INNER JOIN tags t ON pt.tag_id = t.id WHERE pt.post_id = p.id ORDER BY t.name)
 	FROM posts p
 	INNER JOIN users u ON p.created_by = u.id
 	WHERE (to_tsvector('english', title) @@ plainto_tsquery('english', $1) OR $1 = '')
 	AND (created_by = $2 OR $2 = 0)
-	ORDER BY %s %s, id %s
-	LIMIT $3 OFFSET $4`, filters.sortParam(), filters.sortDirection(), filters.sortDirection())
+	AND (p.status = $8 OR ($8 = '' AND ((NOT p.hidden AND (p.status = 'published' OR (p.status = 'scheduled' AND p.publish_at <= NOW()))) OR p.created_by = $3)))
+	AND (cardinality($6::text[]) = 0 OR (
+		SELECT count(*) FROM post_tags pt INNER JOIN tags t ON pt.tag_id = t.id
+		WHERE pt.post_id = p.id AND t.name = ANY($6)
+	) >= CASE WHEN $7 = 'all' THEN cardinality($6::text[]) ELSE 1 END)
+	%s
+	ORDER BY %s %s, p.id %s
+	LIMIT $4 OFFSET $5`, count, sortColumn.expr, filters.cursorCondition(sortColumn, "p.id", 9), sortColumn.expr, filters.sortDirection(), filters.sortDirection())
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	args := []interface{}{title, filters.ID, filters.limit(), filters.offset()}
+	args := []interface{}{title, filters.ID, viewerID, filters.fetchLimit(), filters.offset(), pq.Array(filters.Tags), filters.TagMode, status}
+
+	if filters.usesCursor() {
+		cursor, err := filters.cursor()
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		args = append(args, cursor.Key, cursor.ID)
+	}
 
 	rows, err := p.DB.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -53,59 +145,274 @@ func (p PostModel) GetAll(title string, filters Filters) ([]*dto.PostResponseBod
 
 	totalRecords := 0
 	posts := []*dto.PostResponseBody{}
+	positions := []Cursor{}
 
 	for rows.Next() {
 		var post Post
 		var userName string
+		var sortKey string
 		err := rows.Scan(
 			&totalRecords,
+			&sortKey,
 			&post.ID,
 			&post.Title,
+			&post.Slug,
 			&post.PostText,
 			&post.Img,
 			&post.ReadTime,
-			pq.Array(&post.LikedBy),
+			&post.LikesCount,
 			&post.CreatedBy,
 			&post.CreatedAt,
+			&post.Status,
+			&post.PublishAt,
+			&post.PublishedAt,
+			&post.CommentsCount,
+			&post.Hidden,
 			&userName,
+			&post.LikedByMe,
+			&post.BookmarkedByMe,
+			pq.Array(&post.Tags),
 		)
 		if err != nil {
 			return nil, Metadata{}, err
 		}
 
 		PostResponseBody := dto.PostResponseBody{
-			ID:        post.ID,
-			Title:     post.Title,
-			PostText:  post.PostText,
-			Img:       post.Img,
-			ReadTime:  post.ReadTime,
-			LikedBy:   post.LikedBy,
-			CreatedAt: post.CreatedAt,
-			CreatedBy: post.CreatedBy,
-			UserName:  userName,
+			ID:             post.ID,
+			Title:          post.Title,
+			Slug:           post.Slug,
+			PostText:       post.PostText,
+			Img:            post.Img,
+			ReadTime:       post.ReadTime,
+			LikesCount:     post.LikesCount,
+			LikedByMe:      post.LikedByMe,
+			BookmarkedByMe: post.BookmarkedByMe,
+			Tags:           post.Tags,
+			Status:         post.Status,
+			PublishAt:      post.PublishAt,
+			PublishedAt:    post.PublishedAt,
+			CommentsCount:  post.CommentsCount,
+			Hidden:         post.Hidden,
+			CreatedAt:      post.CreatedAt,
+			CreatedBy:      post.CreatedBy,
+			UserName:       userName,
 		}
 
 		posts = append(posts, &PostResponseBody)
+		positions = append(positions, Cursor{Key: sortKey, ID: post.ID})
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, Metadata{}, err
 	}
 
-	metadata := calculateMetadata(totalRecords, filters.Page, filters.Limit)
+	n, metadata := filters.calculatePage(positions, totalRecords)
+	posts = posts[:n]
+
+	if filters.Before != "" {
+		for i, j := 0, len(posts)-1; i < j; i, j = i+1, j-1 {
+			posts[i], posts[j] = posts[j], posts[i]
+		}
+	}
 
 	return posts, metadata, nil
 }
 
+// Sortable fields of the home feed. Posts are dated by when they went public.
+var feedSortColumns = map[string]sortColumn{
+	"published":  {expr: "COALESCE(p.published_at, p.publish_at, p.created_at)", typ: "timestamptz"},
+	"likescount": {expr: "p.likes_count", typ: "integer"},
+}
+
+// Public posts for the home feed of userID. With followedOnly set it only returns posts from authors the user follows.
+// Pages are read by offset, or after/before a cursor when the filters have one.
+func (p PostModel) GetFeed(userID int64, followedOnly bool, filters Filters) ([]*dto.PostResponseBody, Metadata, error) {
+	sortColumn := filters.sortColumn(feedSortColumns)
+
+	count := "count(*) OVER()"
+	if filters.usesCursor() {
+		count = "0"
+	}
+
+	query := fmt.Sprintf(`
+	SELECT %s, %s::text, p.id, p.title, p.slug, p.post_text, p.img, p.read_time, p.likes_count, p.created_by, p.created_at, p.status, p.publish_at, p.published_at, p.comments_count, p.hidden, u.name,
+	EXISTS(SELECT 1 FROM post_likes pl WHERE pl.post_id = p.id AND pl.user_id = $1),
+	EXISTS(SELECT 1 FROM bookmarks b WHERE b.post_id = p.id AND b.user_id = $1),
+	ARRAY(SELECT t.name FROM post_tags pt INNER JOIN tags t ON pt.tag_id = t.id WHERE pt.post_id = p.id ORDER BY t.name)
+	FROM posts p
+	INNER JOIN users u ON p.created_by = u.id
+	WHERE NOT p.hidden AND (p.status = 'published' OR (p.status = 'scheduled' AND p.publish_at <= NOW()))
+	AND (NOT $2 OR p.created_by IN (SELECT followee_id FROM follows WHERE follower_id = $1))
+	%s
+	ORDER BY %s %s, p.id %s
+	LIMIT $3 OFFSET $4`, count, sortColumn.expr, filters.cursorCondition(sortColumn, "p.id", 5), sortColumn.expr, filters.sortDirection(), filters.sortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := []interface{}{userID, followedOnly, filters.fetchLimit(), filters.offset()}
+
+	if filters.usesCursor() {
+		cursor, err := filters.cursor()
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		args = append(args, cursor.Key, cursor.ID)
+	}
+
+	rows, err := p.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	posts := []*dto.PostResponseBody{}
+	positions := []Cursor{}
+
+	for rows.Next() {
+		var post dto.PostResponseBody
+		var sortKey string
+		err := rows.Scan(
+			&totalRecords,
+			&sortKey,
+			&post.ID,
+			&post.Title,
+			&post.Slug,
+			&post.PostText,
+			&post.Img,
+			&post.ReadTime,
+			&post.LikesCount,
+			&post.CreatedBy,
+			&post.CreatedAt,
+			&post.Status,
+			&post.PublishAt,
+			&post.PublishedAt,
+			&post.CommentsCount,
+			&post.Hidden,
+			&post.UserName,
+			&post.LikedByMe,
+			&post.BookmarkedByMe,
+			pq.Array(&post.Tags),
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		posts = append(posts, &post)
+		positions = append(positions, Cursor{Key: sortKey, ID: post.ID})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	n, metadata := filters.calculatePage(positions, totalRecords)
+	posts = posts[:n]
+
+	if filters.Before != "" {
+		for i, j := 0, len(posts)-1; i < j; i, j = i+1, j-1 {
+			posts[i], posts[j] = posts[j], posts[i]
+		}
+	}
+
+	return posts, metadata, nil
+}
+
+// ts_headline marks matches with control characters that the posts are stripped of. html.EscapeString never produces
+// them, so the headline can be escaped first and the marks turned into <mark> tags afterwards.
+const (
+	highlightStart = "\x01"
+	highlightStop  = "\x02"
+)
+
+var highlightReplacer = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+func highlightHTML(headline string) string {
+	return highlightReplacer.Replace(html.EscapeString(headline))
+}
+
+// Search public posts by title, tags, text and author name, in that order of weight. The query must be in to_tsquery syntax.
+// Results are ordered by relevance and highlighted with ts_headline, which only runs on the rows of the requested page.
+func (p PostModel) Search(query string, filters Filters) ([]*dto.SearchResultResponseBody, Metadata, error) {
+	stmt := `
+	SELECT total, id, ts_headline('english', title, q, E'HighlightAll=true, StartSel=\x01, StopSel=\x02'), slug,
+	ts_headline('english', post_text, q, E'StartSel=\x01, StopSel=\x02, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" ... "'),
+	img, read_time, tags, rank, published_at, created_by, name
+	FROM (
+		SELECT count(*) OVER() AS total, p.id, translate(p.title, E'\x01\x02', '') AS title, p.slug,
+		translate(p.post_text, E'\x01\x02', '') AS post_text, p.img, p.read_time, p.published_at, p.created_by, u.name, q,
+		ARRAY(SELECT t.name FROM post_tags pt INNER JOIN tags t ON pt.tag_id = t.id WHERE pt.post_id = p.id ORDER BY t.name) AS tags,
+		ts_rank_cd(p.search_vector, q) AS rank
+		FROM posts p
+		INNER JOIN users u ON p.created_by = u.id
+		CROSS JOIN to_tsquery('english', $1) q
+		WHERE p.search_vector @@ q
+		AND NOT p.hidden AND (p.status = 'published' OR (p.status = 'scheduled' AND p.publish_at <= NOW()))
+		ORDER BY rank DESC, p.id DESC
+		LIMIT $2 OFFSET $3
+	) AS results
+	ORDER BY rank DESC, id DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, stmt, query, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	results := []*dto.SearchResultResponseBody{}
+
+	for rows.Next() {
+		var result dto.SearchResultResponseBody
+		err := rows.Scan(
+			&totalRecords,
+			&result.ID,
+			&result.Title,
+			&result.Slug,
+			&result.Snippet,
+			&result.Img,
+			&result.ReadTime,
+			pq.Array(&result.Tags),
+			&result.Rank,
+			&result.PublishedAt,
+			&result.CreatedBy,
+			&result.UserName,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		result.Title = highlightHTML(result.Title)
+		result.Snippet = highlightHTML(result.Snippet)
+
+		results = append(results, &result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.Limit)
+
+	return results, metadata, nil
+}
+
 func (p PostModel) Get(id int64) (*Post, error) {
 	if id < 1 {
 		return nil, ErrRecordNotFound
 	}
 
 	query := `
-	SELECT id, title, post_text, img, read_time, liked_by, created_by, created_at, version
-	FROM posts
-	WHERE id = $1`
+	SELECT p.id, p.title, p.slug, p.post_text, p.img, p.read_time, p.likes_count, p.created_by, p.created_at, p.status, p.publish_at, p.published_at, p.comments_count, p.comment_moderation, p.hidden, p.version,
+	ARRAY(SELECT t.name FROM post_tags pt INNER JOIN tags t ON pt.tag_id = t.id WHERE pt.post_id = p.id ORDER BY t.name)
+	FROM posts p
+	WHERE p.id = $1`
 
 	var post Post
 
@@ -115,13 +422,21 @@ func (p PostModel) Get(id int64) (*Post, error) {
 	err := p.DB.QueryRowContext(ctx, query, id).Scan(
 		&post.ID,
 		&post.Title,
+		&post.Slug,
 		&post.PostText,
 		&post.Img,
 		&post.ReadTime,
-		pq.Array(&post.LikedBy),
+		&post.LikesCount,
 		&post.CreatedBy,
 		&post.CreatedAt,
+		&post.Status,
+		&post.PublishAt,
+		&post.PublishedAt,
+		&post.CommentsCount,
+		&post.CommentModeration,
+		&post.Hidden,
 		&post.Version,
+		pq.Array(&post.Tags),
 	)
 
 	if err != nil {
@@ -142,7 +457,8 @@ func (p PostModel) GetWithUserName(id int64) (*Post, *string, error) {
 	}
 
 	query := `
-	SELECT p.id, p.title, p.post_text, p.img, p.read_time, p.liked_by, p.created_by, p.created_at, u.name
+	SELECT p.id, p.title, p.slug, p.post_text, p.img, p.read_time, p.likes_count, p.created_by, p.created_at, p.status, p.publish_at, p.published_at, p.comments_count, p.hidden, p.version, u.name,
+	ARRAY(SELECT t.name FROM post_tags pt INNER JOIN tags t ON pt.tag_id = t.id WHERE pt.post_id = p.id ORDER BY t.name)
 	FROM posts p
 	INNER JOIN users u ON p.created_by = u.id
 	WHERE p.id = $1`
@@ -156,13 +472,21 @@ func (p PostModel) GetWithUserName(id int64) (*Post, *string, error) {
 	err := p.DB.QueryRowContext(ctx, query, id).Scan(
 		&post.ID,
 		&post.Title,
+		&post.Slug,
 		&post.PostText,
 		&post.Img,
 		&post.ReadTime,
-		pq.Array(&post.LikedBy),
+		&post.LikesCount,
 		&post.CreatedBy,
 		&post.CreatedAt,
+		&post.Status,
+		&post.PublishAt,
+		&post.PublishedAt,
+		&post.CommentsCount,
+		&post.Hidden,
+		&post.Version,
 		&userName,
+		pq.Array(&post.Tags),
 	)
 
 	if err != nil {
@@ -177,25 +501,109 @@ func (p PostModel) GetWithUserName(id int64) (*Post, *string, error) {
 	return &post, &userName, nil
 }
 
-func (p PostModel) Insert(post *Post) error {
+// Get a post by any slug it ever had, along with the name of the user who created it.
+// The returned post carries its current slug, which differs from the given slug when the post was renamed.
+func (p PostModel) GetBySlug(postSlug string) (*Post, *string, error) {
 	query := `
-		INSERT INTO posts (title, post_text, img, read_time, created_by) 
-		VALUES ($1, $2, $3, $4, $5) 
-		RETURNING id, created_at`
+	SELECT p.id, p.title, p.slug, p.post_text, p.img, p.read_time, p.likes_count, p.created_by, p.created_at, p.status, p.publish_at, p.published_at, p.comments_count, p.hidden, p.version, u.name,
+	ARRAY(SELECT t.name FROM post_tags pt INNER JOIN tags t ON pt.tag_id = t.id WHERE pt.post_id = p.id ORDER BY t.name)
+	FROM post_slugs ps
+	INNER JOIN posts p ON ps.post_id = p.id
+	INNER JOIN users u ON p.created_by = u.id
+	WHERE ps.slug = $1`
 
-	args := []interface{}{post.Title, post.PostText, post.Img, post.ReadTime, post.CreatedBy}
+	var post Post
+	var userName string
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	return p.DB.QueryRowContext(ctx, query, args...).Scan(&post.ID, &post.CreatedAt)
+	err := p.DB.QueryRowContext(ctx, query, postSlug).Scan(
+		&post.ID,
+		&post.Title,
+		&post.Slug,
+		&post.PostText,
+		&post.Img,
+		&post.ReadTime,
+		&post.LikesCount,
+		&post.CreatedBy,
+		&post.CreatedAt,
+		&post.Status,
+		&post.PublishAt,
+		&post.PublishedAt,
+		&post.CommentsCount,
+		&post.Hidden,
+		&post.Version,
+		&userName,
+		pq.Array(&post.Tags),
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, nil, ErrRecordNotFound
+		default:
+			return nil, nil, err
+		}
+	}
+
+	return &post, &userName, nil
 }
 
-func (p PostModel) Update(post *Post) error {
+// Insert a post along with its slug, tags and first revision.
+func (p PostModel) Insert(post *Post) error {
+	query := `
+		INSERT INTO posts (title, slug, post_text, img, read_time, created_by) 
+		VALUES ($1, $2, $3, $4, $5, $6) 
+		RETURNING id, created_at, status, version`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	post.Slug, err = uniquePostSlug(ctx, tx, 0, postSlugBase(post.Title))
+	if err != nil {
+		return err
+	}
+
+	args := []interface{}{post.Title, post.Slug, post.PostText, post.Img, post.ReadTime, post.CreatedBy}
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&post.ID, &post.CreatedAt, &post.Status, &post.Version)
+	if err != nil {
+		return err
+	}
+
+	err = insertPostSlug(ctx, tx, post.Slug, post.ID)
+	if err != nil {
+		return err
+	}
+
+	err = setPostTags(ctx, tx, post.ID, post.Tags)
+	if err != nil {
+		return err
+	}
+
+	err = insertPostRevision(ctx, tx, post, post.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Update a post, its slug and its tags and store its new title and text as a revision made by editorID, all in a single transaction.
+func (p PostModel) Update(post *Post, editorID int64) error {
 	query := `
 	UPDATE posts
 	SET title = $1, post_text = $2, img = $3, read_time = $4, version = version + 1
-	WHERE id = $5 AND version = $6`
+	WHERE id = $5 AND version = $6
+	RETURNING version`
 
 	args := []interface{}{
 		post.Title,
@@ -209,23 +617,198 @@ func (p PostModel) Update(post *Post) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	result, err := p.DB.ExecContext(ctx, query, args...)
+	tx, err := p.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&post.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	err = setPostSlug(ctx, tx, post)
 	if err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return ErrEditConflict
+	err = setPostTags(ctx, tx, post.ID, post.Tags)
+	if err != nil {
+		return err
+	}
+
+	err = insertPostRevision(ctx, tx, post, editorID)
+	if err != nil {
+		return err
 	}
 
+	return tx.Commit()
+}
+
+// Move a post to a new status and record who made the change, both in a single transaction.
+// post.PublishAt is only kept when the post is being scheduled.
+func (p PostModel) UpdateStatus(post *Post, status string, changedBy int64) error {
+	query := `
+	UPDATE posts
+	SET status = $1,
+	published_at = CASE WHEN $1 = 'published' THEN NOW() ELSE published_at END,
+	publish_at = CASE WHEN $1 = 'scheduled' THEN $4::timestamptz ELSE NULL END,
+	version = version + 1
+	WHERE id = $2 AND version = $3
+	RETURNING publish_at, published_at, version`
+
+	historyQuery := `
+	INSERT INTO post_status_history (post_id, from_status, to_status, changed_by)
+	VALUES ($1, $2, $3, $4)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// Rollback is a no-op once the transaction has been committed.
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query, status, post.ID, post.Version, post.PublishAt).Scan(&post.PublishAt, &post.PublishedAt, &post.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, historyQuery, post.ID, post.Status, status, changedBy)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	post.Status = status
+
 	return nil
 }
 
+// Publish scheduled posts which are due. Rows locked by another replica are skipped, so running the
+// scheduler on several API instances never publishes the same post twice.
+func (p PostModel) PublishDue(limit int) ([]*Post, error) {
+	query := `
+	WITH due AS (
+		SELECT id FROM posts
+		WHERE status = 'scheduled' AND publish_at <= NOW()
+		ORDER BY publish_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	), published AS (
+		UPDATE posts p
+		SET status = 'published', published_at = p.publish_at, publish_at = NULL, version = p.version + 1
+		FROM due
+		WHERE p.id = due.id
+		RETURNING p.id, p.title, p.created_by, p.published_at, p.version
+	), history AS (
+		INSERT INTO post_status_history (post_id, from_status, to_status)
+		SELECT id, 'scheduled', 'published' FROM published
+	)
+	SELECT id, title, created_by, published_at, version FROM published`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	posts := []*Post{}
+
+	for rows.Next() {
+		post := Post{Status: PostStatusPublished}
+
+		err := rows.Scan(
+			&post.ID,
+			&post.Title,
+			&post.CreatedBy,
+			&post.PublishedAt,
+			&post.Version,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		posts = append(posts, &post)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+func (p PostModel) GetStatusHistory(postID int64) ([]*dto.PostStatusChangeResponseBody, error) {
+	query := `
+	SELECT h.from_status, h.to_status, h.changed_by, COALESCE(u.name, ''), h.changed_at
+	FROM post_status_history h
+	LEFT JOIN users u ON h.changed_by = u.id
+	WHERE h.post_id = $1
+	ORDER BY h.id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, query, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	changes := []*dto.PostStatusChangeResponseBody{}
+
+	for rows.Next() {
+		var change dto.PostStatusChangeResponseBody
+		var changedBy sql.NullInt64
+
+		err := rows.Scan(
+			&change.FromStatus,
+			&change.ToStatus,
+			&changedBy,
+			&change.UserName,
+			&change.ChangedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		change.ChangedBy = changedBy.Int64
+
+		changes = append(changes, &change)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
 func (p PostModel) Delete(id int64) error {
 	if id < 1 {
 		return ErrRecordNotFound
@@ -255,30 +838,66 @@ func (p PostModel) Delete(id int64) error {
 	return nil
 }
 
+// Like the post as the user, at most once, and set the post's LikesCount and LikedByMe from the result.
 func (p PostModel) AddLike(post *Post, userID int64) error {
-	// This SQL statement will prevent a user from liking a post twice
 	query := `
-	UPDATE posts SET 
-	liked_by = (select array_agg(distinct x) from unnest(array_append(liked_by, $1)) t(x))
-	WHERE id = $2
-	RETURNING liked_by`
+	WITH liked AS (
+		INSERT INTO post_likes (post_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+		RETURNING post_id
+	)
+	SELECT likes_count + (SELECT count(*) FROM liked)
+	FROM posts
+	WHERE id = $1`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	return p.DB.QueryRowContext(ctx, query, userID, post.ID).Scan(pq.Array(&post.LikedBy))
+	err := p.DB.QueryRowContext(ctx, query, post.ID, userID).Scan(&post.LikesCount)
+	if err != nil {
+		return err
+	}
+
+	post.LikedByMe = true
+
+	return nil
 }
 
 func (p PostModel) RemoveLike(post *Post, userID int64) error {
 	query := `
-	UPDATE posts SET liked_by = array_remove(liked_by, $1)
-	WHERE id = $2
-	RETURNING liked_by`
+	WITH unliked AS (
+		DELETE FROM post_likes
+		WHERE post_id = $1 AND user_id = $2
+		RETURNING post_id
+	)
+	SELECT likes_count - (SELECT count(*) FROM unliked)
+	FROM posts
+	WHERE id = $1`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	return p.DB.QueryRowContext(ctx, query, userID, post.ID).Scan(pq.Array(&post.LikedBy))
+	err := p.DB.QueryRowContext(ctx, query, post.ID, userID).Scan(&post.LikesCount)
+	if err != nil {
+		return err
+	}
+
+	post.LikedByMe = false
+
+	return nil
+}
+
+// Fill in whether the user likes and has bookmarked the post.
+func (p PostModel) GetViewerState(post *Post, userID int64) error {
+	query := `
+	SELECT EXISTS(SELECT 1 FROM post_likes WHERE post_id = $1 AND user_id = $2),
+	EXISTS(SELECT 1 FROM bookmarks WHERE post_id = $1 AND user_id = $2)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return p.DB.QueryRowContext(ctx, query, post.ID, userID).Scan(&post.LikedByMe, &post.BookmarkedByMe)
 }
 
 func ValidatePost(v *validator.Validator, post *Post) {
@@ -291,3 +910,22 @@ func ValidatePost(v *validator.Validator, post *Post) {
 	v.Check(post.ReadTime != 0, "readTime", "Read time must be provided")
 	v.Check(post.ReadTime > 0, "readTime", "Read time must be provided")
 }
+
+// Returns true if a post in the from status can be moved to the to status.
+func CanTransitionPost(from, to string) bool {
+	return validator.In(to, postStatusTransitions[from]...)
+}
+
+func ValidatePostTransition(v *validator.Validator, from, to string) {
+	v.Check(CanTransitionPost(from, to), "status", fmt.Sprintf("a %s post cannot be moved to %s", from, to))
+}
+
+// Posts can only be listed by status to find the ones waiting for review.
+func ValidatePostStatusFilter(v *validator.Validator, status string) {
+	v.Check(validator.In(status, "", PostStatusInReview), "status", "must be in_review")
+}
+
+func ValidatePublishAt(v *validator.Validator, publishAt *time.Time) {
+	v.Check(publishAt != nil, "publishAt", "must be provided")
+	v.Check(publishAt == nil || publishAt.After(time.Now()), "publishAt", "must be in the future")
+}
@@ -4,17 +4,48 @@ import (
 	"time"
 
 	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
 )
 
 var mockPost = Post{
-	ID:        1,
+	ID:            1,
+	CreatedAt:     time.Now(),
+	Title:         "Mocked Post Title",
+	Slug:          "mocked-post-title",
+	PostText:      "Mocked Post PostText",
+	Img:           "Mocked Post Img",
+	ReadTime:      1,
+	LikesCount:    2,
+	Tags:          []string{"go"},
+	CreatedBy:     1,
+	Status:        PostStatusPublished,
+	CommentsCount: 1,
+	Version:       1,
+}
+
+var mockDraftPost = Post{
+	ID:        3,
+	CreatedAt: time.Now(),
+	Title:     "Mocked Draft Title",
+	Slug:      "mocked-draft-title",
+	PostText:  "Mocked Draft PostText",
+	Img:       "Mocked Draft Img",
+	ReadTime:  1,
+	CreatedBy: 1,
+	Status:    PostStatusDraft,
+	Version:   1,
+}
+
+var mockInReviewPost = Post{
+	ID:        4,
 	CreatedAt: time.Now(),
-	Title:     "Mocked Post Title",
-	PostText:  "Mocked Post PostText",
-	Img:       "Mocked Post Img",
+	Title:     "Mocked In Review Title",
+	Slug:      "mocked-in-review-title",
+	PostText:  "Mocked In Review PostText",
+	Img:       "Mocked In Review Img",
 	ReadTime:  1,
-	LikedBy:   []int64{1, 2},
 	CreatedBy: 1,
+	Status:    PostStatusInReview,
 	Version:   1,
 }
 
@@ -27,55 +58,138 @@ var mockMetadata = Metadata{
 }
 
 var mockPostResponseBody = &dto.PostResponseBody{
-	ID:        mockPost.ID,
-	Title:     mockPost.Title,
-	PostText:  mockPost.PostText,
-	Img:       mockPost.Img,
-	ReadTime:  mockPost.ReadTime,
-	LikedBy:   mockPost.LikedBy,
-	CreatedBy: mockComment.CreatedBy,
-	UserName:  "Mocked User",
+	ID:         mockPost.ID,
+	Title:      mockPost.Title,
+	Slug:       mockPost.Slug,
+	PostText:   mockPost.PostText,
+	Img:        mockPost.Img,
+	ReadTime:   mockPost.ReadTime,
+	LikesCount: mockPost.LikesCount,
+	Tags:       mockPost.Tags,
+	Status:     mockPost.Status,
+	CreatedBy:  mockComment.CreatedBy,
+	UserName:   "Mocked User",
 }
 
 var mockPostResponseBodyDifferentTitle = &dto.PostResponseBody{
-	ID:        mockPost.ID,
-	Title:     "Title",
-	PostText:  mockPost.PostText,
-	Img:       mockPost.Img,
-	ReadTime:  mockPost.ReadTime,
-	LikedBy:   mockPost.LikedBy,
-	CreatedBy: mockComment.CreatedBy,
-	UserName:  "Mocked User",
+	ID:         mockPost.ID,
+	Title:      "Title",
+	PostText:   mockPost.PostText,
+	Img:        mockPost.Img,
+	ReadTime:   mockPost.ReadTime,
+	LikesCount: mockPost.LikesCount,
+	Tags:       mockPost.Tags,
+	Status:     mockPost.Status,
+	CreatedBy:  mockComment.CreatedBy,
+	UserName:   "Mocked User",
+}
+
+type MockPostModel struct {
+	MockUpdateStatus func(post *Post, status string, changedBy int64) error
 }
 
-type MockPostModel struct{}
+func (p MockPostModel) GetAll(title, status string, viewerID int64, filters Filters) ([]*dto.PostResponseBody, Metadata, error) {
+	// Return copies so handlers filling in the posts don't leak changes into other tests.
+	post := *mockPostResponseBody
+	postDifferentTitle := *mockPostResponseBodyDifferentTitle
 
-func (p MockPostModel) GetAll(title string, filters Filters) ([]*dto.PostResponseBody, Metadata, error) {
 	switch {
+	case status == PostStatusInReview:
+		inReview := dto.PostResponseBody{
+			ID:        mockInReviewPost.ID,
+			Title:     mockInReviewPost.Title,
+			Slug:      mockInReviewPost.Slug,
+			PostText:  mockInReviewPost.PostText,
+			Img:       mockInReviewPost.Img,
+			ReadTime:  mockInReviewPost.ReadTime,
+			Status:    mockInReviewPost.Status,
+			CreatedBy: mockInReviewPost.CreatedBy,
+			UserName:  "Mocked User",
+		}
+		return []*dto.PostResponseBody{&inReview}, mockMetadata, nil
+	case validator.In("unknown", filters.Tags...):
+		return []*dto.PostResponseBody{}, Metadata{}, nil
 	case title == "title":
-		return []*dto.PostResponseBody{mockPostResponseBodyDifferentTitle}, mockMetadata, nil
+		return []*dto.PostResponseBody{&postDifferentTitle}, mockMetadata, nil
 	case title != "invalid" && filters.ID == 1:
-		return []*dto.PostResponseBody{mockPostResponseBody}, mockMetadata, nil
+		return []*dto.PostResponseBody{&post}, mockMetadata, nil
 	case title == "":
-		return []*dto.PostResponseBody{mockPostResponseBody}, mockMetadata, nil
+		return []*dto.PostResponseBody{&post}, mockMetadata, nil
 	default:
 		return nil, Metadata{}, ErrRecordNotFound
 	}
 }
 
+func (p MockPostModel) GetFeed(userID int64, followedOnly bool, filters Filters) ([]*dto.PostResponseBody, Metadata, error) {
+	post := *mockPostResponseBody
+
+	return []*dto.PostResponseBody{&post}, mockMetadata, nil
+}
+
+func (p MockPostModel) Search(query string, filters Filters) ([]*dto.SearchResultResponseBody, Metadata, error) {
+	if query != "mocked" {
+		return []*dto.SearchResultResponseBody{}, Metadata{}, nil
+	}
+
+	return []*dto.SearchResultResponseBody{{
+		ID:        mockPost.ID,
+		Title:     "<mark>Mocked</mark> Post Title",
+		Slug:      mockPost.Slug,
+		Snippet:   "<mark>Mocked</mark> post text",
+		Img:       mockPost.Img,
+		ReadTime:  mockPost.ReadTime,
+		Tags:      mockPost.Tags,
+		Rank:      0.1,
+		CreatedBy: mockPost.CreatedBy,
+		UserName:  "Mocked User",
+	}}, mockMetadata, nil
+}
+
 func (p MockPostModel) Get(id int64) (*Post, error) {
+	// Return a copy so handlers mutating the post don't leak changes into other tests.
+	post := mockPost
+	draft := mockDraftPost
+	inReview := mockInReviewPost
+
 	switch id {
 	case 1:
-		return &mockPost, nil
+		return &post, nil
+	case 3:
+		return &draft, nil
+	case 4:
+		return &inReview, nil
 	default:
 		return nil, ErrRecordNotFound
 	}
 }
 
 func (p MockPostModel) GetWithUserName(id int64) (*Post, *string, error) {
+	post := mockPost
+	draft := mockDraftPost
+	inReview := mockInReviewPost
+
 	switch id {
 	case 1:
-		return &mockPost, &mockPostResponseBody.UserName, nil
+		return &post, &mockPostResponseBody.UserName, nil
+	case 3:
+		return &draft, &mockPostResponseBody.UserName, nil
+	case 4:
+		return &inReview, &mockPostResponseBody.UserName, nil
+	default:
+		return nil, nil, ErrRecordNotFound
+	}
+}
+
+// Mocked post 1 was renamed from "Old Mocked Post Title", so its old slug still resolves.
+func (p MockPostModel) GetBySlug(slug string) (*Post, *string, error) {
+	post := mockPost
+	draft := mockDraftPost
+
+	switch slug {
+	case "mocked-post-title", "old-mocked-post-title":
+		return &post, &mockPostResponseBody.UserName, nil
+	case "mocked-draft-title":
+		return &draft, &mockPostResponseBody.UserName, nil
 	default:
 		return nil, nil, ErrRecordNotFound
 	}
@@ -86,10 +200,41 @@ func (p MockPostModel) Insert(post *Post) error {
 }
 
 // Consider testing race condition (the errEditConflict error case) in future
-func (p MockPostModel) Update(post *Post) error {
+func (p MockPostModel) Update(post *Post, editorID int64) error {
+	return nil
+}
+
+func (p MockPostModel) UpdateStatus(post *Post, status string, changedBy int64) error {
+	if p.MockUpdateStatus != nil {
+		return p.MockUpdateStatus(post, status, changedBy)
+	}
+
+	post.Status = status
 	return nil
 }
 
+func (p MockPostModel) SetCommentModeration(postID int64, mode *string) error {
+	switch postID {
+	case 1, 3:
+		return nil
+	default:
+		return ErrRecordNotFound
+	}
+}
+
+func (p MockPostModel) PublishDue(limit int) ([]*Post, error) {
+	publishedAt := time.Now()
+
+	post := mockPost
+	post.PublishedAt = &publishedAt
+
+	return []*Post{&post}, nil
+}
+
+func (p MockPostModel) GetStatusHistory(postID int64) ([]*dto.PostStatusChangeResponseBody, error) {
+	return []*dto.PostStatusChangeResponseBody{}, nil
+}
+
 func (p MockPostModel) Delete(id int64) error {
 	switch id {
 	case 1:
@@ -100,9 +245,24 @@ func (p MockPostModel) Delete(id int64) error {
 }
 
 func (p MockPostModel) AddLike(post *Post, userID int64) error {
+	if !post.LikedByMe {
+		post.LikesCount++
+	}
+	post.LikedByMe = true
 	return nil
 }
 
 func (p MockPostModel) RemoveLike(post *Post, userID int64) error {
+	if post.LikedByMe {
+		post.LikesCount--
+	}
+	post.LikedByMe = false
+	return nil
+}
+
+// Users 1 and 2 like mocked post 1, user 2 has bookmarked it.
+func (p MockPostModel) GetViewerState(post *Post, userID int64) error {
+	post.LikedByMe = post.ID == mockPost.ID && (userID == 1 || userID == 2)
+	post.BookmarkedByMe = post.ID == mockPost.ID && userID == 2
 	return nil
 }
@@ -0,0 +1,48 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+)
+
+func TestHighlightHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		headline string
+		want     string
+	}{
+		{"Plain text", "learning \x01go\x02 fast", "learning <mark>go</mark> fast"},
+		{"Script tag", "<script>alert(1)</script> \x01go\x02", "&lt;script&gt;alert(1)&lt;/script&gt; <mark>go</mark>"},
+		{"Markup in a match", "\x01<img src=x onerror=alert(1)>\x02", "<mark>&lt;img src=x onerror=alert(1)&gt;</mark>"},
+		{"Fake mark tags", "<mark>go</mark>", "&lt;mark&gt;go&lt;/mark&gt;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, highlightHTML(tt.headline), tt.want)
+		})
+	}
+}
+
+func TestPostVisibleTo(t *testing.T) {
+	draft := &Post{Status: PostStatusDraft, CreatedBy: 1}
+
+	tests := []struct {
+		name string
+		user *User
+		want bool
+	}{
+		{"Owner", &User{ID: 1, Role: RoleAuthor}, true},
+		{"Other user", &User{ID: 2, Role: RoleAuthor}, false},
+		{"Moderator", &User{ID: 2, Role: RoleEditor}, true},
+		{"Token with read scope", &User{ID: 1, Role: RoleAuthor, Scopes: Permissions{PermissionPostsRead}}, true},
+		{"Token without read scope", &User{ID: 1, Role: RoleAuthor, Scopes: Permissions{PermissionPostsWrite}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, draft.VisibleTo(tt.user), tt.want)
+		})
+	}
+}
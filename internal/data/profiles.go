@@ -0,0 +1,145 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+)
+
+// Sites users can link to from their profile, keyed in the social links by these names.
+var SocialPlatforms = []string{"github", "twitter", "linkedin", "mastodon", "youtube", "instagram"}
+
+// The public part of a user along with their author stats. It never holds the user's email.
+// The stats only count public posts, and are read-only.
+type Profile struct {
+	UserID        int64
+	CreatedAt     time.Time
+	Name          string
+	DisplayName   string
+	Bio           string
+	AvatarURL     string
+	Website       string
+	SocialLinks   map[string]string
+	PostsCount    int
+	LikesReceived int
+	Followers     int
+	Following     int
+	Version       int32
+}
+
+func (u UserModel) GetProfile(userID int64) (*Profile, error) {
+	if userID < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+	SELECT u.id, u.created_at, u.name, u.display_name, u.bio, u.avatar_url, u.website, u.social_links, u.followers_count, u.following_count, u.version,
+	count(p.id), COALESCE(sum(p.likes_count), 0)
+	FROM users u
+	LEFT JOIN posts p ON p.created_by = u.id
+	AND NOT p.hidden AND (p.status = 'published' OR (p.status = 'scheduled' AND p.publish_at <= NOW()))
+	WHERE u.id = $1
+	GROUP BY u.id`
+
+	var profile Profile
+	var socialLinks []byte
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := u.DB.QueryRowContext(ctx, query, userID).Scan(
+		&profile.UserID,
+		&profile.CreatedAt,
+		&profile.Name,
+		&profile.DisplayName,
+		&profile.Bio,
+		&profile.AvatarURL,
+		&profile.Website,
+		&socialLinks,
+		&profile.Followers,
+		&profile.Following,
+		&profile.Version,
+		&profile.PostsCount,
+		&profile.LikesReceived,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	err = json.Unmarshal(socialLinks, &profile.SocialLinks)
+	if err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// Only the editable profile fields are saved. The user's version guards against concurrent edits of the user.
+func (u UserModel) UpdateProfile(profile *Profile) error {
+	socialLinks, err := json.Marshal(profile.SocialLinks)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	UPDATE users
+	SET display_name = $1, bio = $2, avatar_url = $3, website = $4, social_links = $5, version = version + 1
+	WHERE id = $6 AND version = $7
+	RETURNING version`
+
+	args := []interface{}{
+		profile.DisplayName,
+		profile.Bio,
+		profile.AvatarURL,
+		profile.Website,
+		socialLinks,
+		profile.UserID,
+		profile.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err = u.DB.QueryRowContext(ctx, query, args...).Scan(&profile.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// The avatar, website and social links can be left empty, but must be http or https URLs otherwise.
+func ValidateProfile(v *validator.Validator, profile *Profile) {
+	v.Check(len(profile.DisplayName) <= 100, "displayName", "must not be more than 100 bytes long")
+	v.Check(len(profile.Bio) <= 1000, "bio", "must not be more than 1000 bytes long")
+
+	v.Check(len(profile.AvatarURL) <= 500, "avatarUrl", "must not be more than 500 bytes long")
+	v.Check(profile.AvatarURL == "" || validator.URL(profile.AvatarURL), "avatarUrl", "must be a valid http or https URL")
+
+	v.Check(len(profile.Website) <= 500, "website", "must not be more than 500 bytes long")
+	v.Check(profile.Website == "" || validator.URL(profile.Website), "website", "must be a valid http or https URL")
+
+	for platform, link := range profile.SocialLinks {
+		key := fmt.Sprintf("socialLinks.%s", platform)
+
+		v.Check(validator.In(platform, SocialPlatforms...), key, fmt.Sprintf("must be one of %s", strings.Join(SocialPlatforms, ", ")))
+		v.Check(len(link) <= 500, key, "must not be more than 500 bytes long")
+		v.Check(validator.URL(link), key, "must be a valid http or https URL")
+	}
+}
@@ -0,0 +1,176 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+	"github.com/lib/pq"
+)
+
+// Kinds of content users can react to.
+const (
+	ReactionTargetPost    = "post"
+	ReactionTargetComment = "comment"
+)
+
+// Emojis users can react with unless the server is configured with another set.
+var DefaultReactions = []string{"👍", "❤️", "🎉", "🤔"}
+
+// Table and target column holding the reactions to each kind of content. Only these names are ever put into queries.
+var reactionTables = map[string]struct{ table, column string }{
+	ReactionTargetPost:    {table: "post_reactions", column: "post_id"},
+	ReactionTargetComment: {table: "comment_reactions", column: "comment_id"},
+}
+
+type ReactionModel struct {
+	DB *sql.DB
+}
+
+// Reacting twice with the same emoji has no effect. A user can react with several different emojis.
+func (m ReactionModel) Insert(targetType string, targetID int64, userID int64, emoji string) error {
+	t := reactionTables[targetType]
+
+	query := fmt.Sprintf(`
+	INSERT INTO %s (%s, user_id, emoji)
+	VALUES ($1, $2, $3)
+	ON CONFLICT DO NOTHING`, t.table, t.column)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, targetID, userID, emoji)
+
+	return err
+}
+
+func (m ReactionModel) Delete(targetType string, targetID int64, userID int64, emoji string) error {
+	t := reactionTables[targetType]
+
+	query := fmt.Sprintf(`
+	DELETE FROM %s
+	WHERE %s = $1 AND user_id = $2 AND emoji = $3`, t.table, t.column)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, targetID, userID, emoji)
+
+	return err
+}
+
+// Reaction counts of several posts or comments at once, along with the emojis userID reacted with.
+// Every target gets a summary, even when nobody reacted to it.
+func (m ReactionModel) GetSummaries(targetType string, targetIDs []int64, userID int64) (map[int64]*dto.ReactionSummary, error) {
+	t := reactionTables[targetType]
+
+	query := fmt.Sprintf(`
+	SELECT %[2]s, emoji, count(*), bool_or(user_id = $2)
+	FROM %[1]s
+	WHERE %[2]s = ANY($1)
+	GROUP BY %[2]s, emoji
+	ORDER BY %[2]s, emoji`, t.table, t.column)
+
+	summaries := make(map[int64]*dto.ReactionSummary, len(targetIDs))
+	for _, id := range targetIDs {
+		summaries[id] = &dto.ReactionSummary{Counts: map[string]int{}, Mine: []string{}}
+	}
+
+	if len(targetIDs) == 0 {
+		return summaries, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(targetIDs), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var targetID int64
+		var emoji string
+		var count int
+		var mine bool
+
+		err := rows.Scan(&targetID, &emoji, &count, &mine)
+		if err != nil {
+			return nil, err
+		}
+
+		summary := summaries[targetID]
+		summary.Counts[emoji] = count
+		if mine {
+			summary.Mine = append(summary.Mine, emoji)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+// Users who reacted to a post or comment, newest reaction first. An empty emoji lists every reaction.
+func (m ReactionModel) GetAllForTarget(targetType string, targetID int64, emoji string, filters Filters) ([]*dto.ReactionResponseBody, Metadata, error) {
+	t := reactionTables[targetType]
+
+	query := fmt.Sprintf(`
+	SELECT count(*) OVER(), r.user_id, u.name, r.emoji, r.created_at
+	FROM %s r
+	INNER JOIN users u ON r.user_id = u.id
+	WHERE r.%s = $1
+	AND (r.emoji = $2 OR $2 = '')
+	ORDER BY r.id DESC
+	LIMIT $3 OFFSET $4`, t.table, t.column)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, targetID, emoji, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	reactions := []*dto.ReactionResponseBody{}
+
+	for rows.Next() {
+		var reaction dto.ReactionResponseBody
+
+		err := rows.Scan(
+			&totalRecords,
+			&reaction.UserID,
+			&reaction.UserName,
+			&reaction.Emoji,
+			&reaction.CreatedAt,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		reactions = append(reactions, &reaction)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.Limit)
+
+	return reactions, metadata, nil
+}
+
+func ValidateReaction(v *validator.Validator, emoji string, allowed []string) {
+	v.Check(validator.In(emoji, allowed...), "emoji", "must be one of "+strings.Join(allowed, " "))
+}
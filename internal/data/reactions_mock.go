@@ -0,0 +1,63 @@
+package data
+
+import (
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+)
+
+// Users 1 and 2 reacted to mocked post 1 with a thumbs up, user 1 to mocked comment 1 with a heart.
+var mockReactions = map[string]map[int64][]*dto.ReactionResponseBody{
+	ReactionTargetPost: {
+		1: {
+			{UserID: 2, UserName: "Another User", Emoji: "👍", CreatedAt: time.Now()},
+			{UserID: 1, UserName: "Mocked User", Emoji: "👍", CreatedAt: time.Now()},
+		},
+	},
+	ReactionTargetComment: {
+		1: {
+			{UserID: 1, UserName: "Mocked User", Emoji: "❤️", CreatedAt: time.Now()},
+		},
+	},
+}
+
+type MockReactionModel struct{}
+
+func (r MockReactionModel) Insert(targetType string, targetID int64, userID int64, emoji string) error {
+	return nil
+}
+
+func (r MockReactionModel) Delete(targetType string, targetID int64, userID int64, emoji string) error {
+	return nil
+}
+
+func (r MockReactionModel) GetSummaries(targetType string, targetIDs []int64, userID int64) (map[int64]*dto.ReactionSummary, error) {
+	summaries := make(map[int64]*dto.ReactionSummary, len(targetIDs))
+
+	for _, id := range targetIDs {
+		summary := &dto.ReactionSummary{Counts: map[string]int{}, Mine: []string{}}
+
+		for _, reaction := range mockReactions[targetType][id] {
+			summary.Counts[reaction.Emoji]++
+			if reaction.UserID == userID {
+				summary.Mine = append(summary.Mine, reaction.Emoji)
+			}
+		}
+
+		summaries[id] = summary
+	}
+
+	return summaries, nil
+}
+
+func (r MockReactionModel) GetAllForTarget(targetType string, targetID int64, emoji string, filters Filters) ([]*dto.ReactionResponseBody, Metadata, error) {
+	reactions := []*dto.ReactionResponseBody{}
+
+	for _, reaction := range mockReactions[targetType][targetID] {
+		if emoji == "" || reaction.Emoji == emoji {
+			reactions = append(reactions, reaction)
+		}
+	}
+
+	return reactions, mockMetadata, nil
+}
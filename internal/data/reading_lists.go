@@ -0,0 +1,504 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrDuplicateListName = errors.New("duplicate reading list name")
+	ErrDuplicateListItem = errors.New("duplicate reading list item")
+)
+
+type ReadingList struct {
+	ID          int64
+	UserID      int64
+	Name        string
+	Description string
+	Public      bool
+	ItemsCount  int
+	CreatedAt   time.Time
+	Version     int32
+}
+
+// Items are ordered by Position, which runs from 1 without gaps.
+type ReadingListItem struct {
+	ListID   int64
+	PostID   int64
+	Position int
+	Note     string
+	AddedAt  time.Time
+}
+
+// Private lists are only visible to their owner.
+func (l *ReadingList) VisibleTo(user *User) bool {
+	return l.Public || l.UserID == user.ID
+}
+
+type ReadingListModel struct {
+	DB *sql.DB
+}
+
+func (m ReadingListModel) Insert(list *ReadingList) error {
+	query := `
+	INSERT INTO reading_lists (user_id, name, description, public)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id, created_at, version`
+
+	args := []interface{}{list.UserID, list.Name, list.Description, list.Public}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&list.ID, &list.CreatedAt, &list.Version)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "reading_lists_user_id_name_key"`:
+			return ErrDuplicateListName
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ItemsCount only counts the items GetItems shows to the viewer.
+func (m ReadingListModel) Get(id int64, viewerID int64) (*ReadingList, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+	SELECT l.id, l.user_id, l.name, l.description, l.public, l.created_at, l.version,
+	(SELECT count(*) FROM reading_list_items i INNER JOIN posts p ON i.post_id = p.id WHERE i.list_id = l.id
+	AND ((NOT p.hidden AND (p.status = 'published' OR (p.status = 'scheduled' AND p.publish_at <= NOW()))) OR p.created_by = $2))
+	FROM reading_lists l
+	WHERE l.id = $1`
+
+	var list ReadingList
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id, viewerID).Scan(
+		&list.ID,
+		&list.UserID,
+		&list.Name,
+		&list.Description,
+		&list.Public,
+		&list.CreatedAt,
+		&list.Version,
+		&list.ItemsCount,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &list, nil
+}
+
+// Reading lists of a user ordered by name. Only public lists are returned when publicOnly is set. Like with Get,
+// ItemsCount only counts the items shown to the viewer.
+func (m ReadingListModel) GetAllForUser(userID int64, viewerID int64, publicOnly bool, filters Filters) ([]*dto.ReadingListResponseBody, Metadata, error) {
+	query := `
+	SELECT count(*) OVER(), l.id, l.user_id, l.name, l.description, l.public, l.created_at,
+	(SELECT count(*) FROM reading_list_items i INNER JOIN posts p ON i.post_id = p.id WHERE i.list_id = l.id
+	AND ((NOT p.hidden AND (p.status = 'published' OR (p.status = 'scheduled' AND p.publish_at <= NOW()))) OR p.created_by = $5))
+	FROM reading_lists l
+	WHERE l.user_id = $1
+	AND (l.public OR NOT $2)
+	ORDER BY l.name, l.id
+	LIMIT $3 OFFSET $4`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID, publicOnly, filters.limit(), filters.offset(), viewerID)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	lists := []*dto.ReadingListResponseBody{}
+
+	for rows.Next() {
+		var list dto.ReadingListResponseBody
+
+		err := rows.Scan(
+			&totalRecords,
+			&list.ID,
+			&list.UserID,
+			&list.Name,
+			&list.Description,
+			&list.Public,
+			&list.CreatedAt,
+			&list.ItemsCount,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		lists = append(lists, &list)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.Limit)
+
+	return lists, metadata, nil
+}
+
+func (m ReadingListModel) Update(list *ReadingList) error {
+	query := `
+	UPDATE reading_lists
+	SET name = $1, description = $2, public = $3, version = version + 1
+	WHERE id = $4 AND version = $5
+	RETURNING version`
+
+	args := []interface{}{list.Name, list.Description, list.Public, list.ID, list.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&list.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		case err.Error() == `pq: duplicate key value violates unique constraint "reading_lists_user_id_name_key"`:
+			return ErrDuplicateListName
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m ReadingListModel) Delete(id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+	DELETE FROM reading_lists
+	WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Items of a list in order, with their posts. Posts the viewer can't see are left out.
+func (m ReadingListModel) GetItems(listID int64, viewerID int64, filters Filters) ([]*dto.ReadingListItemResponseBody, Metadata, error) {
+	query := `
+	SELECT count(*) OVER(), i.position, i.note, i.added_at,
+	p.id, p.title, p.slug, p.post_text, p.img, p.read_time, p.likes_count, p.created_by, p.created_at, p.status, p.publish_at, p.published_at, p.comments_count, p.hidden, u.name,
+	EXISTS(SELECT 1 FROM post_likes pl WHERE pl.post_id = p.id AND pl.user_id = $2),
+	EXISTS(SELECT 1 FROM bookmarks b WHERE b.post_id = p.id AND b.user_id = $2),
+	ARRAY(SELECT t.name FROM post_tags pt INNER JOIN tags t ON pt.tag_id = t.id WHERE pt.post_id = p.id ORDER BY t.name)
+	FROM reading_list_items i
+	INNER JOIN posts p ON i.post_id = p.id
+	INNER JOIN users u ON p.created_by = u.id
+	WHERE i.list_id = $1
+	AND ((NOT p.hidden AND (p.status = 'published' OR (p.status = 'scheduled' AND p.publish_at <= NOW()))) OR p.created_by = $2)
+	ORDER BY i.position
+	LIMIT $3 OFFSET $4`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, listID, viewerID, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	items := []*dto.ReadingListItemResponseBody{}
+
+	for rows.Next() {
+		var item dto.ReadingListItemResponseBody
+		var post Post
+		var userName string
+		var likedByMe, bookmarkedByMe bool
+
+		err := rows.Scan(
+			&totalRecords,
+			&item.Position,
+			&item.Note,
+			&item.AddedAt,
+			&post.ID,
+			&post.Title,
+			&post.Slug,
+			&post.PostText,
+			&post.Img,
+			&post.ReadTime,
+			&post.LikesCount,
+			&post.CreatedBy,
+			&post.CreatedAt,
+			&post.Status,
+			&post.PublishAt,
+			&post.PublishedAt,
+			&post.CommentsCount,
+			&post.Hidden,
+			&userName,
+			&likedByMe,
+			&bookmarkedByMe,
+			pq.Array(&post.Tags),
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		item.Post = &dto.PostResponseBody{
+			ID:             post.ID,
+			Title:          post.Title,
+			Slug:           post.Slug,
+			PostText:       post.PostText,
+			Img:            post.Img,
+			ReadTime:       post.ReadTime,
+			LikesCount:     post.LikesCount,
+			Tags:           post.Tags,
+			Status:         post.Status,
+			PublishAt:      post.PublishAt,
+			PublishedAt:    post.PublishedAt,
+			CommentsCount:  post.CommentsCount,
+			Hidden:         post.Hidden,
+			CreatedAt:      post.CreatedAt,
+			CreatedBy:      post.CreatedBy,
+			UserName:       userName,
+			LikedByMe:      likedByMe,
+			BookmarkedByMe: bookmarkedByMe,
+		}
+
+		items = append(items, &item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.Limit)
+
+	return items, metadata, nil
+}
+
+func (m ReadingListModel) GetItem(listID int64, postID int64) (*ReadingListItem, error) {
+	query := `
+	SELECT list_id, post_id, position, note, added_at
+	FROM reading_list_items
+	WHERE list_id = $1 AND post_id = $2`
+
+	var item ReadingListItem
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, listID, postID).Scan(
+		&item.ListID,
+		&item.PostID,
+		&item.Position,
+		&item.Note,
+		&item.AddedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &item, nil
+}
+
+// Add a post to the end of a list. The list row is locked so concurrent additions don't get the same position.
+func (m ReadingListModel) AddItem(item *ReadingListItem) error {
+	query := `
+	INSERT INTO reading_list_items (list_id, post_id, position, note)
+	SELECT $1, $2, COALESCE(MAX(position), 0) + 1, $3
+	FROM reading_list_items
+	WHERE list_id = $1
+	RETURNING position, added_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `SELECT id FROM reading_lists WHERE id = $1 FOR UPDATE`, item.ListID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, query, item.ListID, item.PostID, item.Note).Scan(&item.Position, &item.AddedAt)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "reading_list_items_pkey"`:
+			return ErrDuplicateListItem
+		default:
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Save an item's note and move it to its position, shifting the items in between. Positions past the end of the
+// list move the item to the end.
+func (m ReadingListModel) UpdateItem(item *ReadingListItem) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `SELECT id FROM reading_lists WHERE id = $1 FOR UPDATE`, item.ListID)
+	if err != nil {
+		return err
+	}
+
+	var current, last int
+
+	query := `
+	SELECT i.position, (SELECT MAX(position) FROM reading_list_items WHERE list_id = $1)
+	FROM reading_list_items i
+	WHERE i.list_id = $1 AND i.post_id = $2`
+
+	err = tx.QueryRowContext(ctx, query, item.ListID, item.PostID).Scan(&current, &last)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	if item.Position > last {
+		item.Position = last
+	}
+
+	switch {
+	case item.Position > current:
+		query = `
+		UPDATE reading_list_items SET position = position - 1
+		WHERE list_id = $1 AND position > $2 AND position <= $3`
+		_, err = tx.ExecContext(ctx, query, item.ListID, current, item.Position)
+	case item.Position < current:
+		query = `
+		UPDATE reading_list_items SET position = position + 1
+		WHERE list_id = $1 AND position >= $3 AND position < $2`
+		_, err = tx.ExecContext(ctx, query, item.ListID, current, item.Position)
+	}
+	if err != nil {
+		return err
+	}
+
+	query = `
+	UPDATE reading_list_items SET position = $1, note = $2
+	WHERE list_id = $3 AND post_id = $4
+	RETURNING added_at`
+
+	err = tx.QueryRowContext(ctx, query, item.Position, item.Note, item.ListID, item.PostID).Scan(&item.AddedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// The items after the removed one move up, see the reading_list_items_close_gap trigger. Like AddItem and UpdateItem,
+// the list row is locked so the positions don't get mixed up with concurrent changes.
+func (m ReadingListModel) RemoveItem(listID int64, postID int64) error {
+	query := `
+	DELETE FROM reading_list_items
+	WHERE list_id = $1 AND post_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `SELECT id FROM reading_lists WHERE id = $1 FOR UPDATE`, listID)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, query, listID, postID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return tx.Commit()
+}
+
+func ValidateReadingList(v *validator.Validator, list *ReadingList) {
+	v.Check(list.Name != "", "name", "must be provided")
+	v.Check(len(list.Name) <= 100, "name", "must not be more than 100 bytes long")
+	v.Check(len(list.Description) <= 500, "description", "must not be more than 500 bytes long")
+}
+
+func ValidateReadingListItem(v *validator.Validator, item *ReadingListItem) {
+	v.Check(item.PostID > 0, "post", "must be a valid id")
+	v.Check(len(item.Note) <= 500, "note", "must not be more than 500 bytes long")
+}
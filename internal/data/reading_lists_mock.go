@@ -0,0 +1,168 @@
+package data
+
+import (
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+)
+
+// Both mocked lists belong to user 1. Mocked post 1 is the only item of the public list.
+var mockReadingList = &ReadingList{
+	ID:          1,
+	UserID:      1,
+	Name:        "Mocked Reading List",
+	Description: "Mocked Reading List Description",
+	Public:      true,
+	ItemsCount:  1,
+	CreatedAt:   time.Now(),
+	Version:     1,
+}
+
+var mockPrivateReadingList = &ReadingList{
+	ID:        2,
+	UserID:    1,
+	Name:      "Mocked Private Reading List",
+	CreatedAt: time.Now(),
+	Version:   1,
+}
+
+var mockReadingListItem = &ReadingListItem{
+	ListID:   1,
+	PostID:   1,
+	Position: 1,
+	Note:     "Mocked Note",
+	AddedAt:  time.Now(),
+}
+
+type MockReadingListModel struct{}
+
+func (l MockReadingListModel) Insert(list *ReadingList) error {
+	if list.Name == mockReadingList.Name {
+		return ErrDuplicateListName
+	}
+
+	list.ID = 3
+	list.CreatedAt = time.Now()
+	list.Version = 1
+
+	return nil
+}
+
+func (l MockReadingListModel) Get(id int64, viewerID int64) (*ReadingList, error) {
+	// Return a copy so handlers mutating the list don't leak changes into other tests.
+	var list ReadingList
+
+	switch id {
+	case 1:
+		list = *mockReadingList
+	case 2:
+		list = *mockPrivateReadingList
+	default:
+		return nil, ErrRecordNotFound
+	}
+
+	return &list, nil
+}
+
+func (l MockReadingListModel) GetAllForUser(userID int64, viewerID int64, publicOnly bool, filters Filters) ([]*dto.ReadingListResponseBody, Metadata, error) {
+	lists := []*dto.ReadingListResponseBody{}
+
+	if userID != mockReadingList.UserID {
+		return lists, Metadata{}, nil
+	}
+
+	for _, list := range []*ReadingList{mockReadingList, mockPrivateReadingList} {
+		if publicOnly && !list.Public {
+			continue
+		}
+
+		lists = append(lists, &dto.ReadingListResponseBody{
+			ID:          list.ID,
+			UserID:      list.UserID,
+			Name:        list.Name,
+			Description: list.Description,
+			Public:      list.Public,
+			ItemsCount:  list.ItemsCount,
+			CreatedAt:   list.CreatedAt,
+		})
+	}
+
+	return lists, mockMetadata, nil
+}
+
+func (l MockReadingListModel) Update(list *ReadingList) error {
+	if list.ID != mockReadingList.ID && list.Name == mockReadingList.Name {
+		return ErrDuplicateListName
+	}
+
+	list.Version++
+
+	return nil
+}
+
+func (l MockReadingListModel) Delete(id int64) error {
+	switch id {
+	case 1, 2:
+		return nil
+	default:
+		return ErrRecordNotFound
+	}
+}
+
+func (l MockReadingListModel) GetItems(listID int64, viewerID int64, filters Filters) ([]*dto.ReadingListItemResponseBody, Metadata, error) {
+	if listID != mockReadingListItem.ListID {
+		return []*dto.ReadingListItemResponseBody{}, Metadata{}, nil
+	}
+
+	post := *mockPostResponseBody
+
+	return []*dto.ReadingListItemResponseBody{{
+		Position: mockReadingListItem.Position,
+		Note:     mockReadingListItem.Note,
+		AddedAt:  mockReadingListItem.AddedAt,
+		Post:     &post,
+	}}, mockMetadata, nil
+}
+
+func (l MockReadingListModel) GetItem(listID int64, postID int64) (*ReadingListItem, error) {
+	if listID != mockReadingListItem.ListID || postID != mockReadingListItem.PostID {
+		return nil, ErrRecordNotFound
+	}
+
+	item := *mockReadingListItem
+
+	return &item, nil
+}
+
+func (l MockReadingListModel) AddItem(item *ReadingListItem) error {
+	if item.ListID == mockReadingListItem.ListID && item.PostID == mockReadingListItem.PostID {
+		return ErrDuplicateListItem
+	}
+
+	item.Position = 1
+	if item.ListID == mockReadingList.ID {
+		item.Position = 2
+	}
+	item.AddedAt = time.Now()
+
+	return nil
+}
+
+// The mocked list has a single item, so it can't move anywhere else.
+func (l MockReadingListModel) UpdateItem(item *ReadingListItem) error {
+	if item.ListID != mockReadingListItem.ListID || item.PostID != mockReadingListItem.PostID {
+		return ErrRecordNotFound
+	}
+
+	item.Position = 1
+
+	return nil
+}
+
+func (l MockReadingListModel) RemoveItem(listID int64, postID int64) error {
+	if listID != mockReadingListItem.ListID || postID != mockReadingListItem.PostID {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
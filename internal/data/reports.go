@@ -0,0 +1,420 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+)
+
+var (
+	ErrDuplicateReport = errors.New("duplicate report")
+	ErrReportResolved  = errors.New("report already resolved")
+)
+
+// Kinds of content which can be reported.
+const (
+	ReportTargetPost    = "post"
+	ReportTargetComment = "comment"
+)
+
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+// Actions recorded in the audit trail of reported content. Moderators resolve reports with hide or dismiss.
+const (
+	ReportActionAutoHidden = "auto_hidden"
+	ReportActionHide       = "hide"
+	ReportActionDismiss    = "dismiss"
+)
+
+var reportReasons = []string{"spam", "harassment", "hate", "misinformation", "other"}
+
+type Report struct {
+	ID         int64
+	TargetType string
+	TargetID   int64
+	ReporterID int64
+	Reason     string
+	Details    string
+	Status     string
+	CreatedAt  time.Time
+	ResolvedBy *int64
+	ResolvedAt *time.Time
+}
+
+type ReportModel struct {
+	DB *sql.DB
+}
+
+// Save a report and hide its target once it has threshold open reports. Hidden posts are only visible to their
+// authors and moderators, hidden comments go back to the moderation queue. A threshold of zero never hides anything.
+// It returns true when this report hid the target and ErrDuplicateReport when the user already reported it.
+func (m ReportModel) Insert(report *Report, threshold int) (bool, error) {
+	query := `
+	INSERT INTO reports (target_type, target_id, reporter_id, reason, details)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (reporter_id, target_type, target_id) DO NOTHING
+	RETURNING id, status, created_at`
+
+	args := []interface{}{report.TargetType, report.TargetID, report.ReporterID, report.Reason, report.Details}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+
+	defer tx.Rollback()
+
+	// Concurrent reports of the same content wait for each other, so one of them sees the threshold reached.
+	err = lockReportTarget(ctx, tx, report.TargetType, report.TargetID)
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&report.ID, &report.Status, &report.CreatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return false, ErrDuplicateReport
+		default:
+			return false, err
+		}
+	}
+
+	hidden := false
+
+	if threshold > 0 {
+		query = `
+		SELECT count(*)
+		FROM reports
+		WHERE target_type = $1 AND target_id = $2 AND status = 'open'`
+
+		var openReports int
+
+		err = tx.QueryRowContext(ctx, query, report.TargetType, report.TargetID).Scan(&openReports)
+		if err != nil {
+			return false, err
+		}
+
+		if openReports >= threshold {
+			hidden, err = setReportTargetHidden(ctx, tx, report.TargetType, report.TargetID, ReportActionAutoHidden, nil)
+			if err != nil {
+				return false, err
+			}
+
+			// Content which was already hidden isn't hidden again, so the audit trail only records the first time.
+			if hidden {
+				note := fmt.Sprintf("%d open reports", openReports)
+
+				err = insertReportAction(ctx, tx, report.TargetType, report.TargetID, ReportActionAutoHidden, nil, note)
+				if err != nil {
+					return false, err
+				}
+			}
+		}
+	}
+
+	return hidden, tx.Commit()
+}
+
+// Reports of the given status, oldest first, optionally only those about one kind of content.
+func (m ReportModel) GetAll(status, targetType string, filters Filters) ([]*dto.ReportResponseBody, Metadata, error) {
+	query := `
+	SELECT count(*) OVER(), r.id, r.target_type, r.target_id, r.reporter_id, u.name, r.reason, r.details, r.status, r.created_at, r.resolved_by, r.resolved_at
+	FROM reports r
+	INNER JOIN users u ON r.reporter_id = u.id
+	WHERE r.status = $1
+	AND (r.target_type = $2 OR $2 = '')
+	ORDER BY r.id
+	LIMIT $3 OFFSET $4`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, status, targetType, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	reports := []*dto.ReportResponseBody{}
+
+	for rows.Next() {
+		var report dto.ReportResponseBody
+
+		err := rows.Scan(
+			&totalRecords,
+			&report.ID,
+			&report.TargetType,
+			&report.TargetID,
+			&report.ReporterID,
+			&report.ReporterName,
+			&report.Reason,
+			&report.Details,
+			&report.Status,
+			&report.CreatedAt,
+			&report.ResolvedBy,
+			&report.ResolvedAt,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		reports = append(reports, &report)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.Limit)
+
+	return reports, metadata, nil
+}
+
+func (m ReportModel) Get(id int64) (*Report, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+	SELECT id, target_type, target_id, reporter_id, reason, details, status, created_at, resolved_by, resolved_at
+	FROM reports
+	WHERE id = $1`
+
+	var report Report
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&report.ID,
+		&report.TargetType,
+		&report.TargetID,
+		&report.ReporterID,
+		&report.Reason,
+		&report.Details,
+		&report.Status,
+		&report.CreatedAt,
+		&report.ResolvedBy,
+		&report.ResolvedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &report, nil
+}
+
+// The audit trail of a piece of reported content, oldest first.
+func (m ReportModel) GetActions(targetType string, targetID int64) ([]*dto.ReportActionResponseBody, error) {
+	query := `
+	SELECT a.action, a.actor_id, COALESCE(u.name, ''), a.note, a.created_at
+	FROM report_actions a
+	LEFT JOIN users u ON a.actor_id = u.id
+	WHERE a.target_type = $1 AND a.target_id = $2
+	ORDER BY a.id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	actions := []*dto.ReportActionResponseBody{}
+
+	for rows.Next() {
+		var action dto.ReportActionResponseBody
+
+		err := rows.Scan(
+			&action.Action,
+			&action.ActorID,
+			&action.ActorName,
+			&action.Note,
+			&action.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		actions = append(actions, &action)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return actions, nil
+}
+
+// Hide the report's target or dismiss the reports against it. Either way every open report of the target is closed
+// and the action goes to the audit trail. Dismissing makes content hidden by reports visible again. Reports which
+// aren't open anymore return ErrReportResolved.
+func (m ReportModel) Resolve(report *Report, action string, moderatorID int64, note string) error {
+	query := `
+	UPDATE reports
+	SET status = $1, resolved_by = $2, resolved_at = NOW()
+	WHERE target_type = $3 AND target_id = $4 AND status = 'open'`
+
+	status := ReportStatusResolved
+	if action == ReportActionDismiss {
+		status = ReportStatusDismissed
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = lockReportTarget(ctx, tx, report.TargetType, report.TargetID)
+	if err != nil {
+		return err
+	}
+
+	var current string
+
+	err = tx.QueryRowContext(ctx, `SELECT status FROM reports WHERE id = $1`, report.ID).Scan(&current)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	if current != ReportStatusOpen {
+		return ErrReportResolved
+	}
+
+	_, err = setReportTargetHidden(ctx, tx, report.TargetType, report.TargetID, action, &moderatorID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, query, status, moderatorID, report.TargetType, report.TargetID)
+	if err != nil {
+		return err
+	}
+
+	err = insertReportAction(ctx, tx, report.TargetType, report.TargetID, action, &moderatorID, note)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `SELECT status, resolved_by, resolved_at FROM reports WHERE id = $1`, report.ID).Scan(
+		&report.Status,
+		&report.ResolvedBy,
+		&report.ResolvedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Lock the reported post or comment until the transaction ends. Every change to the reports of some content takes this
+// lock first, so they don't interleave.
+func lockReportTarget(ctx context.Context, tx *sql.Tx, targetType string, targetID int64) error {
+	query := `SELECT id FROM posts WHERE id = $1 FOR UPDATE`
+	if targetType == ReportTargetComment {
+		query = `SELECT id FROM comments WHERE id = $1 FOR UPDATE`
+	}
+
+	_, err := tx.ExecContext(ctx, query, targetID)
+
+	return err
+}
+
+// Apply a report action to its target and report whether the target changed.
+//
+//	auto_hidden  hides a post, sends an approved comment back to the moderation queue
+//	hide         hides a post, rejects a comment
+//	dismiss      shows a hidden post again, approves a comment waiting in the queue
+func setReportTargetHidden(ctx context.Context, tx *sql.Tx, targetType string, targetID int64, action string, moderatorID *int64) (bool, error) {
+	var query string
+	args := []interface{}{targetID}
+
+	switch {
+	case targetType == ReportTargetPost && action == ReportActionDismiss:
+		query = `UPDATE posts SET hidden = false WHERE id = $1 AND hidden`
+	case targetType == ReportTargetPost:
+		query = `UPDATE posts SET hidden = true WHERE id = $1 AND NOT hidden`
+	case action == ReportActionAutoHidden:
+		query = `UPDATE comments SET status = 'pending' WHERE id = $1 AND status = 'approved'`
+	case action == ReportActionHide:
+		query = `UPDATE comments SET status = 'rejected', moderated_by = $2, moderated_at = NOW() WHERE id = $1 AND status <> 'rejected'`
+		args = append(args, moderatorID)
+	default:
+		query = `UPDATE comments SET status = 'approved', moderated_by = $2, moderated_at = NOW() WHERE id = $1 AND status = 'pending'`
+		args = append(args, moderatorID)
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func insertReportAction(ctx context.Context, tx *sql.Tx, targetType string, targetID int64, action string, actorID *int64, note string) error {
+	query := `
+	INSERT INTO report_actions (target_type, target_id, action, actor_id, note)
+	VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := tx.ExecContext(ctx, query, targetType, targetID, action, actorID, note)
+
+	return err
+}
+
+func ValidateReport(v *validator.Validator, report *Report) {
+	v.Check(validator.In(report.TargetType, ReportTargetPost, ReportTargetComment), "targetType", "must be post or comment")
+	v.Check(report.TargetID > 0, "targetId", "must be a valid id")
+	v.Check(validator.In(report.Reason, reportReasons...), "reason", "must be one of spam, harassment, hate, misinformation or other")
+	v.Check(len(report.Details) <= 1000, "details", "must not be more than 1000 bytes long")
+}
+
+func ValidateReportAction(v *validator.Validator, action, note string) {
+	v.Check(validator.In(action, ReportActionHide, ReportActionDismiss), "action", "must be hide or dismiss")
+	v.Check(len(note) <= 1000, "note", "must not be more than 1000 bytes long")
+}
+
+func ValidateReportFilters(v *validator.Validator, status, targetType string) {
+	v.Check(validator.In(status, ReportStatusOpen, ReportStatusResolved, ReportStatusDismissed), "status", "must be open, resolved or dismissed")
+	v.Check(validator.In(targetType, "", ReportTargetPost, ReportTargetComment), "target_type", "must be post or comment")
+}
@@ -0,0 +1,93 @@
+package data
+
+import (
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+)
+
+// User 2 has reported mocked post 1.
+var mockReport = &Report{
+	ID:         1,
+	TargetType: ReportTargetPost,
+	TargetID:   1,
+	ReporterID: 2,
+	Reason:     "spam",
+	Details:    "Mocked Report Details",
+	Status:     ReportStatusOpen,
+	CreatedAt:  time.Now(),
+}
+
+type MockReportModel struct{}
+
+// Every report hides its target when the threshold is one.
+func (r MockReportModel) Insert(report *Report, threshold int) (bool, error) {
+	if report.ReporterID == mockReport.ReporterID && report.TargetType == mockReport.TargetType && report.TargetID == mockReport.TargetID {
+		return false, ErrDuplicateReport
+	}
+
+	report.ID = 2
+	report.Status = ReportStatusOpen
+	report.CreatedAt = time.Now()
+
+	return threshold == 1, nil
+}
+
+func (r MockReportModel) GetAll(status, targetType string, filters Filters) ([]*dto.ReportResponseBody, Metadata, error) {
+	if status != mockReport.Status || (targetType != "" && targetType != mockReport.TargetType) {
+		return []*dto.ReportResponseBody{}, Metadata{}, nil
+	}
+
+	return []*dto.ReportResponseBody{{
+		ID:           mockReport.ID,
+		TargetType:   mockReport.TargetType,
+		TargetID:     mockReport.TargetID,
+		ReporterID:   mockReport.ReporterID,
+		ReporterName: "Another User",
+		Reason:       mockReport.Reason,
+		Details:      mockReport.Details,
+		Status:       mockReport.Status,
+		CreatedAt:    mockReport.CreatedAt,
+	}}, mockMetadata, nil
+}
+
+// Report 3 is like report 1, but already resolved.
+func (r MockReportModel) Get(id int64) (*Report, error) {
+	switch id {
+	case 1:
+		report := *mockReport
+		return &report, nil
+	case 3:
+		report := *mockReport
+		report.ID = 3
+		report.Status = ReportStatusResolved
+		return &report, nil
+	default:
+		return nil, ErrRecordNotFound
+	}
+}
+
+func (r MockReportModel) GetActions(targetType string, targetID int64) ([]*dto.ReportActionResponseBody, error) {
+	return []*dto.ReportActionResponseBody{{
+		Action:    ReportActionAutoHidden,
+		Note:      "5 open reports",
+		CreatedAt: time.Now(),
+	}}, nil
+}
+
+func (r MockReportModel) Resolve(report *Report, action string, moderatorID int64, note string) error {
+	if report.Status != ReportStatusOpen {
+		return ErrReportResolved
+	}
+
+	now := time.Now()
+
+	report.Status = ReportStatusResolved
+	if action == ReportActionDismiss {
+		report.Status = ReportStatusDismissed
+	}
+	report.ResolvedBy = &moderatorID
+	report.ResolvedAt = &now
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Sessions are added to the list by a trigger whenever their access token row is deleted.
+type RevokedSessionModel struct {
+	DB *sql.DB
+}
+
+// Revoked sessions whose signed access tokens may still be valid, with the time the last of them expires.
+func (m RevokedSessionModel) GetAll() (map[int64]time.Time, error) {
+	query := `
+	SELECT family, expiry
+	FROM revoked_sessions
+	WHERE expiry > NOW()`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	revoked := make(map[int64]time.Time)
+
+	for rows.Next() {
+		var family int64
+		var expiry time.Time
+
+		err := rows.Scan(&family, &expiry)
+		if err != nil {
+			return nil, err
+		}
+
+		revoked[family] = expiry
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revoked, nil
+}
+
+func (m RevokedSessionModel) DeleteExpired() error {
+	query := `
+	DELETE FROM revoked_sessions
+	WHERE expiry <= NOW()`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query)
+
+	return err
+}
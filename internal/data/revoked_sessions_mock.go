@@ -0,0 +1,16 @@
+package data
+
+import (
+	"time"
+)
+
+// Session 99 was revoked.
+type MockRevokedSessionModel struct{}
+
+func (m MockRevokedSessionModel) GetAll() (map[int64]time.Time, error) {
+	return map[int64]time.Time{99: time.Now().Add(time.Hour)}, nil
+}
+
+func (m MockRevokedSessionModel) DeleteExpired() error {
+	return nil
+}
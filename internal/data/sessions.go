@@ -0,0 +1,311 @@
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+)
+
+var (
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+// The session a request was made with: the plaintext of an opaque access token, or the family of a signed one.
+type SessionRef struct {
+	TokenPlainText string
+	Family         int64
+}
+
+// A session is the short-lived access token (an authentication token) of a login along with its refresh token.
+// Both share a family. Refreshing rotates them, keeping the access token's row so the session keeps its id and details.
+// Authentication tokens without a family come from before refresh tokens and are sessions on their own.
+
+// Start a session for the user, returning its access and refresh tokens.
+func (t TokenModel) NewSession(userID int64, accessTTL, refreshTTL time.Duration) (*Token, *Token, error) {
+	access, err := generateToken(userID, accessTTL, ScopeAuthentication)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refresh, err := generateToken(userID, refreshTTL, ScopeRefresh)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := `
+	WITH family AS (
+		SELECT nextval('token_families_seq') AS id
+	)
+	INSERT INTO tokens (hash, user_id, expiry, scope, family)
+	SELECT hash, $1, expiry, scope, family.id
+	FROM family, (VALUES ($2::bytea, $3::timestamptz, $4), ($5::bytea, $6::timestamptz, $7)) AS t (hash, expiry, scope)
+	RETURNING family`
+
+	args := []interface{}{userID, access.Hash, access.Expiry, access.Scope, refresh.Hash, refresh.Expiry, refresh.Scope}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Both rows have the same family, so reading the first one is enough.
+	err = t.DB.QueryRowContext(ctx, query, args...).Scan(&access.Family)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refresh.Family = access.Family
+
+	return access, refresh, nil
+}
+
+// Trade a refresh token for a new access and refresh token of the same session. Each refresh token can only be used once.
+// Using one again means it leaked, so the whole session is revoked and ErrRefreshTokenReused returned.
+// It returns ErrRecordNotFound when the refresh token is unknown, expired or its session was revoked.
+func (t TokenModel) Refresh(refreshPlainText string, accessTTL, refreshTTL time.Duration) (*Token, *Token, error) {
+	refreshHash := sha256.Sum256([]byte(refreshPlainText))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := t.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer tx.Rollback()
+
+	var userID, family int64
+	var rotatedAt *time.Time
+
+	query := `
+	SELECT user_id, family, rotated_at
+	FROM tokens
+	WHERE hash = $1 AND scope = 'refresh' AND expiry > NOW()
+	FOR UPDATE`
+
+	err = tx.QueryRowContext(ctx, query, refreshHash[:]).Scan(&userID, &family, &rotatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, nil, ErrRecordNotFound
+		default:
+			return nil, nil, err
+		}
+	}
+
+	if rotatedAt != nil {
+		_, err = tx.ExecContext(ctx, `DELETE FROM tokens WHERE family = $1`, family)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return nil, nil, ErrRefreshTokenReused
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE tokens SET rotated_at = NOW() WHERE hash = $1`, refreshHash[:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	access, err := generateToken(userID, accessTTL, ScopeAuthentication)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query = `
+	UPDATE tokens
+	SET hash = $1, expiry = $2
+	WHERE family = $3 AND scope = 'authentication'`
+
+	result, err := tx.ExecContext(ctx, query, access.Hash, access.Expiry, family)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The session was logged out.
+	if rowsAffected == 0 {
+		return nil, nil, ErrRecordNotFound
+	}
+
+	refresh, err := generateToken(userID, refreshTTL, ScopeRefresh)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query = `
+	INSERT INTO tokens (hash, user_id, expiry, scope, family)
+	VALUES ($1, $2, $3, $4, $5)`
+
+	_, err = tx.ExecContext(ctx, query, refresh.Hash, refresh.UserID, refresh.Expiry, refresh.Scope, family)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	access.Family = family
+	refresh.Family = family
+
+	return access, refresh, nil
+}
+
+// Record that the session was just used, and from where. Requests in quick succession only write once a minute.
+func (t TokenModel) Touch(tokenPlainText, ip, userAgent string) error {
+	query := `
+	UPDATE tokens
+	SET last_used_at = NOW(), ip = $2, user_agent = $3
+	WHERE hash = $1 AND scope = 'authentication'
+	AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute' OR ip <> $2 OR user_agent <> $3)`
+
+	tokenHash := sha256.Sum256([]byte(tokenPlainText))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := t.DB.ExecContext(ctx, query, tokenHash[:], ip, userAgent)
+
+	return err
+}
+
+// Active sessions of the user, most recently used first, with the current session marked.
+// A session stays active while its access token or its latest refresh token is valid, and expires with the later of the two.
+func (t TokenModel) GetSessionsForUser(userID int64, current SessionRef) ([]*dto.SessionResponseBody, error) {
+	query := `
+	SELECT a.id, a.created_at, a.last_used_at, GREATEST(a.expiry, r.expiry), a.ip, a.user_agent, (a.hash = $2 OR COALESCE(a.family = $3, false))
+	FROM tokens a
+	LEFT JOIN tokens r ON r.family = a.family AND r.scope = 'refresh' AND r.rotated_at IS NULL
+	WHERE a.user_id = $1 AND a.scope = 'authentication'
+	AND (a.expiry > NOW() OR r.expiry > NOW())
+	ORDER BY COALESCE(a.last_used_at, a.created_at) DESC, a.id DESC`
+
+	currentHash := sha256.Sum256([]byte(current.TokenPlainText))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := t.DB.QueryContext(ctx, query, userID, currentHash[:], current.Family)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	sessions := []*dto.SessionResponseBody{}
+
+	for rows.Next() {
+		var session dto.SessionResponseBody
+
+		err := rows.Scan(
+			&session.ID,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.Expiry,
+			&session.IP,
+			&session.UserAgent,
+			&session.Current,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, &session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Delete the session along with its refresh tokens, logging it out.
+func (t TokenModel) DeleteSession(session SessionRef) error {
+	query := `
+	WITH session AS (
+		SELECT id, family FROM tokens WHERE scope = 'authentication' AND (hash = $1 OR family = $2)
+	)
+	DELETE FROM tokens t
+	USING session s
+	WHERE t.id = s.id OR t.family = s.family`
+
+	tokenHash := sha256.Sum256([]byte(session.TokenPlainText))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := t.DB.ExecContext(ctx, query, tokenHash[:], session.Family)
+
+	return err
+}
+
+// Delete a session of the user by its id. It returns ErrRecordNotFound when the user has no such session.
+func (t TokenModel) DeleteSessionByID(userID int64, id int64) error {
+	query := `
+	WITH session AS (
+		SELECT id, family FROM tokens WHERE id = $1 AND user_id = $2 AND scope = 'authentication'
+	)
+	DELETE FROM tokens t
+	USING session s
+	WHERE t.id = s.id OR t.family = s.family`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := t.DB.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Delete every session of the user except the current one, and return how many were deleted.
+func (t TokenModel) DeleteOtherSessions(userID int64, current SessionRef) (int64, error) {
+	query := `
+	WITH deleted AS (
+		DELETE FROM tokens
+		WHERE user_id = $1 AND scope IN ('authentication', 'refresh') AND hash <> $2
+		AND (family IS NULL OR family <> COALESCE(NULLIF($3, 0), (SELECT family FROM tokens WHERE hash = $2), 0))
+		RETURNING scope
+	)
+	SELECT count(*) FROM deleted WHERE scope = 'authentication'`
+
+	tokenHash := sha256.Sum256([]byte(current.TokenPlainText))
+
+	var revoked int64
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := t.DB.QueryRowContext(ctx, query, userID, tokenHash[:], current.Family).Scan(&revoked)
+	if err != nil {
+		return 0, err
+	}
+
+	return revoked, nil
+}
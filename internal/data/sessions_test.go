@@ -0,0 +1,167 @@
+package data
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+)
+
+// Open the migrated database in TEST_DB_DSN, skipping the test when it isn't set.
+func newTestDB(t *testing.T) *sql.DB {
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// A session is either found by the plaintext of an opaque access token, with no family, or by the family of a signed
+// one, with no plaintext. The zero value of the other must not match anything, which depends on how Postgres types the
+// parameters, so this runs against a real database.
+func TestSessionRefs(t *testing.T) {
+	db := newTestDB(t)
+
+	users := UserModel{DB: db}
+	tokens := TokenModel{DB: db}
+
+	user := &User{Name: "Session Test", Email: fmt.Sprintf("sessions-%d@example.com", time.Now().UnixNano()), Activated: true, Role: RoleReader}
+
+	err := user.Password.Set("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = users.Insert(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { db.Exec(`DELETE FROM users WHERE id = $1`, user.ID) })
+
+	tests := []struct {
+		name    string
+		current func(access *Token) SessionRef
+	}{
+		{"Opaque token", func(access *Token) SessionRef { return SessionRef{TokenPlainText: access.Plaintext} }},
+		{"Signed token", func(access *Token) SessionRef { return SessionRef{Family: access.Family} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			access, _, err := tokens.NewSession(user.ID, time.Hour, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, _, err = tokens.NewSession(user.ID, time.Hour, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			current := tt.current(access)
+
+			sessions, err := tokens.GetSessionsForUser(user.ID, current)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			currentSessions := 0
+			for _, session := range sessions {
+				if session.Current {
+					currentSessions++
+				}
+			}
+
+			assert.Equal(t, len(sessions), 2)
+			assert.Equal(t, currentSessions, 1)
+
+			deleted, err := tokens.DeleteOtherSessions(user.ID, current)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, deleted, int64(1))
+
+			sessions, err = tokens.GetSessionsForUser(user.ID, current)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, len(sessions), 1)
+			assert.Equal(t, sessions[0].Current, true)
+		})
+	}
+}
+
+// Refreshing rotates the access token of a session in place, so the cleanup must keep it after it expires as long as
+// the session can still be refreshed.
+func TestDeleteExpiredKeepsRefreshableSessions(t *testing.T) {
+	db := newTestDB(t)
+
+	users := UserModel{DB: db}
+	tokens := TokenModel{DB: db}
+
+	user := &User{Name: "Cleanup Test", Email: fmt.Sprintf("cleanup-%d@example.com", time.Now().UnixNano()), Activated: true, Role: RoleReader}
+
+	err := user.Password.Set("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = users.Insert(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { db.Exec(`DELETE FROM users WHERE id = $1`, user.ID) })
+
+	// The access token has already expired, the refresh token hasn't.
+	_, refresh, err := tokens.NewSession(user.ID, -time.Minute, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = tokens.DeleteExpired()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	access, _, err := tokens.Refresh(refresh.Plaintext, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, access.Family, refresh.Family)
+
+	// Once the refresh token has expired too, the whole session is deleted.
+	_, expiredRefresh, err := tokens.NewSession(user.ID, -time.Minute, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = tokens.DeleteExpired()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var remaining int
+
+	err = db.QueryRow(`SELECT count(*) FROM tokens WHERE family = $1`, expiredRefresh.Family).Scan(&remaining)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, remaining, 0)
+}
@@ -0,0 +1,124 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+	"github.com/AthfanFasee/blog-post-backend/internal/slug"
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+	"github.com/lib/pq"
+)
+
+// Tag filter modes. "any" matches posts with at least one of the tags, "all" only posts with every tag.
+const (
+	TagModeAny = "any"
+	TagModeAll = "all"
+)
+
+type TagModel struct {
+	DB *sql.DB
+}
+
+// Get every tag used by a public post along with the number of public posts using it.
+func (t TagModel) GetAll() ([]*dto.TagResponseBody, error) {
+	query := `
+	SELECT t.name, count(*)
+	FROM tags t
+	INNER JOIN post_tags pt ON pt.tag_id = t.id
+	INNER JOIN posts p ON pt.post_id = p.id
+	WHERE NOT p.hidden AND (p.status = 'published' OR (p.status = 'scheduled' AND p.publish_at <= NOW()))
+	GROUP BY t.name
+	ORDER BY count(*) DESC, t.name ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := t.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tags := []*dto.TagResponseBody{}
+
+	for rows.Next() {
+		var tag dto.TagResponseBody
+
+		err := rows.Scan(&tag.Name, &tag.PostsCount)
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, &tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// Replace the tags of a post. It is called by PostModel within the same transaction that writes the post.
+func setPostTags(ctx context.Context, tx *sql.Tx, postID int64, tags []string) error {
+	query := `
+	INSERT INTO tags (name)
+	SELECT unnest($1::text[])
+	ON CONFLICT (name) DO NOTHING`
+
+	_, err := tx.ExecContext(ctx, query, pq.Array(tags))
+	if err != nil {
+		return err
+	}
+
+	query = `
+	DELETE FROM post_tags
+	WHERE post_id = $1`
+
+	_, err = tx.ExecContext(ctx, query, postID)
+	if err != nil {
+		return err
+	}
+
+	query = `
+	INSERT INTO post_tags (post_id, tag_id)
+	SELECT $1, id FROM tags
+	WHERE name = ANY($2)`
+
+	_, err = tx.ExecContext(ctx, query, postID, pq.Array(tags))
+	return err
+}
+
+// Turn tag names into unique lowercase slugs, keeping their order. Tags without any letter or digit are dropped.
+func NormalizeTags(tags []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool)
+
+	for _, tag := range tags {
+		name := slug.Make(tag)
+
+		if name == "" || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+
+	return normalized
+}
+
+func ValidateTags(v *validator.Validator, tags []string) {
+	v.Check(len(tags) <= 10, "tags", "A post can have 10 tags or less")
+
+	for _, tag := range tags {
+		v.Check(len(tag) <= 30, "tags", "Tags can only contain 30 characters or less")
+	}
+}
+
+func ValidateTagMode(v *validator.Validator, mode string) {
+	v.Check(validator.In(mode, TagModeAny, TagModeAll), "tag_mode", "must be either any or all")
+}
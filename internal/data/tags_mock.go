@@ -0,0 +1,11 @@
+package data
+
+import (
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
+)
+
+type MockTagModel struct{}
+
+func (t MockTagModel) GetAll() ([]*dto.TagResponseBody, error) {
+	return []*dto.TagResponseBody{{Name: "go", PostsCount: 1}}, nil
+}
@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"database/sql"
 	"encoding/base32"
+	"errors"
 	"time"
 
 	"github.com/AthfanFasee/blog-post-backend/internal/validator"
@@ -14,6 +15,12 @@ import (
 const (
 	ScopeActivation     = "activation"
 	ScopeAuthentication = "authentication"
+	ScopePasswordReset  = "password-reset"
+	ScopeEmailChange    = "email-change"
+	ScopeEmailRevert    = "email-revert"
+	ScopeRefresh        = "refresh"
+	// Issued on login to users with two-factor authentication, and exchanged for a session along with a valid code.
+	Scope2FAChallenge = "2fa-challenge"
 )
 
 type Token struct {
@@ -22,6 +29,10 @@ type Token struct {
 	UserID    int64     `json:"userID"`
 	Expiry    time.Time `json:"expiry"`
 	Scope     string    `json:"-"`
+	// The address an email change or revert token switches the user to.
+	Email string `json:"-"`
+	// The session of an access or refresh token.
+	Family int64 `json:"-"`
 }
 
 type TokenModel struct {
@@ -79,12 +90,25 @@ func (t TokenModel) New(userID int64, timeToLive time.Duration, scope string) (*
 	return token, err
 }
 
+// Like New, for email change and revert tokens that switch the user to the given address.
+func (t TokenModel) NewForEmail(userID int64, timeToLive time.Duration, scope string, email string) (*Token, error) {
+	token, err := generateToken(userID, timeToLive, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	token.Email = email
+
+	err = t.Insert(token)
+	return token, err
+}
+
 func (t TokenModel) Insert(token *Token) error {
 	query := `
-	INSERT INTO tokens (hash, user_id, expiry, scope)
-	VALUES ($1, $2, $3, $4)`
+	INSERT INTO tokens (hash, user_id, expiry, scope, email)
+	VALUES ($1, $2, $3, $4, NULLIF($5, ''))`
 
-	args := []interface{}{token.Hash, token.UserID, token.Expiry, token.Scope}
+	args := []interface{}{token.Hash, token.UserID, token.Expiry, token.Scope, token.Email}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -93,6 +117,39 @@ func (t TokenModel) Insert(token *Token) error {
 	return err
 }
 
+// Get an unexpired token of the given scope. The plaintext isn't stored, so it's left empty.
+func (t TokenModel) Get(scope, tokenPlainText string) (*Token, error) {
+	query := `
+	SELECT hash, user_id, expiry, scope, COALESCE(email, '')
+	FROM tokens
+	WHERE hash = $1 AND scope = $2 AND expiry > $3`
+
+	tokenHash := sha256.Sum256([]byte(tokenPlainText))
+
+	var token Token
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := t.DB.QueryRowContext(ctx, query, tokenHash[:], scope, time.Now()).Scan(
+		&token.Hash,
+		&token.UserID,
+		&token.Expiry,
+		&token.Scope,
+		&token.Email,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &token, nil
+}
+
 func (t TokenModel) DeleteAllForUser(scope string, userID int64) error {
 	query := `
 	DELETE FROM tokens
@@ -106,6 +163,65 @@ func (t TokenModel) DeleteAllForUser(scope string, userID int64) error {
 	return err
 }
 
+// Delete every expired token, including the rotated refresh tokens kept to detect reuse. Returns how many were deleted.
+// The access token of a session is kept while its refresh token is unexpired, since refreshing rotates it in place.
+func (t TokenModel) DeleteExpired() (int64, error) {
+	query := `
+	DELETE FROM tokens
+	WHERE expiry <= NOW()
+	AND NOT (scope = 'authentication' AND family IS NOT NULL AND family IN (
+		SELECT family FROM tokens WHERE scope = 'refresh' AND expiry > NOW()
+	))`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := t.DB.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// Count an attempt at using an unexpired token of the given scope. Returns false if there's no such token. It's
+// deleted on the last of maxAttempts attempts.
+func (t TokenModel) CountAttempt(scope, tokenPlainText string, maxAttempts int) (bool, error) {
+	query := `
+	UPDATE tokens
+	SET attempts = attempts + 1
+	WHERE hash = $1 AND scope = $2 AND expiry > $3 AND attempts < $4
+	RETURNING attempts`
+
+	tokenHash := sha256.Sum256([]byte(tokenPlainText))
+
+	var attempts int
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := t.DB.QueryRowContext(ctx, query, tokenHash[:], scope, time.Now(), maxAttempts).Scan(&attempts)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+
+	if attempts < maxAttempts {
+		return true, nil
+	}
+
+	_, err = t.DB.ExecContext(ctx, `DELETE FROM tokens WHERE hash = $1 AND scope = $2`, tokenHash[:], scope)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 func ValidateTokenPlainText(v *validator.Validator, tokenPlainText string) {
 	v.Check(tokenPlainText != "", "token", "must be provided")
 	v.Check(len(tokenPlainText) == 26, "token", "must be 26 bytes long")
@@ -3,6 +3,8 @@ package data
 import (
 	"fmt"
 	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/dto"
 )
 
 var mockToken = &Token{
@@ -14,7 +16,15 @@ var mockToken = &Token{
 }
 
 type MockTokenModel struct {
-	MockNew func(userID int64, ttl time.Duration, scope string) (*Token, error)
+	MockNew              func(userID int64, ttl time.Duration, scope string) (*Token, error)
+	MockNewForEmail      func(userID int64, ttl time.Duration, scope string, email string) (*Token, error)
+	MockGet              func(scope, tokenPlainText string) (*Token, error)
+	MockDeleteAllForUser func(scope string, userID int64) error
+	MockCountAttempt     func(scope, tokenPlainText string, maxAttempts int) (bool, error)
+	MockDeleteExpired    func() (int64, error)
+	MockDeleteSession    func(session SessionRef) error
+	MockNewSession       func(userID int64, accessTTL, refreshTTL time.Duration) (*Token, *Token, error)
+	MockRefresh          func(refreshPlainText string, accessTTL, refreshTTL time.Duration) (*Token, *Token, error)
 }
 
 func (c MockTokenModel) Insert(token *Token) error {
@@ -27,13 +37,114 @@ func (t MockTokenModel) New(userID int64, timeToLive time.Duration, scope string
 	}
 
 	switch {
-	case userID == 1 && scope == ScopeActivation:
+	case userID == 1 && (scope == ScopeActivation || scope == ScopePasswordReset || scope == Scope2FAChallenge):
 		return mockToken, nil
 	default:
 		return nil, fmt.Errorf("mocked Error")
 	}
 }
 
+func (t MockTokenModel) NewForEmail(userID int64, timeToLive time.Duration, scope string, email string) (*Token, error) {
+	if t.MockNewForEmail != nil {
+		return t.MockNewForEmail(userID, timeToLive, scope, email)
+	}
+
+	return &Token{UserID: userID, Expiry: time.Now().Add(timeToLive), Scope: scope, Email: email}, nil
+}
+
+// Email change tokens switch mocked user 1 to new@email.com, revert tokens switch it back to mocked@email.com.
+func (t MockTokenModel) Get(scope, tokenPlainText string) (*Token, error) {
+	if t.MockGet != nil {
+		return t.MockGet(scope, tokenPlainText)
+	}
+
+	token := &Token{UserID: 1, Expiry: mockToken.Expiry, Scope: scope}
+
+	switch scope {
+	case ScopeEmailChange:
+		token.Email = "new@email.com"
+	case ScopeEmailRevert:
+		token.Email = "mocked@email.com"
+	default:
+		return nil, ErrRecordNotFound
+	}
+
+	return token, nil
+}
+
 func (t MockTokenModel) DeleteAllForUser(scope string, userID int64) error {
+	if t.MockDeleteAllForUser != nil {
+		return t.MockDeleteAllForUser(scope, userID)
+	}
+
+	return nil
+}
+
+func (t MockTokenModel) DeleteExpired() (int64, error) {
+	if t.MockDeleteExpired != nil {
+		return t.MockDeleteExpired()
+	}
+
+	return 0, nil
+}
+
+func (t MockTokenModel) CountAttempt(scope, tokenPlainText string, maxAttempts int) (bool, error) {
+	if t.MockCountAttempt != nil {
+		return t.MockCountAttempt(scope, tokenPlainText, maxAttempts)
+	}
+
+	return true, nil
+}
+
+func (t MockTokenModel) NewSession(userID int64, accessTTL, refreshTTL time.Duration) (*Token, *Token, error) {
+	if t.MockNewSession != nil {
+		return t.MockNewSession(userID, accessTTL, refreshTTL)
+	}
+
+	access := &Token{UserID: userID, Expiry: time.Now().Add(accessTTL), Scope: ScopeAuthentication, Family: 1}
+	refresh := &Token{UserID: userID, Expiry: time.Now().Add(refreshTTL), Scope: ScopeRefresh, Family: 1}
+
+	return access, refresh, nil
+}
+
+func (t MockTokenModel) Refresh(refreshPlainText string, accessTTL, refreshTTL time.Duration) (*Token, *Token, error) {
+	if t.MockRefresh != nil {
+		return t.MockRefresh(refreshPlainText, accessTTL, refreshTTL)
+	}
+
+	return t.NewSession(1, accessTTL, refreshTTL)
+}
+
+func (t MockTokenModel) Touch(tokenPlainText, ip, userAgent string) error {
 	return nil
 }
+
+// Every user has two sessions, 1 being the current one.
+func (t MockTokenModel) GetSessionsForUser(userID int64, current SessionRef) ([]*dto.SessionResponseBody, error) {
+	lastUsedAt := mockToken.Expiry.Add(-time.Hour)
+
+	return []*dto.SessionResponseBody{
+		{ID: 1, LastUsedAt: &lastUsedAt, Expiry: mockToken.Expiry, IP: "127.0.0.1", UserAgent: "Mocked Agent", Current: true},
+		{ID: 2, Expiry: mockToken.Expiry, IP: "10.0.0.1", UserAgent: "Other Agent"},
+	}, nil
+}
+
+func (t MockTokenModel) DeleteSession(session SessionRef) error {
+	if t.MockDeleteSession != nil {
+		return t.MockDeleteSession(session)
+	}
+
+	return nil
+}
+
+func (t MockTokenModel) DeleteSessionByID(userID int64, id int64) error {
+	if id > 2 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (t MockTokenModel) DeleteOtherSessions(userID int64, current SessionRef) (int64, error) {
+	return 1, nil
+}
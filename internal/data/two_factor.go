@@ -0,0 +1,338 @@
+package data
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/validator"
+	"github.com/lib/pq"
+)
+
+const recoveryCodesCount = 10
+
+// After maxFailedAttempts invalid codes in a row, codes are refused for failedAttemptsLockout, doubling with every
+// further invalid code up to maxFailedAttemptsLockout.
+const (
+	maxFailedAttempts        = 5
+	failedAttemptsLockout    = time.Minute
+	maxFailedAttemptsLockout = 24 * time.Hour
+)
+
+var ErrTwoFactorEnabled = errors.New("two-factor authentication already enabled")
+
+// Secret is empty until the user enrolls. Codes are only asked for on login once Enabled is set, after the user
+// proved their authenticator works.
+type TwoFactor struct {
+	UserID      int64
+	Secret      string
+	Enabled     bool
+	LastStep    int64
+	LockedUntil *time.Time
+}
+
+// Whether codes are refused because of too many invalid ones.
+func (t *TwoFactor) IsLocked() bool {
+	return t.LockedUntil != nil && t.LockedUntil.After(time.Now())
+}
+
+type TwoFactorModel struct {
+	DB *sql.DB
+}
+
+func (m TwoFactorModel) Get(userID int64) (*TwoFactor, error) {
+	query := `
+	SELECT id, COALESCE(totp_secret, ''), totp_enabled, totp_last_step, totp_locked_until
+	FROM users
+	WHERE id = $1`
+
+	var twoFactor TwoFactor
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, userID).Scan(
+		&twoFactor.UserID,
+		&twoFactor.Secret,
+		&twoFactor.Enabled,
+		&twoFactor.LastStep,
+		&twoFactor.LockedUntil,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &twoFactor, nil
+}
+
+// Store a new secret for the user to verify. Enrolling again replaces a secret that wasn't verified yet.
+func (m TwoFactorModel) SetSecret(userID int64, secret string) error {
+	query := `
+	UPDATE users
+	SET totp_secret = $2, totp_last_step = 0
+	WHERE id = $1 AND NOT totp_enabled`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, userID, secret)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrTwoFactorEnabled
+	}
+
+	return nil
+}
+
+// Turn on two-factor authentication once the user verified a code of the given step, and store their recovery codes.
+func (m TwoFactorModel) Enable(userID int64, step int64, recoveryCodes []string) error {
+	query := `
+	UPDATE users
+	SET totp_enabled = true, totp_last_step = $2
+	WHERE id = $1 AND totp_secret IS NOT NULL AND NOT totp_enabled`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrTwoFactorEnabled
+	}
+
+	err = replaceRecoveryCodes(ctx, tx, userID, recoveryCodes)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Turn off two-factor authentication, dropping the secret and the recovery codes.
+func (m TwoFactorModel) Disable(userID int64) error {
+	query := `
+	UPDATE users
+	SET totp_secret = NULL, totp_enabled = false, totp_last_step = 0, totp_failed_attempts = 0, totp_locked_until = NULL
+	WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	err = replaceRecoveryCodes(ctx, tx, userID, nil)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Record that a code of the given step was used. Returns false if a code of that step or a later one was used already,
+// or codes are refused after too many invalid ones, in which case the code must be rejected.
+func (m TwoFactorModel) UseStep(userID int64, step int64) (bool, error) {
+	query := `
+	UPDATE users
+	SET totp_last_step = $2, totp_failed_attempts = 0, totp_locked_until = NULL
+	WHERE id = $1 AND totp_last_step < $2 AND (totp_locked_until IS NULL OR totp_locked_until <= NOW())`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// Use up one of the user's recovery codes. Returns false if the user has no such code, or codes are refused after too
+// many invalid ones.
+func (m TwoFactorModel) UseRecoveryCode(userID int64, code string) (bool, error) {
+	query := `
+	DELETE FROM recovery_codes
+	WHERE user_id = $1 AND hash = $2
+	AND NOT EXISTS (SELECT 1 FROM users WHERE id = $1 AND totp_locked_until > NOW())`
+
+	hash := hashRecoveryCode(code)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, userID, hash)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE users SET totp_failed_attempts = 0, totp_locked_until = NULL WHERE id = $1`, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// Record an invalid code. Once there were too many in a row, codes are refused for a while, and for twice as long
+// with every further invalid code.
+func (m TwoFactorModel) AddFailedAttempt(userID int64) error {
+	query := `
+	UPDATE users
+	SET totp_failed_attempts = totp_failed_attempts + 1,
+		totp_locked_until = CASE
+			WHEN totp_failed_attempts + 1 >= $2
+			THEN NOW() + LEAST($3 * power(2, totp_failed_attempts + 1 - $2), $4) * interval '1 second'
+			ELSE totp_locked_until
+		END
+	WHERE id = $1`
+
+	args := []interface{}{userID, maxFailedAttempts, failedAttemptsLockout.Seconds(), maxFailedAttemptsLockout.Seconds()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, args...)
+
+	return err
+}
+
+// Replace the user's recovery codes with new ones.
+func (m TwoFactorModel) ReplaceRecoveryCodes(userID int64, recoveryCodes []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = replaceRecoveryCodes(ctx, tx, userID, recoveryCodes)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int64, recoveryCodes []string) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	if len(recoveryCodes) == 0 {
+		return nil
+	}
+
+	hashes := make([][]byte, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hashes[i] = hashRecoveryCode(code)
+	}
+
+	query := `
+	INSERT INTO recovery_codes (user_id, hash)
+	SELECT $1, unnest($2::bytea[])`
+
+	_, err = tx.ExecContext(ctx, query, userID, pq.ByteaArray(hashes))
+
+	return err
+}
+
+// Generate a set of recovery codes in the form xxxxx-xxxxx. They're random enough to be stored with a plain hash.
+func GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, recoveryCodesCount)
+
+	for i := range codes {
+		randomBytes := make([]byte, 7)
+
+		_, err := rand.Read(randomBytes)
+		if err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(randomBytes))[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+	}
+
+	return codes, nil
+}
+
+// Codes are compared case-insensitively and without the dash, as users may type them either way.
+func hashRecoveryCode(code string) []byte {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+
+	hash := sha256.Sum256([]byte(normalized))
+	return hash[:]
+}
+
+// Codes are either 6 digit authenticator codes or recovery codes.
+func ValidateTwoFactorCode(v *validator.Validator, code string) {
+	v.Check(code != "", "code", "must be provided")
+	v.Check(len(code) <= 20, "code", "must not be more than 20 bytes long")
+}
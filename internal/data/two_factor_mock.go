@@ -0,0 +1,72 @@
+package data
+
+// Two-factor authentication is off for every user unless MockGet says otherwise.
+type MockTwoFactorModel struct {
+	MockGet              func(userID int64) (*TwoFactor, error)
+	MockSetSecret        func(userID int64, secret string) error
+	MockEnable           func(userID int64, step int64, recoveryCodes []string) error
+	MockDisable          func(userID int64) error
+	MockUseStep          func(userID int64, step int64) (bool, error)
+	MockUseRecoveryCode  func(userID int64, code string) (bool, error)
+	MockAddFailedAttempt func(userID int64) error
+}
+
+func (m MockTwoFactorModel) Get(userID int64) (*TwoFactor, error) {
+	if m.MockGet != nil {
+		return m.MockGet(userID)
+	}
+
+	return &TwoFactor{UserID: userID}, nil
+}
+
+func (m MockTwoFactorModel) SetSecret(userID int64, secret string) error {
+	if m.MockSetSecret != nil {
+		return m.MockSetSecret(userID, secret)
+	}
+
+	return nil
+}
+
+func (m MockTwoFactorModel) Enable(userID int64, step int64, recoveryCodes []string) error {
+	if m.MockEnable != nil {
+		return m.MockEnable(userID, step, recoveryCodes)
+	}
+
+	return nil
+}
+
+func (m MockTwoFactorModel) Disable(userID int64) error {
+	if m.MockDisable != nil {
+		return m.MockDisable(userID)
+	}
+
+	return nil
+}
+
+func (m MockTwoFactorModel) UseStep(userID int64, step int64) (bool, error) {
+	if m.MockUseStep != nil {
+		return m.MockUseStep(userID, step)
+	}
+
+	return true, nil
+}
+
+func (m MockTwoFactorModel) UseRecoveryCode(userID int64, code string) (bool, error) {
+	if m.MockUseRecoveryCode != nil {
+		return m.MockUseRecoveryCode(userID, code)
+	}
+
+	return false, nil
+}
+
+func (m MockTwoFactorModel) AddFailedAttempt(userID int64) error {
+	if m.MockAddFailedAttempt != nil {
+		return m.MockAddFailedAttempt(userID)
+	}
+
+	return nil
+}
+
+func (m MockTwoFactorModel) ReplaceRecoveryCodes(userID int64, recoveryCodes []string) error {
+	return nil
+}
@@ -24,13 +24,44 @@ type User struct {
 	Email     string
 	Password  Password
 	Activated bool
+	Role      string
 	Version   int32
+	// Set when the request was authenticated with a personal access token, which is limited to these permissions.
+	Scopes Permissions
 }
 
 func (u *User) IsAnonymous() bool {
 	return u == AnonymousUser
 }
 
+// Checks whether the user's role grants the given permission code, and for personal access tokens their scopes too.
+func (u *User) HasPermission(code string) bool {
+	if u.Scopes != nil && !u.Scopes.Include(code) {
+		return false
+	}
+
+	return PermissionsForRole(u.Role).Include(code)
+}
+
+// The id to read non-public content as, or 0 to only read public content. It's 0 for personal access tokens without
+// the given read permission.
+func (u *User) ReaderID(code string) int64 {
+	if !u.HasPermission(code) {
+		return 0
+	}
+
+	return u.ID
+}
+
+// Users can modify content they created. Moderators (editors and admins) can modify any content.
+func (u *User) CanModify(ownerID int64) bool {
+	if u.IsAnonymous() {
+		return false
+	}
+
+	return u.ID == ownerID || u.HasPermission(PermissionContentModerate)
+}
+
 type Password struct {
 	plainText *string
 	hash      []byte
@@ -42,11 +73,11 @@ type UserModel struct {
 
 func (u UserModel) Insert(user *User) error {
 	query := `
-	INSERT INTO users (name, email, password_hash, activated)
-	VALUES ($1, $2, $3, $4)
+	INSERT INTO users (name, email, password_hash, activated, role)
+	VALUES ($1, $2, $3, $4, $5)
 	RETURNING id, created_at`
 
-	args := []interface{}{user.Name, user.Email, user.Password.hash, user.Activated}
+	args := []interface{}{user.Name, user.Email, user.Password.hash, user.Activated, user.Role}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -64,9 +95,46 @@ func (u UserModel) Insert(user *User) error {
 	return nil
 }
 
+func (u UserModel) Get(id int64) (*User, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+	SELECT id, created_at, name, email, password_hash, activated, role, version
+	FROM users
+	WHERE id = $1`
+
+	var user User
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := u.DB.QueryRowContext(ctx, query, id).Scan(
+		&user.ID,
+		&user.CreatedAt,
+		&user.Name,
+		&user.Email,
+		&user.Password.hash,
+		&user.Activated,
+		&user.Role,
+		&user.Version,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &user, nil
+}
+
 func (u UserModel) GetByEmail(email string) (*User, error) {
 	query := `
-	SELECT id, created_at, name, email, password_hash, activated, version
+	SELECT id, created_at, name, email, password_hash, activated, role, version
 	FROM users
 	WHERE email = $1`
 
@@ -82,6 +150,7 @@ func (u UserModel) GetByEmail(email string) (*User, error) {
 		&user.Email,
 		&user.Password.hash,
 		&user.Activated,
+		&user.Role,
 		&user.Version,
 	)
 	if err != nil {
@@ -99,14 +168,15 @@ func (u UserModel) GetByEmail(email string) (*User, error) {
 func (u UserModel) Update(user *User) error {
 	query := `
 	UPDATE users
-	SET name = $1, email = $2, password_hash = $3, activated = $4, version = version + 1
-	WHERE id = $5 AND version = $6`
+	SET name = $1, email = $2, password_hash = $3, activated = $4, role = $5, version = version + 1
+	WHERE id = $6 AND version = $7`
 
 	args := []interface{}{
 		user.Name,
 		user.Email,
 		user.Password.hash,
 		user.Activated,
+		user.Role,
 		user.ID,
 		user.Version,
 	}
@@ -138,7 +208,7 @@ func (u UserModel) Update(user *User) error {
 
 func (u UserModel) GetForToken(tokenScope, tokenPlainText string) (*User, error) {
 	query := `
-	SELECT users.id, users.created_at, users.name, users.email, users.password_hash, users.activated, users.version
+	SELECT users.id, users.created_at, users.name, users.email, users.password_hash, users.activated, users.role, users.version
 	FROM users
 	INNER JOIN tokens
 	ON users.id = tokens.user_id
@@ -162,6 +232,7 @@ func (u UserModel) GetForToken(tokenScope, tokenPlainText string) (*User, error)
 		&user.Email,
 		&user.Password.hash,
 		&user.Activated,
+		&user.Role,
 		&user.Version,
 	)
 	if err != nil {
@@ -220,6 +291,7 @@ func ValidateUser(v *validator.Validator, user *User) {
 	v.Check(len(user.Name) <= 100, "name", "name must not be more than 100 bytes long")
 
 	ValidateEmail(v, user.Email)
+	ValidateRole(v, user.Role)
 
 	if user.Password.plainText != nil {
 		ValidatePasswordPlaintext(v, *user.Password.plainText)
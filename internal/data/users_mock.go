@@ -1,6 +1,7 @@
 package data
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -11,6 +12,7 @@ var mockUser = &User{
 	Email:     "Mocked Email",
 	Password:  Password{},
 	Activated: true,
+	Role:      RoleAuthor,
 	Version:   1,
 }
 
@@ -20,14 +22,30 @@ var mockUserModel = MockUserModel{
 }
 
 type MockUserModel struct {
-	UserActivated bool
-	UserAnonymous bool
+	UserActivated   bool
+	UserAnonymous   bool
+	MockGetForToken func(tokenScope, tokenPlainText string) (*User, error)
+	MockUpdate      func(user *User) error
+	MockGet         func(id int64) (*User, error)
 }
 
 func (u MockUserModel) Insert(user *User) error {
 	return nil
 }
 
+func (u MockUserModel) Get(id int64) (*User, error) {
+	if u.MockGet != nil {
+		return u.MockGet(id)
+	}
+
+	if id != mockUser.ID {
+		return nil, ErrRecordNotFound
+	}
+
+	user := *mockUser
+	return &user, nil
+}
+
 func (MockUserModel) GetByEmail(email string) (*User, error) {
 	switch email {
 	case "mocked@email.com":
@@ -40,11 +58,19 @@ func (MockUserModel) GetByEmail(email string) (*User, error) {
 	}
 }
 
-func (MockUserModel) Update(user *User) error {
+func (u MockUserModel) Update(user *User) error {
+	if u.MockUpdate != nil {
+		return u.MockUpdate(user)
+	}
+
 	return nil
 }
 
-func (MockUserModel) GetForToken(tokenScope, tokenPlainText string) (*User, error) {
+func (u MockUserModel) GetForToken(tokenScope, tokenPlainText string) (*User, error) {
+	if u.MockGetForToken != nil {
+		return u.MockGetForToken(tokenScope, tokenPlainText)
+	}
+
 	user := *mockUser
 	user.Activated = mockUserModel.UserActivated
 	switch {
@@ -60,6 +86,37 @@ func (MockUserModel) GetForToken(tokenScope, tokenPlainText string) (*User, erro
 	}
 }
 
+// Users 1 to 4 have a profile. User 1 wrote the mocked post.
+func (MockUserModel) GetProfile(userID int64) (*Profile, error) {
+	if userID < 1 || userID > 4 {
+		return nil, ErrRecordNotFound
+	}
+
+	profile := &Profile{
+		UserID:      userID,
+		CreatedAt:   mockUser.CreatedAt,
+		Name:        fmt.Sprintf("Mocked User %d", userID),
+		SocialLinks: map[string]string{},
+		Version:     1,
+	}
+
+	if userID == 1 {
+		profile.Name = "Mocked User"
+		profile.Bio = "Mocked bio"
+		profile.SocialLinks["github"] = "https://github.com/mocked"
+		profile.PostsCount = 1
+		profile.LikesReceived = mockPost.LikesCount
+		profile.Followers = 1
+	}
+
+	return profile, nil
+}
+
+func (MockUserModel) UpdateProfile(profile *Profile) error {
+	profile.Version++
+	return nil
+}
+
 // SetMockUserPassword sets the password for the mockUser.
 func SetMockUserPassword(passwordHash []byte) {
 	mockUser.Password.hash = passwordHash
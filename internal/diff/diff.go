@@ -0,0 +1,111 @@
+package diff
+
+import (
+	"errors"
+	"strings"
+)
+
+// Operations of a diff line.
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// The diff takes a table of the changed lines of both texts, which is capped at this many cells.
+const maxCells = 1 << 20
+
+var ErrTooLarge = errors.New("texts are too large to diff")
+
+type Line struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines returns a line-based diff which turns text a into text b.
+// It is built from the longest common subsequence of lines, so unchanged lines are kept as "equal".
+// Lines shared at the start and end don't count towards the size limit, so small edits of long texts can be diffed.
+func Lines(a, b string) ([]Line, error) {
+	aLines := splitLines(a)
+	bLines := splitLines(b)
+
+	lines := []Line{}
+
+	prefix := 0
+	for prefix < len(aLines) && prefix < len(bLines) && aLines[prefix] == bLines[prefix] {
+		lines = append(lines, Line{Op: OpEqual, Text: aLines[prefix]})
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(aLines)-prefix && suffix < len(bLines)-prefix && aLines[len(aLines)-1-suffix] == bLines[len(bLines)-1-suffix] {
+		suffix++
+	}
+
+	common := aLines[len(aLines)-suffix:]
+	aLines = aLines[prefix : len(aLines)-suffix]
+	bLines = bLines[prefix : len(bLines)-suffix]
+
+	if (len(aLines)+1)*(len(bLines)+1) > maxCells {
+		return nil, ErrTooLarge
+	}
+
+	// lcs[i][j] holds the length of the longest common subsequence of aLines[i:] and bLines[j:].
+	lcs := make([][]int, len(aLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bLines)+1)
+	}
+
+	for i := len(aLines) - 1; i >= 0; i-- {
+		for j := len(bLines) - 1; j >= 0; j-- {
+			switch {
+			case aLines[i] == bLines[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+
+	for i < len(aLines) && j < len(bLines) {
+		switch {
+		case aLines[i] == bLines[j]:
+			lines = append(lines, Line{Op: OpEqual, Text: aLines[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Op: OpDelete, Text: aLines[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: OpInsert, Text: bLines[j]})
+			j++
+		}
+	}
+
+	for ; i < len(aLines); i++ {
+		lines = append(lines, Line{Op: OpDelete, Text: aLines[i]})
+	}
+
+	for ; j < len(bLines); j++ {
+		lines = append(lines, Line{Op: OpInsert, Text: bLines[j]})
+	}
+
+	for _, text := range common {
+		lines = append(lines, Line{Op: OpEqual, Text: text})
+	}
+
+	return lines, nil
+}
+
+// An empty text has no lines rather than a single empty line.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
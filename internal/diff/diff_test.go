@@ -0,0 +1,99 @@
+package diff
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want []Line
+	}{
+		{
+			name: "Identical texts",
+			a:    "one\ntwo",
+			b:    "one\ntwo",
+			want: []Line{{OpEqual, "one"}, {OpEqual, "two"}},
+		},
+		{
+			name: "Changed line",
+			a:    "one\ntwo\nthree",
+			b:    "one\n2\nthree",
+			want: []Line{{OpEqual, "one"}, {OpDelete, "two"}, {OpInsert, "2"}, {OpEqual, "three"}},
+		},
+		{
+			name: "Appended line",
+			a:    "one",
+			b:    "one\ntwo",
+			want: []Line{{OpEqual, "one"}, {OpInsert, "two"}},
+		},
+		{
+			name: "Removed line",
+			a:    "one\ntwo",
+			b:    "two",
+			want: []Line{{OpDelete, "one"}, {OpEqual, "two"}},
+		},
+		{
+			name: "From empty text",
+			a:    "",
+			b:    "one",
+			want: []Line{{OpInsert, "one"}},
+		},
+		{
+			name: "Windows line endings",
+			a:    "one\r\ntwo",
+			b:    "one\ntwo",
+			want: []Line{{OpEqual, "one"}, {OpEqual, "two"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Lines(tt.a, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, len(got), len(tt.want))
+
+			for i := range got {
+				if i < len(tt.want) {
+					assert.Equal(t, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLinesTooLarge(t *testing.T) {
+	a := make([]string, 2000)
+	b := make([]string, 2000)
+	for i := range a {
+		a[i] = "a" + strconv.Itoa(i)
+		b[i] = "b" + strconv.Itoa(i)
+	}
+
+	_, err := Lines(strings.Join(a, "\n"), strings.Join(b, "\n"))
+	assert.Equal(t, errors.Is(err, ErrTooLarge), true)
+
+	// Only the changed lines count, so a single edit in a long text can be diffed.
+	edited := append([]string{}, a...)
+	edited[1000] = "changed"
+
+	got, err := Lines(strings.Join(a, "\n"), strings.Join(edited, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, len(got), 2001)
+	assert.Equal(t, got[1000], Line{OpDelete, "a1000"})
+	assert.Equal(t, got[1001], Line{OpInsert, "changed"})
+	assert.Equal(t, got[2000], Line{OpEqual, "a1999"})
+}
@@ -1,14 +1,58 @@
 package dto
 
+import (
+	"time"
+)
+
 type CommentRequestBody struct {
-	Text   string `json:"text"`
-	PostID int64  `json:"post"`
+	Text     string `json:"text"`
+	PostID   int64  `json:"post"`
+	ParentID *int64 `json:"parentId"`
+}
+
+type UpdateCommentRequestBody struct {
+	Text *string `json:"text"`
 }
 
+// Replies is only filled in the tree view of a post's comments, the flat view lists them in thread order instead.
+// Reactions is only filled in when reading comments, not in the responses of changes to them.
 type CommentResponseBody struct {
-	ID        int64  `json:"id"`
-	Text      string `json:"text"`
-	CreatedBy int64  `json:"createdBy"`
-	PostID    int64  `json:"post"`
-	UserName  string `json:"userName"`
+	ID         int64                  `json:"id"`
+	Text       string                 `json:"text"`
+	CreatedBy  int64                  `json:"createdBy"`
+	PostID     int64                  `json:"post"`
+	ParentID   *int64                 `json:"parentId"`
+	Depth      int                    `json:"depth"`
+	Deleted    bool                   `json:"deleted,omitempty"`
+	EditedAt   *time.Time             `json:"editedAt,omitempty"`
+	LikesCount int                    `json:"likesCount"`
+	Status     string                 `json:"status"`
+	UserName   string                 `json:"userName"`
+	Reactions  *ReactionSummary       `json:"reactions,omitempty"`
+	Replies    []*CommentResponseBody `json:"replies,omitempty"`
+	// Set when the comment has more replies than were loaded with it, see showCommentRepliesHandler.
+	MoreReplies bool `json:"moreReplies,omitempty"`
+}
+
+type CommentEditResponseBody struct {
+	Text     string    `json:"text"`
+	EditedBy int64     `json:"editedBy"`
+	UserName string    `json:"userName"`
+	EditedAt time.Time `json:"editedAt"`
+}
+
+type ModerateCommentsRequestBody struct {
+	IDs    []int64 `json:"ids"`
+	Status string  `json:"status"`
+}
+
+// A nil Mode makes the post follow the global moderation mode again.
+type PostModerationRequestBody struct {
+	Mode *string `json:"mode"`
+}
+
+type PostModerationResponseBody struct {
+	PostID        int64   `json:"post"`
+	Mode          *string `json:"mode"`
+	EffectiveMode string  `json:"effectiveMode"`
 }
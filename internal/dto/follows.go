@@ -0,0 +1,18 @@
+package dto
+
+import (
+	"time"
+)
+
+type FollowResponseBody struct {
+	UserID     int64     `json:"userId"`
+	UserName   string    `json:"userName"`
+	FollowedAt time.Time `json:"followedAt"`
+}
+
+// FollowedByMe tells whether the current user follows the user the counts belong to.
+type FollowCountsResponseBody struct {
+	Followers    int  `json:"followers"`
+	Following    int  `json:"following"`
+	FollowedByMe bool `json:"followedByMe"`
+}
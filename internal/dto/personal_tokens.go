@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"time"
+)
+
+// Token is only set in the response to creating the token, it can't be shown again.
+type PersonalTokenResponseBody struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	Expiry     *time.Time `json:"expiry"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	LastUsedIP string     `json:"lastUsedIP"`
+	Token      string     `json:"token,omitempty"`
+}
+
+type CreatePersonalTokenRequestBody struct {
+	Name   string     `json:"name"`
+	Scopes []string   `json:"scopes"`
+	Expiry *time.Time `json:"expiry"`
+}
@@ -9,6 +9,7 @@ type CreatePostRequestBody struct {
 	PostText string   `json:"postText"`
 	ReadTime ReadTime `json:"readTime"`
 	Img      string   `json:"img"`
+	Tags     []string `json:"tags"`
 }
 
 // Define the input struct in a way, all the field got zero value 'nil'.
@@ -17,16 +18,64 @@ type UpdatePostRequestBody struct {
 	PostText *string   `json:"postText"`
 	ReadTime *ReadTime `json:"readTime"`
 	Img      *string   `json:"img"`
+	Tags     *[]string `json:"tags"`
 }
 
+// Reactions is only filled in when reading posts, not in the responses of changes to them.
 type PostResponseBody struct {
-	ID        int64     `json:"id"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID             int64            `json:"id"`
+	CreatedAt      time.Time        `json:"createdAt"`
+	Title          string           `json:"title"`
+	Slug           string           `json:"slug"`
+	PostText       string           `json:"postText"`
+	Img            string           `json:"img"`
+	ReadTime       ReadTime         `json:"readTime"` // If we use our custom ReadTime type here (which has the underlying type int32) go will use ReadTime type's method MarshalJSON to encode this to JSON and it will be encoded to ReadTime type (a string in the format "<readtime> mins") instead of int.
+	LikesCount     int              `json:"likesCount"`
+	LikedByMe      bool             `json:"likedByMe"`
+	BookmarkedByMe bool             `json:"bookmarkedByMe"`
+	Tags           []string         `json:"tags"`
+	Status         string           `json:"status"`
+	PublishAt      *time.Time       `json:"publishAt,omitempty"`
+	PublishedAt    *time.Time       `json:"publishedAt,omitempty"`
+	CommentsCount  int              `json:"commentsCount"`
+	Hidden         bool             `json:"hidden,omitempty"`
+	Reactions      *ReactionSummary `json:"reactions,omitempty"`
+	CreatedBy      int64            `json:"createdBy"`
+	UserName       string           `json:"userName"`
+}
+
+type SchedulePostRequestBody struct {
+	PublishAt *time.Time `json:"publishAt"`
+}
+
+type PostStatusChangeResponseBody struct {
+	FromStatus string    `json:"fromStatus"`
+	ToStatus   string    `json:"toStatus"`
+	ChangedBy  int64     `json:"changedBy"`
+	UserName   string    `json:"userName"`
+	ChangedAt  time.Time `json:"changedAt"`
+}
+
+type PostRevisionResponseBody struct {
+	Revision  int32     `json:"revision"`
 	Title     string    `json:"title"`
-	PostText  string    `json:"postText"`
-	Img       string    `json:"img"`
-	ReadTime  ReadTime  `json:"readTime"` // If we use our custom ReadTime type here (which has the underlying type int32) go will use ReadTime type's method MarshalJSON to encode this to JSON and it will be encoded to ReadTime type (a string in the format "<readtime> mins") instead of int.
-	LikedBy   []int64   `json:"likedBy,omitempty"`
+	PostText  string    `json:"postText,omitempty"`
 	CreatedBy int64     `json:"createdBy"`
 	UserName  string    `json:"userName"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Title and Snippet are HTML: the post's text escaped, with the matched words wrapped in <mark> tags.
+type SearchResultResponseBody struct {
+	ID          int64      `json:"id"`
+	Title       string     `json:"title"`
+	Slug        string     `json:"slug"`
+	Snippet     string     `json:"snippet"`
+	Img         string     `json:"img"`
+	ReadTime    ReadTime   `json:"readTime"`
+	Tags        []string   `json:"tags"`
+	Rank        float64    `json:"rank"`
+	PublishedAt *time.Time `json:"publishedAt,omitempty"`
+	CreatedBy   int64      `json:"createdBy"`
+	UserName    string     `json:"userName"`
 }
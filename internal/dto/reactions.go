@@ -0,0 +1,18 @@
+package dto
+
+import (
+	"time"
+)
+
+// Counts holds the number of users who reacted with each emoji, Mine the emojis the current user reacted with.
+type ReactionSummary struct {
+	Counts map[string]int `json:"counts"`
+	Mine   []string       `json:"mine"`
+}
+
+type ReactionResponseBody struct {
+	UserID    int64     `json:"userId"`
+	UserName  string    `json:"userName"`
+	Emoji     string    `json:"emoji"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"time"
+)
+
+type CreateReadingListRequestBody struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Public      bool   `json:"public"`
+}
+
+type UpdateReadingListRequestBody struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Public      *bool   `json:"public"`
+}
+
+type ReadingListResponseBody struct {
+	ID          int64     `json:"id"`
+	UserID      int64     `json:"userId"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Public      bool      `json:"public"`
+	ItemsCount  int       `json:"itemsCount"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type AddReadingListItemRequestBody struct {
+	PostID int64  `json:"post"`
+	Note   string `json:"note"`
+}
+
+// Moving an item to another position shifts the items in between.
+type UpdateReadingListItemRequestBody struct {
+	Note     *string `json:"note"`
+	Position *int    `json:"position"`
+}
+
+type ReadingListItemResponseBody struct {
+	Position int               `json:"position"`
+	Note     string            `json:"note"`
+	AddedAt  time.Time         `json:"addedAt"`
+	Post     *PostResponseBody `json:"post"`
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+)
+
+type CreateReportRequestBody struct {
+	TargetType string `json:"targetType"`
+	TargetID   int64  `json:"targetId"`
+	Reason     string `json:"reason"`
+	Details    string `json:"details"`
+}
+
+type ResolveReportRequestBody struct {
+	Action string `json:"action"`
+	Note   string `json:"note"`
+}
+
+type ReportResponseBody struct {
+	ID           int64      `json:"id"`
+	TargetType   string     `json:"targetType"`
+	TargetID     int64      `json:"targetId"`
+	ReporterID   int64      `json:"reporterId"`
+	ReporterName string     `json:"reporterName,omitempty"`
+	Reason       string     `json:"reason"`
+	Details      string     `json:"details"`
+	Status       string     `json:"status"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	ResolvedBy   *int64     `json:"resolvedBy,omitempty"`
+	ResolvedAt   *time.Time `json:"resolvedAt,omitempty"`
+}
+
+// ActorID is empty for actions taken automatically, like hiding content which crossed the report threshold.
+type ReportActionResponseBody struct {
+	Action    string    `json:"action"`
+	ActorID   *int64    `json:"actorId"`
+	ActorName string    `json:"actorName"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"createdAt"`
+}
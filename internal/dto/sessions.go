@@ -0,0 +1,16 @@
+package dto
+
+import (
+	"time"
+)
+
+// Current marks the session of the token the request was made with.
+type SessionResponseBody struct {
+	ID         int64      `json:"id"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	Expiry     time.Time  `json:"expiry"`
+	IP         string     `json:"ip"`
+	UserAgent  string     `json:"userAgent"`
+	Current    bool       `json:"current"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type TagResponseBody struct {
+	Name       string `json:"name"`
+	PostsCount int    `json:"postsCount"`
+}
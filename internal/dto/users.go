@@ -1,7 +1,39 @@
 package dto
 
+import (
+	"time"
+)
+
 type RegisterUserRequestBody struct {
 	Name     string `json:"name"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
+
+// SocialLinks replaces all the links of the user when it's given.
+type UpdateProfileRequestBody struct {
+	DisplayName *string            `json:"displayName"`
+	Bio         *string            `json:"bio"`
+	AvatarURL   *string            `json:"avatarUrl"`
+	Website     *string            `json:"website"`
+	SocialLinks *map[string]string `json:"socialLinks"`
+}
+
+type ProfileStatsResponseBody struct {
+	Posts         int `json:"posts"`
+	LikesReceived int `json:"likesReceived"`
+	Followers     int `json:"followers"`
+	Following     int `json:"following"`
+}
+
+type ProfileResponseBody struct {
+	ID          int64                    `json:"id"`
+	Name        string                   `json:"name"`
+	DisplayName string                   `json:"displayName"`
+	Bio         string                   `json:"bio"`
+	AvatarURL   string                   `json:"avatarUrl"`
+	Website     string                   `json:"website"`
+	SocialLinks map[string]string        `json:"socialLinks"`
+	Stats       ProfileStatsResponseBody `json:"stats"`
+	CreatedAt   time.Time                `json:"createdAt"`
+}
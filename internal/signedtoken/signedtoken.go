@@ -0,0 +1,226 @@
+package signedtoken
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Signed tokens have the JWT compact form: base64url(header).base64url(claims).base64url(signature).
+// Only the two algorithms below are supported, and the header's alg must match the algorithm of the key named by its kid,
+// so a token can never pick how it gets verified.
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid signed token")
+	ErrExpiredToken = errors.New("expired signed token")
+)
+
+var encoding = base64.RawURLEncoding
+
+// Claims carry everything the authenticate middleware needs to know about the user, so it doesn't have to look them up.
+type Claims struct {
+	UserID    int64  `json:"sub"`
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	Activated bool   `json:"act"`
+	// The session (token family) the token belongs to, so it can be revoked along with the session.
+	Session   int64 `json:"sid"`
+	IssuedAt  int64 `json:"iat"`
+	ExpiresAt int64 `json:"exp"`
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid"`
+}
+
+type Key struct {
+	ID        string
+	Algorithm string
+	secret    []byte
+	private   ed25519.PrivateKey
+	public    ed25519.PublicKey
+}
+
+// HMAC secrets must be at least 32 bytes long.
+func NewHMACKey(id string, secret []byte) (*Key, error) {
+	if len(secret) < 32 {
+		return nil, fmt.Errorf("key %q: HMAC secret must be at least 32 bytes long", id)
+	}
+
+	return &Key{ID: id, Algorithm: AlgorithmHS256, secret: secret}, nil
+}
+
+// The seed is the 32 byte private key seed of RFC 8032.
+func NewEd25519Key(id string, seed []byte) (*Key, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("key %q: Ed25519 seed must be %d bytes long", id, ed25519.SeedSize)
+	}
+
+	private := ed25519.NewKeyFromSeed(seed)
+
+	return &Key{ID: id, Algorithm: AlgorithmEdDSA, private: private, public: private.Public().(ed25519.PublicKey)}, nil
+}
+
+func (k *Key) sign(message []byte) []byte {
+	switch k.Algorithm {
+	case AlgorithmEdDSA:
+		return ed25519.Sign(k.private, message)
+	default:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(message)
+		return mac.Sum(nil)
+	}
+}
+
+func (k *Key) verify(message, signature []byte) bool {
+	switch k.Algorithm {
+	case AlgorithmEdDSA:
+		return ed25519.Verify(k.public, message, signature)
+	default:
+		return hmac.Equal(k.sign(message), signature)
+	}
+}
+
+// A KeySet signs with its first key and verifies with any of them. Keys are rotated by putting a new key first and
+// keeping the old ones until the tokens they signed have expired.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+func NewKeySet(keys ...*Key) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one key is required")
+	}
+
+	ks := &KeySet{signing: keys[0], keys: make(map[string]*Key)}
+
+	for _, key := range keys {
+		if _, exists := ks.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		ks.keys[key.ID] = key
+	}
+
+	return ks, nil
+}
+
+// Parse a space separated list of keys in the form kid:alg:base64-key, where alg is HS256 or EdDSA.
+// The key is the HMAC secret or the Ed25519 seed, in standard base64.
+func ParseKeySet(spec string) (*KeySet, error) {
+	var keys []*Key
+
+	for _, field := range strings.Fields(spec) {
+		parts := strings.SplitN(field, ":", 3)
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("key %q must be in the form kid:alg:base64-key", field)
+		}
+
+		material, err := base64.StdEncoding.DecodeString(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", parts[0], err)
+		}
+
+		var key *Key
+
+		switch parts[1] {
+		case AlgorithmHS256:
+			key, err = NewHMACKey(parts[0], material)
+		case AlgorithmEdDSA:
+			key, err = NewEd25519Key(parts[0], material)
+		default:
+			err = fmt.Errorf("key %q: unsupported algorithm %q", parts[0], parts[1])
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return NewKeySet(keys...)
+}
+
+func (ks *KeySet) Sign(claims Claims) (string, error) {
+	h, err := json.Marshal(header{Algorithm: ks.signing.Algorithm, Type: "JWT", KeyID: ks.signing.ID})
+	if err != nil {
+		return "", err
+	}
+
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	message := encoding.EncodeToString(h) + "." + encoding.EncodeToString(c)
+
+	return message + "." + encoding.EncodeToString(ks.signing.sign([]byte(message))), nil
+}
+
+// Verify the token's signature and expiry at the given time, and return its claims.
+func (ks *KeySet) Verify(token string, now time.Time) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var h header
+
+	err := decodeJSON(parts[0], &h)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	key, ok := ks.keys[h.KeyID]
+	if !ok || key.Algorithm != h.Algorithm {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := encoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if !key.verify([]byte(parts[0]+"."+parts[1]), signature) {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+
+	err = decodeJSON(parts[1], &claims)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+
+	return &claims, nil
+}
+
+// Opaque tokens are base32 and never contain dots.
+func IsSigned(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+func decodeJSON(segment string, dst interface{}) error {
+	b, err := encoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, dst)
+}
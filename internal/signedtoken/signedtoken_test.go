@@ -0,0 +1,151 @@
+package signedtoken
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+)
+
+var (
+	hmacSecret = bytes.Repeat([]byte("s"), 32)
+	edSeed     = bytes.Repeat([]byte("e"), 32)
+)
+
+func newKeySet(t *testing.T, keys ...*Key) *KeySet {
+	ks, err := NewKeySet(keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ks
+}
+
+func newKey(t *testing.T, id, algorithm string) *Key {
+	var key *Key
+	var err error
+
+	switch algorithm {
+	case AlgorithmEdDSA:
+		key, err = NewEd25519Key(id, edSeed)
+	default:
+		key, err = NewHMACKey(id, hmacSecret)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Now()
+	claims := Claims{UserID: 1, Name: "Mocked Name", Role: "author", Activated: true, Session: 7, IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Minute).Unix()}
+
+	for _, algorithm := range []string{AlgorithmHS256, AlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			ks := newKeySet(t, newKey(t, "1", algorithm))
+
+			token, err := ks.Sign(claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, IsSigned(token), true)
+
+			got, err := ks.Verify(token, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, *got, claims)
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	claims := Claims{UserID: 1, Session: 7, IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Minute).Unix()}
+
+	ks := newKeySet(t, newKey(t, "1", AlgorithmHS256))
+
+	token, err := ks.Sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := strings.Split(token, ".")
+
+	// A token naming the HMAC key but claiming to be signed with EdDSA.
+	wrongAlg := encoding.EncodeToString([]byte(`{"alg":"EdDSA","typ":"JWT","kid":"1"}`)) + "." + parts[1] + "." + parts[2]
+	tampered := parts[0] + "." + encoding.EncodeToString([]byte(`{"sub":2,"sid":7,"exp":9999999999}`)) + "." + parts[2]
+
+	tests := []struct {
+		name  string
+		ks    *KeySet
+		token string
+		now   time.Time
+		want  error
+	}{
+		{"Expired", ks, token, now.Add(time.Minute), ErrExpiredToken},
+		{"Tampered claims", ks, tampered, now, ErrInvalidToken},
+		{"Algorithm mismatch", ks, wrongAlg, now, ErrInvalidToken},
+		{"Unknown key", newKeySet(t, newKey(t, "2", AlgorithmHS256)), token, now, ErrInvalidToken},
+		{"Not signed", ks, "ABCDEFGHIJKLMNOPQRSTUVWXYZ", now, ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.ks.Verify(tt.token, tt.now)
+
+			assert.Equal(t, errors.Is(err, tt.want), true)
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	now := time.Now()
+	claims := Claims{UserID: 1, ExpiresAt: now.Add(time.Minute).Unix()}
+
+	old := newKey(t, "old", AlgorithmHS256)
+	current := newKey(t, "new", AlgorithmEdDSA)
+
+	token, err := newKeySet(t, old).Sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Tokens signed with the old key still verify after the new key was put first.
+	_, err = newKeySet(t, current, old).Verify(token, now)
+	assert.Equal(t, err == nil, true)
+}
+
+func TestParseKeySet(t *testing.T) {
+	secret := base64.StdEncoding.EncodeToString(hmacSecret)
+	seed := base64.StdEncoding.EncodeToString(edSeed)
+
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{"Both algorithms", "b:EdDSA:" + seed + " a:HS256:" + secret, false},
+		{"No keys", "", true},
+		{"Missing algorithm", "a:" + secret, true},
+		{"Unsupported algorithm", "a:RS256:" + secret, true},
+		{"Short secret", "a:HS256:" + base64.StdEncoding.EncodeToString([]byte("short")), true},
+		{"Duplicate key id", "a:HS256:" + secret + " a:EdDSA:" + seed, true},
+		{"Invalid base64", "a:HS256:!!!", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseKeySet(tt.spec)
+
+			assert.Equal(t, err != nil, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Letters which don't decompose into an ASCII letter and a combining mark.
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'ø': "o", 'œ': "oe", 'ł': "l", 'đ': "d", 'ð': "d", 'þ': "th", 'ı': "i",
+	// Cyrillic, including the letters used by Uzbek, Russian and Ukrainian.
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'ғ': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'қ': "q", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ў': "o", 'ф': "f", 'х': "kh", 'ҳ': "h", 'ц': "ts", 'ч': "ch", 'ш': "sh",
+	'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye",
+	'ґ': "g",
+	// Greek.
+	'α': "a", 'β': "b", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i", 'κ': "k",
+	'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t",
+	'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// Make turns text into a lowercase slug containing only ASCII letters, digits and single dashes.
+// Accented letters lose their accents and Cyrillic and Greek letters are transliterated. Any other character is a separator.
+func Make(text string) string {
+	var b strings.Builder
+
+	// Start as if a dash was just written so that leading separators are dropped.
+	dash := true
+
+	write := func(s string) {
+		if s == "" {
+			return
+		}
+		b.WriteString(s)
+		dash = false
+	}
+
+	for _, r := range strings.ToLower(text) {
+		// Transliterate before decomposing, so letters like 'й' aren't reduced to their base letter.
+		if t, ok := transliterations[r]; ok {
+			write(t)
+			continue
+		}
+
+		// NFD splits accented letters into a base letter followed by combining marks, which are skipped.
+		for _, d := range norm.NFD.String(string(r)) {
+			t, ok := transliterations[d]
+
+			switch {
+			case d < unicode.MaxASCII && (unicode.IsLetter(d) || unicode.IsDigit(d)):
+				write(string(d))
+			case ok:
+				write(t)
+			case unicode.Is(unicode.Mn, d):
+				continue
+			case !dash:
+				b.WriteRune('-')
+				dash = true
+			}
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
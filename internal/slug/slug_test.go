@@ -0,0 +1,36 @@
+package slug
+
+import (
+	"testing"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"Lowercase word", "go", "go"},
+		{"Uppercase word", "PostgreSQL", "postgresql"},
+		{"Spaces", "Hello World", "hello-world"},
+		{"Repeated separators", "Hello -- World!!", "hello-world"},
+		{"Leading and trailing separators", "  #go# ", "go"},
+		{"Digits", "Go 1.18", "go-1-18"},
+		{"Only separators", "!!!", ""},
+		{"Accented letters", "Crème Brûlée à la française", "creme-brulee-a-la-francaise"},
+		{"Special Latin letters", "Straße Ørsted Łódź", "strasse-orsted-lodz"},
+		{"Cyrillic", "Привет мир", "privet-mir"},
+		{"Uzbek Cyrillic", "Ўзбекистон", "ozbekiston"},
+		{"Greek", "Καλημέρα", "kalimera"},
+		{"Unsupported script", "日本語 go", "go"},
+		{"Empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, Make(tt.text), tt.want)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package tsquery
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Parse turns a user search string into PostgreSQL to_tsquery syntax. Terms are combined with AND.
+//
+//	"exact phrase"  words that must follow each other
+//	prefix*         any word starting with prefix
+//	-word           posts which don't contain word
+//
+// Only letters and digits reach the output, so the result is always a well-formed query.
+// It returns an empty string when the search has no usable terms.
+func Parse(search string) string {
+	var terms []string
+	var negated []string
+
+	for len(search) > 0 {
+		search = strings.TrimLeftFunc(search, unicode.IsSpace)
+		if search == "" {
+			break
+		}
+
+		negate := false
+		if search[0] == '-' {
+			negate = true
+			search = search[1:]
+		}
+
+		var term string
+
+		if strings.HasPrefix(search, `"`) {
+			// A phrase runs until the closing quote or the end of the search.
+			end := strings.Index(search[1:], `"`)
+			if end == -1 {
+				term = phrase(search[1:], false)
+				search = ""
+			} else {
+				term = phrase(search[1:end+1], false)
+				search = search[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(search, unicode.IsSpace)
+			if end == -1 {
+				end = len(search)
+			}
+
+			word := search[:end]
+			search = search[end:]
+
+			term = phrase(strings.TrimSuffix(word, "*"), strings.HasSuffix(word, "*"))
+		}
+
+		if term == "" {
+			continue
+		}
+
+		if negate {
+			negated = append(negated, "!"+group(term))
+		} else {
+			terms = append(terms, group(term))
+		}
+	}
+
+	// A query made only of negations would match almost every post.
+	if len(terms) == 0 {
+		return ""
+	}
+
+	return strings.Join(append(terms, negated...), " & ")
+}
+
+// Split text into words and join them with the followed-by operator. Characters other than letters
+// and digits separate words, so "node.js" becomes a two-word phrase.
+func phrase(text string, prefix bool) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	if len(words) == 0 {
+		return ""
+	}
+
+	if prefix {
+		words[len(words)-1] += ":*"
+	}
+
+	return strings.Join(words, " <-> ")
+}
+
+func group(term string) string {
+	if strings.Contains(term, " ") {
+		return "(" + term + ")"
+	}
+	return term
+}
@@ -0,0 +1,36 @@
+package tsquery
+
+import (
+	"testing"
+
+	"github.com/AthfanFasee/blog-post-backend/internal/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		search string
+		want   string
+	}{
+		{"Single word", "golang", "golang"},
+		{"Several words", "go  postgres", "go & postgres"},
+		{"Phrase", `"graceful shutdown"`, "(graceful <-> shutdown)"},
+		{"Phrase and word", `"rate limiting" go`, "(rate <-> limiting) & go"},
+		{"Unclosed phrase", `"rate limiting`, "(rate <-> limiting)"},
+		{"Prefix", "postg*", "postg:*"},
+		{"Negation", "go -java", "go & !java"},
+		{"Negated phrase", `go -"spring boot"`, "go & !(spring <-> boot)"},
+		{"Only negation", "-java", ""},
+		{"Punctuation inside word", "node.js", "(node <-> js)"},
+		{"Operators are stripped", "go & !| postgres:*", "go & postgres:*"},
+		{"Quotes are stripped", `it's`, "(it <-> s)"},
+		{"Unicode letters", "привет", "привет"},
+		{"Empty", "   ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, Parse(tt.search), tt.want)
+		})
+	}
+}
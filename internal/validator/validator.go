@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"net/url"
 	"regexp"
 )
 
@@ -61,3 +62,13 @@ func In(value string, list ...string) bool {
 	}
 	return false
 }
+
+// Returns true if a string is an absolute http or https URL.
+func URL(value string) bool {
+	u, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
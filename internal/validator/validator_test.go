@@ -50,6 +50,23 @@ func TestIn(t *testing.T) {
 	})
 }
 
+func TestURL(t *testing.T) {
+	t.Run("valid URL", func(t *testing.T) {
+		got := URL("https://example.com/me")
+		assert.Equal(t, got, true)
+	})
+
+	t.Run("relative URL", func(t *testing.T) {
+		got := URL("/me")
+		assert.Equal(t, got, false)
+	})
+
+	t.Run("unsupported scheme", func(t *testing.T) {
+		got := URL("javascript:alert(1)")
+		assert.Equal(t, got, false)
+	})
+}
+
 func TestValidator(t *testing.T) {
 	t.Run("valid Validator", func(t *testing.T) {
 		v := New()
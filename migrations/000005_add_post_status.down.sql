@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS post_status_history;
+DROP INDEX IF EXISTS posts_status_idx;
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS posts_status_check;
+ALTER TABLE posts DROP COLUMN IF EXISTS published_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS status;
@@ -0,0 +1,21 @@
+-- Existing posts were already public, so they are backfilled as published before new posts default to draft.
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'published';
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS published_at timestamp(0) with time zone;
+UPDATE posts SET published_at = created_at WHERE status = 'published' AND published_at IS NULL;
+ALTER TABLE posts ALTER COLUMN status SET DEFAULT 'draft';
+
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS posts_status_check;
+ALTER TABLE posts ADD CONSTRAINT posts_status_check CHECK (status IN ('draft', 'in_review', 'published', 'archived'));
+
+CREATE INDEX IF NOT EXISTS posts_status_idx ON posts(status);
+
+CREATE TABLE IF NOT EXISTS "post_status_history" (
+"id" bigserial PRIMARY KEY,
+"post_id" bigint NOT NULL REFERENCES posts ON DELETE CASCADE,
+"from_status" text NOT NULL,
+"to_status" text NOT NULL,
+"changed_by" bigint REFERENCES users ON DELETE SET NULL,
+"changed_at" timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS post_status_history_postid_idx ON post_status_history(post_id);
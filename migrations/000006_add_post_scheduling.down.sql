@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS posts_publishat_idx;
+UPDATE posts SET status = 'in_review' WHERE status = 'scheduled';
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS posts_status_check;
+ALTER TABLE posts ADD CONSTRAINT posts_status_check CHECK (status IN ('draft', 'in_review', 'published', 'archived'));
+ALTER TABLE posts DROP COLUMN IF EXISTS publish_at;
@@ -0,0 +1,7 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS publish_at timestamp(0) with time zone;
+
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS posts_status_check;
+ALTER TABLE posts ADD CONSTRAINT posts_status_check CHECK (status IN ('draft', 'in_review', 'scheduled', 'published', 'archived'));
+
+-- The scheduler only ever looks at scheduled posts ordered by publish_at.
+CREATE INDEX IF NOT EXISTS posts_publishat_idx ON posts(publish_at) WHERE status = 'scheduled';
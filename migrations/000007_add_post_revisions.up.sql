@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS "post_revisions" (
+"id" bigserial PRIMARY KEY,
+"post_id" bigint NOT NULL REFERENCES posts ON DELETE CASCADE,
+"revision" integer NOT NULL,
+"title" text NOT NULL,
+"post_text" text NOT NULL,
+"created_by" bigint REFERENCES users ON DELETE SET NULL,
+"created_at" timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+UNIQUE ("post_id", "revision")
+);
+
+-- The current text of every existing post becomes its first revision.
+INSERT INTO post_revisions (post_id, revision, title, post_text, created_by, created_at)
+SELECT id, 1, title, post_text, created_by, created_at FROM posts
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS "tags" (
+"id" bigserial PRIMARY KEY,
+"name" text UNIQUE NOT NULL,
+"created_at" timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS "post_tags" (
+"post_id" bigint NOT NULL REFERENCES posts ON DELETE CASCADE,
+"tag_id" bigint NOT NULL REFERENCES tags ON DELETE CASCADE,
+PRIMARY KEY ("post_id", "tag_id")
+);
+
+CREATE INDEX IF NOT EXISTS post_tags_tagid_idx ON post_tags(tag_id);
@@ -0,0 +1,23 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS slug text;
+
+-- Existing posts get an ASCII slug from their title, suffixed with their id so it is unique.
+UPDATE posts
+SET slug = COALESCE(NULLIF(trim(both '-' from lower(regexp_replace(title, '[^a-zA-Z0-9]+', '-', 'g'))), ''), 'post') || '-' || id
+WHERE slug IS NULL;
+
+ALTER TABLE posts ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS posts_slug_key;
+ALTER TABLE posts ADD CONSTRAINT posts_slug_key UNIQUE (slug);
+
+-- Every slug a post ever had, so old permalinks keep resolving after a post is renamed.
+CREATE TABLE IF NOT EXISTS "post_slugs" (
+"slug" text PRIMARY KEY,
+"post_id" bigint NOT NULL REFERENCES posts ON DELETE CASCADE,
+"created_at" timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS post_slugs_postid_idx ON post_slugs(post_id);
+
+INSERT INTO post_slugs (slug, post_id)
+SELECT slug, id FROM posts
+ON CONFLICT DO NOTHING;
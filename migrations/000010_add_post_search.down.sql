@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS posts_title_idx;
+CREATE INDEX IF NOT EXISTS posts_title_idx ON posts USING GIN (to_tsvector('simple', title));
+
+DROP INDEX IF EXISTS posts_searchvector_idx;
+ALTER TABLE posts DROP COLUMN IF EXISTS search_vector;
+
+DROP TRIGGER IF EXISTS post_tags_search_tags_trigger ON post_tags;
+DROP FUNCTION IF EXISTS post_tags_update_posts_search_tags();
+DROP TRIGGER IF EXISTS users_posts_search_author_trigger ON users;
+DROP FUNCTION IF EXISTS users_update_posts_search_author();
+DROP TRIGGER IF EXISTS posts_search_author_trigger ON posts;
+DROP FUNCTION IF EXISTS posts_set_search_author();
+
+ALTER TABLE posts DROP COLUMN IF EXISTS search_author;
+ALTER TABLE posts DROP COLUMN IF EXISTS search_tags;
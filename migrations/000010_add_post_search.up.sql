@@ -0,0 +1,79 @@
+-- Tags and the author's name live in other tables, so they are copied onto posts by triggers
+-- and can be part of the stored search vector.
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS search_tags text NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS search_author text NOT NULL DEFAULT '';
+
+CREATE OR REPLACE FUNCTION posts_set_search_author() RETURNS trigger AS $$
+BEGIN
+	NEW.search_author := (SELECT name FROM users WHERE id = NEW.created_by);
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS posts_search_author_trigger ON posts;
+CREATE TRIGGER posts_search_author_trigger
+BEFORE INSERT OR UPDATE OF created_by ON posts
+FOR EACH ROW EXECUTE FUNCTION posts_set_search_author();
+
+CREATE OR REPLACE FUNCTION users_update_posts_search_author() RETURNS trigger AS $$
+BEGIN
+	UPDATE posts SET search_author = NEW.name WHERE created_by = NEW.id;
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS users_posts_search_author_trigger ON users;
+CREATE TRIGGER users_posts_search_author_trigger
+AFTER UPDATE OF name ON users
+FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+EXECUTE FUNCTION users_update_posts_search_author();
+
+-- Tag names are slugs, so dashes are turned back into spaces to index every word.
+CREATE OR REPLACE FUNCTION post_tags_update_posts_search_tags() RETURNS trigger AS $$
+DECLARE
+	changed_post_id bigint;
+BEGIN
+	IF TG_OP = 'DELETE' THEN
+		changed_post_id := OLD.post_id;
+	ELSE
+		changed_post_id := NEW.post_id;
+	END IF;
+
+	UPDATE posts SET search_tags = COALESCE((
+		SELECT string_agg(replace(t.name, '-', ' '), ' ')
+		FROM post_tags pt
+		INNER JOIN tags t ON pt.tag_id = t.id
+		WHERE pt.post_id = changed_post_id
+	), '')
+	WHERE id = changed_post_id;
+
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS post_tags_search_tags_trigger ON post_tags;
+CREATE TRIGGER post_tags_search_tags_trigger
+AFTER INSERT OR DELETE ON post_tags
+FOR EACH ROW EXECUTE FUNCTION post_tags_update_posts_search_tags();
+
+UPDATE posts p SET search_author = u.name FROM users u WHERE p.created_by = u.id;
+
+UPDATE posts p SET search_tags = COALESCE((
+	SELECT string_agg(replace(t.name, '-', ' '), ' ')
+	FROM post_tags pt
+	INNER JOIN tags t ON pt.tag_id = t.id
+	WHERE pt.post_id = p.id
+), '');
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('english', title), 'A') ||
+	setweight(to_tsvector('english', search_tags), 'B') ||
+	setweight(to_tsvector('english', post_text), 'C') ||
+	setweight(to_tsvector('english', search_author), 'D')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS posts_searchvector_idx ON posts USING GIN (search_vector);
+
+-- The title filter of the post listing uses the 'english' configuration, which the old 'simple' index never matched.
+DROP INDEX IF EXISTS posts_title_idx;
+CREATE INDEX IF NOT EXISTS posts_title_idx ON posts USING GIN (to_tsvector('english', title));
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS comments_parent_id_idx;
+DROP INDEX IF EXISTS comments_post_id_parent_id_idx;
+
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_depth_check;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS deleted;
+ALTER TABLE comments DROP COLUMN IF EXISTS depth;
+ALTER TABLE comments DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,9 @@
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS parent_id bigint REFERENCES comments ON DELETE CASCADE;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS depth integer NOT NULL DEFAULT 0;
+-- Comments with replies are kept as tombstones when deleted, so the discussion under them survives.
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS deleted boolean NOT NULL DEFAULT false;
+
+ALTER TABLE comments ADD CONSTRAINT comments_depth_check CHECK ((parent_id IS NULL) = (depth = 0));
+
+CREATE INDEX IF NOT EXISTS comments_post_id_parent_id_idx ON comments (post_id, parent_id);
+CREATE INDEX IF NOT EXISTS comments_parent_id_idx ON comments (parent_id);
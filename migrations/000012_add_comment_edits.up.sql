@@ -0,0 +1,13 @@
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 1;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS edited_at timestamp(0) with time zone;
+
+-- Previous texts of edited comments, kept for moderators.
+CREATE TABLE IF NOT EXISTS "comment_edits" (
+"id" bigserial PRIMARY KEY,
+"comment_id" bigint NOT NULL REFERENCES comments ON DELETE CASCADE,
+"text" text NOT NULL,
+"edited_by" bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+"edited_at" timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS comment_edits_comment_id_idx ON comment_edits (comment_id);
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS comments_post_id_likes_count_idx;
+
+DROP TRIGGER IF EXISTS comment_likes_likes_count_trigger ON comment_likes;
+DROP FUNCTION IF EXISTS comment_likes_update_comments_likes_count();
+DROP TRIGGER IF EXISTS comments_comments_count_trigger ON comments;
+DROP FUNCTION IF EXISTS comments_update_posts_comments_count();
+
+DROP TABLE IF EXISTS comment_likes;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS likes_count;
+ALTER TABLE posts DROP COLUMN IF EXISTS comments_count;
@@ -0,0 +1,53 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS comments_count integer NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS likes_count integer NOT NULL DEFAULT 0;
+
+-- Sorting the most liked comments first needs comments to have likes, at most one per user.
+CREATE TABLE IF NOT EXISTS "comment_likes" (
+"comment_id" bigint NOT NULL REFERENCES comments ON DELETE CASCADE,
+"user_id" bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+"created_at" timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+PRIMARY KEY ("comment_id", "user_id")
+);
+
+CREATE INDEX IF NOT EXISTS comment_likes_user_id_idx ON comment_likes (user_id);
+
+-- Tombstones of deleted comments aren't counted.
+CREATE OR REPLACE FUNCTION comments_update_posts_comments_count() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'INSERT' AND NOT NEW.deleted THEN
+		UPDATE posts SET comments_count = comments_count + 1 WHERE id = NEW.post_id;
+	ELSIF TG_OP = 'DELETE' AND NOT OLD.deleted THEN
+		UPDATE posts SET comments_count = comments_count - 1 WHERE id = OLD.post_id;
+	ELSIF TG_OP = 'UPDATE' AND OLD.deleted <> NEW.deleted THEN
+		UPDATE posts SET comments_count = comments_count + CASE WHEN NEW.deleted THEN -1 ELSE 1 END WHERE id = NEW.post_id;
+	END IF;
+
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS comments_comments_count_trigger ON comments;
+CREATE TRIGGER comments_comments_count_trigger
+AFTER INSERT OR DELETE OR UPDATE OF deleted ON comments
+FOR EACH ROW EXECUTE FUNCTION comments_update_posts_comments_count();
+
+CREATE OR REPLACE FUNCTION comment_likes_update_comments_likes_count() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		UPDATE comments SET likes_count = likes_count + 1 WHERE id = NEW.comment_id;
+	ELSE
+		UPDATE comments SET likes_count = likes_count - 1 WHERE id = OLD.comment_id;
+	END IF;
+
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS comment_likes_likes_count_trigger ON comment_likes;
+CREATE TRIGGER comment_likes_likes_count_trigger
+AFTER INSERT OR DELETE ON comment_likes
+FOR EACH ROW EXECUTE FUNCTION comment_likes_update_comments_likes_count();
+
+UPDATE posts p SET comments_count = (SELECT count(*) FROM comments c WHERE c.post_id = p.id AND NOT c.deleted);
+
+CREATE INDEX IF NOT EXISTS comments_post_id_likes_count_idx ON comments (post_id, likes_count, id);
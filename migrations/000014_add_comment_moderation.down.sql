@@ -0,0 +1,31 @@
+CREATE OR REPLACE FUNCTION comments_update_posts_comments_count() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'INSERT' AND NOT NEW.deleted THEN
+		UPDATE posts SET comments_count = comments_count + 1 WHERE id = NEW.post_id;
+	ELSIF TG_OP = 'DELETE' AND NOT OLD.deleted THEN
+		UPDATE posts SET comments_count = comments_count - 1 WHERE id = OLD.post_id;
+	ELSIF TG_OP = 'UPDATE' AND OLD.deleted <> NEW.deleted THEN
+		UPDATE posts SET comments_count = comments_count + CASE WHEN NEW.deleted THEN -1 ELSE 1 END WHERE id = NEW.post_id;
+	END IF;
+
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS comments_comments_count_trigger ON comments;
+CREATE TRIGGER comments_comments_count_trigger
+AFTER INSERT OR DELETE OR UPDATE OF deleted ON comments
+FOR EACH ROW EXECUTE FUNCTION comments_update_posts_comments_count();
+
+UPDATE posts p SET comments_count = (SELECT count(*) FROM comments c WHERE c.post_id = p.id AND NOT c.deleted);
+
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS posts_comment_moderation_check;
+ALTER TABLE posts DROP COLUMN IF EXISTS comment_moderation;
+
+DROP INDEX IF EXISTS comments_created_by_status_idx;
+DROP INDEX IF EXISTS comments_pending_idx;
+
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_status_check;
+ALTER TABLE comments DROP COLUMN IF EXISTS moderated_at;
+ALTER TABLE comments DROP COLUMN IF EXISTS moderated_by;
+ALTER TABLE comments DROP COLUMN IF EXISTS status;
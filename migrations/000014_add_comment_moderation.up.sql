@@ -0,0 +1,36 @@
+-- Existing comments were already visible, so they are backfilled as approved.
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'approved';
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS moderated_by bigint REFERENCES users ON DELETE SET NULL;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS moderated_at timestamp(0) with time zone;
+
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_status_check;
+ALTER TABLE comments ADD CONSTRAINT comments_status_check CHECK (status IN ('pending', 'approved', 'rejected', 'spam'));
+
+CREATE INDEX IF NOT EXISTS comments_pending_idx ON comments(id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS comments_created_by_status_idx ON comments(created_by, status);
+
+-- NULL means the post follows the global moderation mode.
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS comment_moderation text;
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS posts_comment_moderation_check;
+ALTER TABLE posts ADD CONSTRAINT posts_comment_moderation_check CHECK (comment_moderation IN ('open', 'first_time', 'all', 'closed'));
+
+-- Only approved comments which aren't tombstones are counted.
+CREATE OR REPLACE FUNCTION comments_update_posts_comments_count() RETURNS trigger AS $$
+DECLARE
+	was_counted boolean := TG_OP <> 'INSERT' AND NOT OLD.deleted AND OLD.status = 'approved';
+	is_counted boolean := TG_OP <> 'DELETE' AND NOT NEW.deleted AND NEW.status = 'approved';
+BEGIN
+	IF was_counted AND NOT is_counted THEN
+		UPDATE posts SET comments_count = comments_count - 1 WHERE id = OLD.post_id;
+	ELSIF is_counted AND NOT was_counted THEN
+		UPDATE posts SET comments_count = comments_count + 1 WHERE id = NEW.post_id;
+	END IF;
+
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS comments_comments_count_trigger ON comments;
+CREATE TRIGGER comments_comments_count_trigger
+AFTER INSERT OR DELETE OR UPDATE OF deleted, status ON comments
+FOR EACH ROW EXECUTE FUNCTION comments_update_posts_comments_count();
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS report_actions;
+DROP TABLE IF EXISTS reports;
+
+ALTER TABLE posts DROP COLUMN IF EXISTS hidden;
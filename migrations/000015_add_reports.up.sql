@@ -0,0 +1,32 @@
+-- Hidden posts are only visible to their authors and moderators, like drafts.
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS hidden boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS "reports" (
+"id" bigserial PRIMARY KEY,
+"target_type" text NOT NULL CHECK (target_type IN ('post', 'comment')),
+"target_id" bigint NOT NULL,
+"reporter_id" bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+"reason" text NOT NULL CHECK (reason IN ('spam', 'harassment', 'hate', 'misinformation', 'other')),
+"details" text NOT NULL DEFAULT '',
+"status" text NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+"created_at" timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+"resolved_by" bigint REFERENCES users ON DELETE SET NULL,
+"resolved_at" timestamp(0) with time zone,
+UNIQUE ("reporter_id", "target_type", "target_id")
+);
+
+CREATE INDEX IF NOT EXISTS reports_target_idx ON reports(target_type, target_id);
+CREATE INDEX IF NOT EXISTS reports_open_idx ON reports(id) WHERE status = 'open';
+
+-- Audit trail of everything done to reported content. actor_id is NULL for automatic actions.
+CREATE TABLE IF NOT EXISTS "report_actions" (
+"id" bigserial PRIMARY KEY,
+"target_type" text NOT NULL,
+"target_id" bigint NOT NULL,
+"action" text NOT NULL,
+"actor_id" bigint REFERENCES users ON DELETE SET NULL,
+"note" text NOT NULL DEFAULT '',
+"created_at" timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS report_actions_target_idx ON report_actions(target_type, target_id);
@@ -0,0 +1,10 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS liked_by integer[];
+
+UPDATE posts p SET liked_by = ARRAY(SELECT pl.user_id FROM post_likes pl WHERE pl.post_id = p.id ORDER BY pl.created_at, pl.user_id)
+WHERE EXISTS (SELECT 1 FROM post_likes pl WHERE pl.post_id = p.id);
+
+DROP INDEX IF EXISTS posts_likes_count_idx;
+DROP TRIGGER IF EXISTS post_likes_likes_count_trigger ON post_likes;
+DROP FUNCTION IF EXISTS post_likes_update_posts_likes_count();
+ALTER TABLE posts DROP COLUMN IF EXISTS likes_count;
+DROP TABLE IF EXISTS post_likes;
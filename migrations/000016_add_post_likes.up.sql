@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS "post_likes" (
+"post_id" bigint NOT NULL REFERENCES posts ON DELETE CASCADE,
+"user_id" bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+"created_at" timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+PRIMARY KEY ("post_id", "user_id")
+);
+
+CREATE INDEX IF NOT EXISTS post_likes_user_id_idx ON post_likes (user_id);
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS likes_count integer NOT NULL DEFAULT 0;
+
+-- The array never recorded when a post was liked, so backfilled likes are dated now. Ids of users who no longer
+-- exist are dropped.
+INSERT INTO post_likes (post_id, user_id)
+SELECT DISTINCT p.id, l.user_id
+FROM posts p
+CROSS JOIN LATERAL unnest(p.liked_by) AS l(user_id)
+INNER JOIN users u ON l.user_id = u.id
+ON CONFLICT DO NOTHING;
+
+UPDATE posts p SET likes_count = (SELECT count(*) FROM post_likes pl WHERE pl.post_id = p.id);
+
+CREATE OR REPLACE FUNCTION post_likes_update_posts_likes_count() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		UPDATE posts SET likes_count = likes_count + 1 WHERE id = NEW.post_id;
+	ELSE
+		UPDATE posts SET likes_count = likes_count - 1 WHERE id = OLD.post_id;
+	END IF;
+
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS post_likes_likes_count_trigger ON post_likes;
+CREATE TRIGGER post_likes_likes_count_trigger
+AFTER INSERT OR DELETE ON post_likes
+FOR EACH ROW EXECUTE FUNCTION post_likes_update_posts_likes_count();
+
+ALTER TABLE posts DROP COLUMN IF EXISTS liked_by;
+
+CREATE INDEX IF NOT EXISTS posts_likes_count_idx ON posts (likes_count, id);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS "post_reactions" (
+"id" bigserial PRIMARY KEY,
+"post_id" bigint NOT NULL REFERENCES posts ON DELETE CASCADE,
+"user_id" bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+"emoji" text NOT NULL,
+"created_at" timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+UNIQUE ("post_id", "user_id", "emoji")
+);
+
+CREATE INDEX IF NOT EXISTS post_reactions_post_id_emoji_idx ON post_reactions (post_id, emoji);
+
+CREATE TABLE IF NOT EXISTS "comment_reactions" (
+"id" bigserial PRIMARY KEY,
+"comment_id" bigint NOT NULL REFERENCES comments ON DELETE CASCADE,
+"user_id" bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+"emoji" text NOT NULL,
+"created_at" timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+UNIQUE ("comment_id", "user_id", "emoji")
+);
+
+CREATE INDEX IF NOT EXISTS comment_reactions_comment_id_emoji_idx ON comment_reactions (comment_id, emoji);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS reading_list_items;
+DROP FUNCTION IF EXISTS reading_list_items_close_gap();
+DROP TABLE IF EXISTS reading_lists;
+DROP TABLE IF EXISTS bookmarks;
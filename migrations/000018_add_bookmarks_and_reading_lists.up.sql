@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS "bookmarks" (
+"user_id" bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+"post_id" bigint NOT NULL REFERENCES posts ON DELETE CASCADE,
+"created_at" timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+PRIMARY KEY ("user_id", "post_id")
+);
+
+CREATE INDEX IF NOT EXISTS bookmarks_post_id_idx ON bookmarks (post_id);
+
+CREATE TABLE IF NOT EXISTS "reading_lists" (
+"id" bigserial PRIMARY KEY,
+"user_id" bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+"name" text NOT NULL,
+"description" text NOT NULL DEFAULT '',
+"public" boolean NOT NULL DEFAULT false,
+"created_at" timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+"version" integer NOT NULL DEFAULT 1,
+UNIQUE ("user_id", "name")
+);
+
+-- Items are ordered by position, which runs from 1 without gaps.
+CREATE TABLE IF NOT EXISTS "reading_list_items" (
+"list_id" bigint NOT NULL REFERENCES reading_lists ON DELETE CASCADE,
+"post_id" bigint NOT NULL REFERENCES posts ON DELETE CASCADE,
+"position" integer NOT NULL,
+"note" text NOT NULL DEFAULT '',
+"added_at" timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+PRIMARY KEY ("list_id", "post_id")
+);
+
+CREATE INDEX IF NOT EXISTS reading_list_items_list_id_position_idx ON reading_list_items (list_id, position);
+CREATE INDEX IF NOT EXISTS reading_list_items_post_id_idx ON reading_list_items (post_id);
+
+-- Deleting a post takes it out of every list, close the gap it leaves behind.
+CREATE OR REPLACE FUNCTION reading_list_items_close_gap() RETURNS trigger AS $$
+BEGIN
+	UPDATE reading_list_items SET position = position - 1 WHERE list_id = OLD.list_id AND position > OLD.position;
+
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS reading_list_items_close_gap_trigger ON reading_list_items;
+CREATE TRIGGER reading_list_items_close_gap_trigger
+AFTER DELETE ON reading_list_items
+FOR EACH ROW EXECUTE FUNCTION reading_list_items_close_gap();
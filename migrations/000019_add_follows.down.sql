@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS posts_created_by_published_idx;
+DROP TABLE IF EXISTS follows;
+DROP FUNCTION IF EXISTS follows_update_users_follow_counts();
+ALTER TABLE users DROP COLUMN IF EXISTS following_count;
+ALTER TABLE users DROP COLUMN IF EXISTS followers_count;
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS "follows" (
+"follower_id" bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+"followee_id" bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+"created_at" timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+PRIMARY KEY ("follower_id", "followee_id"),
+CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX IF NOT EXISTS follows_followee_id_idx ON follows (followee_id);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS followers_count integer NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS following_count integer NOT NULL DEFAULT 0;
+
+CREATE OR REPLACE FUNCTION follows_update_users_follow_counts() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		UPDATE users SET followers_count = followers_count + 1 WHERE id = NEW.followee_id;
+		UPDATE users SET following_count = following_count + 1 WHERE id = NEW.follower_id;
+	ELSE
+		UPDATE users SET followers_count = followers_count - 1 WHERE id = OLD.followee_id;
+		UPDATE users SET following_count = following_count - 1 WHERE id = OLD.follower_id;
+	END IF;
+
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS follows_follow_counts_trigger ON follows;
+CREATE TRIGGER follows_follow_counts_trigger
+AFTER INSERT OR DELETE ON follows
+FOR EACH ROW EXECUTE FUNCTION follows_update_users_follow_counts();
+
+-- The feed lists the public posts of a few authors by publication time.
+CREATE INDEX IF NOT EXISTS posts_created_by_published_idx ON posts (created_by, (COALESCE(published_at, publish_at, created_at)), id);
@@ -0,0 +1,2 @@
+-- Email change and revert tokens carry the address they switch the user to.
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS email citext;
@@ -0,0 +1,5 @@
+DELETE FROM tokens WHERE scope = 'refresh';
+DROP INDEX IF EXISTS tokens_family_idx;
+ALTER TABLE tokens DROP COLUMN IF EXISTS rotated_at;
+ALTER TABLE tokens DROP COLUMN IF EXISTS family;
+DROP SEQUENCE IF EXISTS token_families_seq;
@@ -0,0 +1,8 @@
+-- The access and refresh tokens of one login share a family, which is revoked as a whole.
+CREATE SEQUENCE IF NOT EXISTS token_families_seq;
+
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS family bigint;
+-- Rotated refresh tokens are kept until they expire, so reusing one can be detected.
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS rotated_at timestamp(0) with time zone;
+
+CREATE INDEX IF NOT EXISTS tokens_family_idx ON tokens (family);
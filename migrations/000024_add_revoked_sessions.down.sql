@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS tokens_revoke_session_trigger ON tokens;
+DROP FUNCTION IF EXISTS tokens_revoke_session();
+DROP TABLE IF EXISTS revoked_sessions;
@@ -0,0 +1,24 @@
+-- Signed access tokens are verified without a database lookup, so servers keep a copy of this list in memory
+-- to reject the tokens of sessions that were logged out before they expire.
+CREATE TABLE IF NOT EXISTS "revoked_sessions" (
+"family" bigint PRIMARY KEY,
+"expiry" timestamp(0) with time zone NOT NULL
+);
+
+-- Signed access tokens expire with the access token row of their session, so the entry is only needed until then.
+CREATE OR REPLACE FUNCTION tokens_revoke_session() RETURNS trigger AS $$
+BEGIN
+	INSERT INTO revoked_sessions (family, expiry)
+	VALUES (OLD.family, OLD.expiry)
+	ON CONFLICT (family) DO UPDATE SET expiry = GREATEST(revoked_sessions.expiry, EXCLUDED.expiry);
+
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS tokens_revoke_session_trigger ON tokens;
+CREATE TRIGGER tokens_revoke_session_trigger
+AFTER DELETE ON tokens
+FOR EACH ROW
+WHEN (OLD.scope = 'authentication' AND OLD.family IS NOT NULL AND OLD.expiry > NOW())
+EXECUTE FUNCTION tokens_revoke_session();
@@ -0,0 +1,14 @@
+-- Long-lived tokens for scripts and CI. They're limited to the permissions in scopes, on top of the user's role.
+CREATE TABLE IF NOT EXISTS "personal_access_tokens" (
+"id" bigserial PRIMARY KEY,
+"user_id" bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+"name" text NOT NULL,
+"hash" bytea NOT NULL UNIQUE,
+"scopes" text[] NOT NULL,
+"created_at" timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+-- NULL for tokens that never expire.
+"expiry" timestamp(0) with time zone,
+"last_used_at" timestamp(0) with time zone,
+"last_used_ip" text NOT NULL DEFAULT '',
+UNIQUE ("user_id", "name")
+);
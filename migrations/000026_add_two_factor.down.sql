@@ -0,0 +1,7 @@
+ALTER TABLE tokens DROP COLUMN IF EXISTS attempts;
+DROP TABLE IF EXISTS recovery_codes;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_locked_until;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_failed_attempts;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_last_step;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_enabled;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_secret;
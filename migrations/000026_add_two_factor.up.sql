@@ -0,0 +1,18 @@
+-- The TOTP secret is set on enrollment and only used for logins once enabled. totp_last_step is the time step of the
+-- last accepted code, so a code can't be used twice.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret text;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled boolean NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step bigint NOT NULL DEFAULT 0;
+-- Failed codes since the last accepted one. Codes are refused until totp_locked_until once there were too many.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_failed_attempts integer NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_locked_until timestamp(0) with time zone;
+
+-- Single-use codes for logging in without the authenticator. Only their hashes are stored.
+CREATE TABLE IF NOT EXISTS "recovery_codes" (
+"user_id" bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+"hash" bytea NOT NULL,
+PRIMARY KEY ("user_id", "hash")
+);
+
+-- Codes tried with a two-factor challenge token, which is deleted after too many.
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS attempts integer NOT NULL DEFAULT 0;
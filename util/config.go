@@ -12,6 +12,8 @@ type Config struct {
 	SmtpUsername string `mapstructure:"SMTP_USERNAME"`
 	SmtpPassword string `mapstructure:"SMPT_PASSWORD"`
 	SmtpSender   string `mapstructure:"SMTP_SENDER"`
+	// Space separated kid:alg:base64-key entries, the first one signs new access tokens.
+	TokenSigningKeys string `mapstructure:"TOKEN_SIGNING_KEYS"`
 }
 
 var (